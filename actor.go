@@ -0,0 +1,123 @@
+package lruish
+
+import (
+	"errors"
+	"sync"
+)
+
+// Actor is a cache owned by a single goroutine, which all operations are
+// funneled through. Callers never contend on a lock; instead they queue their
+// operations and receive futures of the results. This suits workloads with
+// many producers, which tolerate the latency of the round trip.
+//
+// Eviction callbacks are invoked on the actor goroutine.
+type Actor struct {
+	lru *lruish
+	ops chan func(*lruish)
+
+	quit      chan struct{} // Closed to stop the actor
+	exited    chan struct{} // Closed once the actor stopped processing operations
+	closeOnce sync.Once
+}
+
+// NewActor creates a cache of the given size, owned by a new goroutine which
+// processes up to queueSize queued operations. Optional features are
+// configured through opts, except background refreshes which are not
+// supported.
+func NewActor(size, queueSize int, opts ...Option) (*Actor, error) {
+	cfg := newConfig(opts)
+	if cfg.refreshLoader != nil {
+		return nil, errors.New("refreshing requires a synchronized cache")
+	}
+	if cfg.invalidator != nil {
+		return nil, errInvalidatorUnsynched
+	}
+	lru, err := newLruish(size, cfg)
+	if err != nil {
+		return nil, err
+	}
+	a := &Actor{
+		lru:    lru,
+		ops:    make(chan func(*lruish), queueSize),
+		quit:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+	go a.loop()
+	return a, nil
+}
+
+func (a *Actor) loop() {
+	defer close(a.exited)
+	for {
+		select {
+		case op := <-a.ops:
+			op(a.lru)
+		case <-a.quit:
+			// Complete the operations queued before Close
+			for {
+				select {
+				case op := <-a.ops:
+					op(a.lru)
+				default:
+					a.lru.Close()
+					return
+				}
+			}
+		}
+	}
+}
+
+// submit queues an operation, unless the actor is shutting down.
+func (a *Actor) submit(f *Future, op func(*lruish)) *Future {
+	select {
+	case <-a.quit:
+		f.resolve(nil, false, ErrClosed)
+	default:
+		select {
+		case a.ops <- op:
+		case <-a.quit:
+			f.resolve(nil, false, ErrClosed)
+		}
+	}
+	return f
+}
+
+// GetAsync looks up a key's value from the cache. The future yields the value
+// and whether it was found.
+func (a *Actor) GetAsync(key interface{}) *Future {
+	f := newFuture(a.exited)
+	return a.submit(f, func(c *lruish) {
+		value, ok := c.Get(key)
+		f.resolve(value, ok, nil)
+	})
+}
+
+// AddAsync adds a value to the cache. The future yields whether an eviction
+// occurred as ok, with a nil value.
+func (a *Actor) AddAsync(key, value interface{}) *Future {
+	f := newFuture(a.exited)
+	return a.submit(f, func(c *lruish) {
+		f.resolve(nil, c.Add(key, value), nil)
+	})
+}
+
+// RemoveAsync removes the provided key from the cache. The future yields
+// whether the key was contained as ok, with a nil value.
+func (a *Actor) RemoveAsync(key interface{}) *Future {
+	f := newFuture(a.exited)
+	return a.submit(f, func(c *lruish) {
+		f.resolve(nil, c.Remove(key), nil)
+	})
+}
+
+// Close stops the actor once the queued operations are done, and closes the
+// cache. Operations submitted concurrently with Close fail with ErrClosed.
+func (a *Actor) Close() error {
+	err := ErrClosed
+	a.closeOnce.Do(func() {
+		close(a.quit)
+		err = nil
+	})
+	<-a.exited
+	return err
+}
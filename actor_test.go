@@ -0,0 +1,51 @@
+package lruish
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestActor(t *testing.T) {
+	a, err := NewActor(128, 16)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 32; i++ {
+				a.AddAsync(g*32+i, i)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if v, ok, err := a.GetAsync(33).Wait(ctx); err != nil || !ok || v != 1 {
+		t.Fatalf("bad lookup: %v %v %v", v, ok, err)
+	}
+	if _, ok, _ := a.RemoveAsync(33).Wait(ctx); !ok {
+		t.Fatal("33 should have been removed")
+	}
+	if _, ok, _ := a.GetAsync(33).Wait(ctx); ok {
+		t.Fatal("33 should be gone")
+	}
+	pending := a.AddAsync("late", 1)
+	if err := a.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	// Operations queued before Close are completed
+	if _, _, err := pending.Wait(ctx); err != nil {
+		t.Fatalf("queued add failed: %v", err)
+	}
+	if _, _, err := a.GetAsync(1).Wait(ctx); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	if err := a.Close(); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
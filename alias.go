@@ -0,0 +1,185 @@
+package lruish
+
+// AddAlias makes aliasKey refer to the entry cached under canonicalKey, so
+// that lookups and writes by either key hit the same entry and share its
+// recency. The alias is dropped along with the entry once it is evicted or
+// removed, including by removing it through the alias. Aliases are not
+// entries of their own: they are left out of Len, Keys and iteration.
+//
+// Returns false, adding no alias, if canonicalKey is not cached or aliasKey
+// is cached as an entry of its own. An existing alias is pointed to the new
+// entry.
+func (c *SynchedLRU) AddAlias(aliasKey, canonicalKey interface{}) bool {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.AddAlias(aliasKey, canonicalKey)
+}
+
+// removeAlias drops the alias, if any.
+func (c *SynchedLRU) removeAlias(aliasKey interface{}) {
+	c.lock.Lock()
+	defer c.unlock()
+	c.lru.removeAlias(aliasKey)
+}
+
+// hasEntry returns whether the key is cached as an entry, rather than an
+// alias.
+func (c *SynchedLRU) hasEntry(key interface{}) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	_, ok := c.lru.items[key]
+	return ok
+}
+
+// AddAlias makes aliasKey refer to the entry cached under canonicalKey, so
+// that lookups and writes by either key hit the same entry and share its
+// recency. The alias is dropped along with the entry once it is evicted or
+// removed, including by removing it through the alias. Aliases are not
+// entries of their own: they are left out of Len, Keys and iteration.
+//
+// Returns false, adding no alias, if canonicalKey is not cached or aliasKey
+// is cached as an entry of its own. An existing alias is pointed to the new
+// entry.
+func (c *lruish) AddAlias(aliasKey, canonicalKey interface{}) bool {
+	if c.frozen {
+		return false
+	}
+	if _, ok := c.items[aliasKey]; ok {
+		return false
+	}
+	ent, ok := c.get(canonicalKey)
+	if !ok {
+		return false
+	}
+	if c.aliases == nil {
+		c.aliases = newAliasMap()
+	}
+	c.removeAlias(aliasKey)
+	c.aliases.add(aliasKey, ent)
+	if c.bloom != nil {
+		c.bloomAdd(aliasKey)
+	}
+	return true
+}
+
+// entry returns the entry cached under the key or aliased by it, whether or
+// not it has expired.
+func (c *lruish) entry(key interface{}) (*lruElem, bool) {
+	ent, ok := c.items[key]
+	if !ok && c.aliases != nil {
+		ent, ok = c.aliases.entries[key]
+	}
+	return ent, ok
+}
+
+// removeAlias drops the alias, if any.
+func (c *lruish) removeAlias(aliasKey interface{}) {
+	if c.aliases == nil {
+		return
+	}
+	if c.aliases.remove(aliasKey) && c.onUnalias != nil {
+		c.onUnalias(aliasKey)
+	}
+}
+
+// unalias drops the aliases of the entry.
+func (c *lruish) unalias(ent *lruElem) {
+	aliases := c.aliases.keys[ent]
+	delete(c.aliases.keys, ent)
+	for _, alias := range aliases {
+		delete(c.aliases.entries, alias)
+		if c.onUnalias != nil {
+			c.onUnalias(alias)
+		}
+	}
+}
+
+// dropAliases drops all aliases.
+func (c *lruish) dropAliases() {
+	if c.aliases != nil && c.onUnalias != nil {
+		for alias := range c.aliases.entries {
+			c.onUnalias(alias)
+		}
+	}
+	c.aliases = nil
+}
+
+// aliasMap maps alias keys to the entries they refer to, and back. The aliases
+// are kept out of the entries, as most caches have none.
+type aliasMap struct {
+	entries map[interface{}]*lruElem
+	keys    map[*lruElem][]interface{}
+}
+
+func newAliasMap() *aliasMap {
+	return &aliasMap{
+		entries: make(map[interface{}]*lruElem),
+		keys:    make(map[*lruElem][]interface{}),
+	}
+}
+
+// add makes the alias refer to the entry.
+func (m *aliasMap) add(alias interface{}, ent *lruElem) {
+	m.entries[alias] = ent
+	m.keys[ent] = append(m.keys[ent], alias)
+}
+
+// remove drops the alias, returning whether there was one.
+func (m *aliasMap) remove(alias interface{}) bool {
+	ent, ok := m.entries[alias]
+	if !ok {
+		return false
+	}
+	delete(m.entries, alias)
+	keys := m.keys[ent]
+	for i, k := range keys {
+		if k == alias {
+			keys = append(keys[:i], keys[i+1:]...)
+			break
+		}
+	}
+	if len(keys) == 0 {
+		delete(m.keys, ent)
+	} else {
+		m.keys[ent] = keys
+	}
+	return true
+}
+
+// AddAlias makes an alias within the namespace refer to an entry of the
+// namespace.
+func (n *namespace) AddAlias(aliasKey, canonicalKey interface{}) bool {
+	return n.root.AddAlias(n.wrap(aliasKey), n.wrap(canonicalKey))
+}
+
+// AddAlias makes aliasKey refer to the entry cached under canonicalKey. The
+// alias is kept in the stripe of the entry, and the striped cache routes the
+// alias key there for as long as the alias exists.
+func (c *stripedLRU) AddAlias(aliasKey, canonicalKey interface{}) bool {
+	s := c.stripe(canonicalKey)
+	if h := c.hashStripe(aliasKey); h != s && h.hasEntry(aliasKey) {
+		return false
+	}
+	c.aliased.Store(true)
+	old := c.stripe(aliasKey)
+
+	s.lock.Lock()
+	ok := s.lru.AddAlias(aliasKey, canonicalKey)
+	if ok {
+		c.aliases.Store(aliasKey, s)
+	}
+	s.unlock()
+
+	if ok && old != s {
+		// Drop the alias from the stripe it previously referred into
+		old.removeAlias(aliasKey)
+	}
+	return ok
+}
+
+// routeAliases makes the striped cache forget the aliases the stripe drops.
+func (c *stripedLRU) routeAliases(s *SynchedLRU) {
+	s.lru.onUnalias = func(alias interface{}) {
+		c.aliases.CompareAndDelete(alias, s)
+	}
+}
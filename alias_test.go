@@ -0,0 +1,113 @@
+package lruish
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAddAlias(t *testing.T) {
+	l, _ := New(3)
+	l.Add("hash1", "block1")
+	if l.AddAlias(1, "missing") {
+		t.Error("aliased a missing entry")
+	}
+	if !l.AddAlias(1, "hash1") {
+		t.Fatal("alias not added")
+	}
+	if v, ok := l.Get(1); !ok || v != "block1" {
+		t.Fatalf("have %v (%v), want block1", v, ok)
+	}
+	if l.Len() != 1 {
+		t.Errorf("aliases should not count as entries, len %d", l.Len())
+	}
+	// Writes through the alias update the entry
+	l.Add(1, "block1'")
+	if v, _ := l.Peek("hash1"); v != "block1'" {
+		t.Errorf("have %v, want block1'", v)
+	}
+	// Keys cached as entries can't become aliases
+	l.Add("hash2", "block2")
+	if l.AddAlias("hash2", "hash1") {
+		t.Error("aliased a cached key")
+	}
+	// Lookups by alias share the entry's recency
+	l.Add("hash3", "block3")
+	l.Get(1)
+	l.Add("hash4", "block4")
+	if !l.Contains("hash1") || l.Contains("hash2") {
+		t.Fatalf("lookup by alias did not promote the entry")
+	}
+	// Eviction drops the aliases
+	l.Add("hash5", "block5")
+	l.Add("hash6", "block6")
+	if l.Contains("hash1") || l.Contains(1) {
+		t.Fatalf("entry or alias not evicted")
+	}
+	l.Add("hash7", "block7")
+	l.AddAlias(7, "hash7")
+	if !l.Remove(7) || l.Contains("hash7") {
+		t.Error("removal through the alias failed")
+	}
+}
+
+func TestAddAliasRepoint(t *testing.T) {
+	l, _ := New(4)
+	l.Add("a", 1)
+	l.Add("b", 2)
+	l.AddAlias("x", "a")
+	if !l.AddAlias("x", "b") {
+		t.Fatal("alias not repointed")
+	}
+	l.Remove("a")
+	if v, ok := l.Get("x"); !ok || v != 2 {
+		t.Errorf("have %v (%v), want 2", v, ok)
+	}
+	l.Purge()
+	l.Add("y", 3)
+	if l.Contains("x") {
+		t.Error("alias survived purge")
+	}
+}
+
+func TestAddAliasNamespace(t *testing.T) {
+	l, _ := New(4)
+	ns := l.Namespace("ns")
+	ns.Add("a", 1)
+	if !ns.AddAlias("b", "a") {
+		t.Fatal("alias not added")
+	}
+	if v, ok := ns.Get("b"); !ok || v != 1 {
+		t.Errorf("have %v (%v), want 1", v, ok)
+	}
+	if l.Contains("b") {
+		t.Error("alias leaked out of the namespace")
+	}
+}
+
+func TestAddAliasStriped(t *testing.T) {
+	l, _ := New(64, WithStripes(4))
+	for i := 0; i < 8; i++ {
+		l.Add(fmt.Sprintf("hash%d", i), i)
+		if !l.AddAlias(i, fmt.Sprintf("hash%d", i)) {
+			t.Fatalf("alias %d not added", i)
+		}
+	}
+	for i := 0; i < 8; i++ {
+		if v, ok := l.Get(i); !ok || v != i {
+			t.Errorf("alias %d: have %v (%v)", i, v, ok)
+		}
+	}
+	c := l.Clone()
+	if v, ok := c.Get(3); !ok || v != 3 {
+		t.Errorf("clone: have %v (%v), want 3", v, ok)
+	}
+	// Dropped aliases are routed by hash again
+	l.Remove("hash3")
+	if l.Contains(3) {
+		t.Error("alias survived removal")
+	}
+	l.Add(3, "own")
+	if v, ok := l.Get(3); !ok || v != "own" {
+		t.Errorf("have %v (%v), want own", v, ok)
+	}
+}
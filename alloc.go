@@ -0,0 +1,26 @@
+package lruish
+
+// AllocStats counts the elements allocated for new entries. Elements of
+// entries evicted to make room are reused for the new entry, so in a full
+// cache Reused should grow while Allocated stays flat.
+type AllocStats struct {
+	Allocated uint64 // Elements allocated
+	Reused    uint64 // Elements reused after an eviction
+}
+
+// AllocStats returns the element allocation statistics of the cache.
+func (c *SynchedLRU) AllocStats() AllocStats {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.AllocStats()
+}
+
+// AllocStats returns the element allocation statistics of the cache.
+func (c *lruish) AllocStats() AllocStats {
+	return c.allocs
+}
+
+// AllocStats returns the statistics of the underlying cache.
+func (n *namespace) AllocStats() AllocStats {
+	return n.root.AllocStats()
+}
@@ -0,0 +1,30 @@
+package lruish
+
+import "testing"
+
+func TestElemReuse(t *testing.T) {
+	l, err := NewUnsynched(16)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	keys := make([]interface{}, 1000)
+	for i := range keys {
+		keys[i] = &keys[i] // pointer keys don't allocate when boxed
+	}
+	for _, key := range keys {
+		l.Add(key, nil)
+	}
+	stats := l.AllocStats()
+	if stats.Allocated != 16 || stats.Reused != 1000-16 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	i := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		l.Add(keys[i%len(keys)], nil)
+		i++
+	})
+	// The map may still allocate when growing its buckets
+	if allocs > 0.1 {
+		t.Fatalf("expected no allocations per add, got %v", allocs)
+	}
+}
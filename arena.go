@@ -0,0 +1,262 @@
+package lruish
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"slices"
+	"sync"
+)
+
+// ArenaCache is a thread-safe lruish cache for []byte keys and values, which
+// stores keys and values in a single pre-allocated byte arena rather than as
+// individual heap objects. Its index and ring hold no pointers, so the garbage
+// collector doesn't need to scan them, no matter how many entries are cached.
+//
+// The cache is bounded both by a number of entries and by the size of the
+// arena. Entries are evicted in ring order, like in the other caches of this
+// package. Space of removed entries is reclaimed by compacting the arena once
+// it fills up; if that doesn't free enough, entries are evicted until at least
+// a quarter of the arena can be reclaimed, so that compactions stay rare.
+//
+// Keys are hashed like in BytesCache: adding a key which collides with a
+// cached one replaces it.
+type ArenaCache struct {
+	lock   sync.Mutex
+	hasher Hasher
+
+	index   map[uint64]uint32 // key hash -> ring position
+	ring    []arenaSlot
+	head    int
+	arena   []byte
+	end     int // end of the used part of the arena
+	garbage int // bytes of removed entries before end
+}
+
+// arenaSlot locates an entry in the arena. The key is stored at offset,
+// directly followed by the value.
+type arenaSlot struct {
+	hash   uint64
+	offset uint32
+	keyLen uint32
+	valLen uint32
+	live   bool
+}
+
+func (s *arenaSlot) size() int {
+	return int(s.keyLen) + int(s.valLen)
+}
+
+// NewArenaCache creates a multi-thread safe cache holding up to size entries,
+// whose keys and values are stored in an arena of arenaBytes bytes. The only
+// option it supports is WithHasher.
+func NewArenaCache(size int, arenaBytes int, opts ...Option) (*ArenaCache, error) {
+	if size <= 0 || size > math.MaxUint32 {
+		return nil, errors.New("must provide a positive size")
+	}
+	if arenaBytes <= 0 || arenaBytes > math.MaxUint32 {
+		return nil, errors.New("arena size must be positive and below 4GB")
+	}
+	cfg := newConfig(opts)
+	if cfg.hasher == nil {
+		cfg.hasher = NewMapHasher()
+	}
+	return &ArenaCache{
+		hasher: cfg.hasher,
+		index:  make(map[uint64]uint32),
+		ring:   make([]arenaSlot, size),
+		arena:  make([]byte, arenaBytes),
+	}, nil
+}
+
+// lookup returns the ring position of the entry for key, unless it's missing
+// or another key with the same hash is stored in its place.
+func (c *ArenaCache) lookup(key []byte) (int, bool) {
+	pos, ok := c.index[c.hasher.Hash(key)]
+	if !ok {
+		return 0, false
+	}
+	s := &c.ring[pos]
+	if !bytes.Equal(c.arena[s.offset:s.offset+s.keyLen], key) {
+		return 0, false
+	}
+	return int(pos), true
+}
+
+func (c *ArenaCache) value(pos int) []byte {
+	s := &c.ring[pos]
+	start := s.offset + s.keyLen
+	return c.arena[start : start+s.valLen]
+}
+
+// Add adds a copy of the key and value to the cache. Returns true if an
+// eviction occurred. Entries which don't fit in the arena are not cached,
+// but still replace any previous value for the key.
+func (c *ArenaCache) Add(key, value []byte) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	hash := c.hasher.Hash(key)
+	if pos, ok := c.index[hash]; ok {
+		// Replaced entries move to the head, like new ones
+		c.remove(int(pos))
+	}
+	n := len(key) + len(value)
+	if n > len(c.arena) {
+		return false
+	}
+	evicted := false
+	if len(c.arena)-c.end < n {
+		evicted = c.reclaim(n)
+	}
+	// new head position is h-1, which is where the current tail lives
+	c.head--
+	if c.head < 0 {
+		c.head += len(c.ring)
+	}
+	if c.ring[c.head].live {
+		c.remove(c.head)
+		evicted = true
+	}
+	offset := c.end
+	copy(c.arena[offset:], key)
+	copy(c.arena[offset+len(key):], value)
+	c.end += n
+
+	c.ring[c.head] = arenaSlot{
+		hash:   hash,
+		offset: uint32(offset),
+		keyLen: uint32(len(key)),
+		valLen: uint32(len(value)),
+		live:   true,
+	}
+	c.index[hash] = uint32(c.head)
+	return evicted
+}
+
+// reclaim makes room for n bytes at the end of the arena, evicting the
+// oldest entries if needed. Returns true if an eviction occurred.
+func (c *ArenaCache) reclaim(n int) bool {
+	target := max(n, len(c.arena)/4)
+	evicted := false
+	for i := 1; i <= len(c.ring) && c.garbage+len(c.arena)-c.end < target; i++ {
+		pos := (c.head - i + len(c.ring)) % len(c.ring)
+		if c.ring[pos].live {
+			c.remove(pos)
+			evicted = true
+		}
+	}
+	c.compact()
+	return evicted
+}
+
+// compact moves the live entries to the start of the arena, in the order
+// they are stored, so that all free space is at the end.
+func (c *ArenaCache) compact() {
+	live := make([]int, 0, len(c.index))
+	for _, pos := range c.index {
+		live = append(live, int(pos))
+	}
+	slices.SortFunc(live, func(a, b int) int {
+		return int(c.ring[a].offset) - int(c.ring[b].offset)
+	})
+	end := 0
+	for _, pos := range live {
+		s := &c.ring[pos]
+		copy(c.arena[end:], c.arena[s.offset:int(s.offset)+s.size()])
+		s.offset = uint32(end)
+		end += s.size()
+	}
+	c.end, c.garbage = end, 0
+}
+
+// remove drops the entry at the ring position, leaving its bytes as garbage.
+func (c *ArenaCache) remove(pos int) {
+	s := &c.ring[pos]
+	delete(c.index, s.hash)
+	c.garbage += s.size()
+	*s = arenaSlot{}
+}
+
+func (c *ArenaCache) promote(curIndex int) {
+	// Calculate the new position for this item
+	position := curIndex - c.head
+	if position < 0 {
+		position += len(c.ring)
+	}
+	// Calculate new index to place this item at
+	newIndex := (c.head + position/2) % len(c.ring)
+	c.ring[curIndex], c.ring[newIndex] = c.ring[newIndex], c.ring[curIndex]
+	c.index[c.ring[newIndex].hash] = uint32(newIndex)
+	// Update the downgraded item, if live (could be a hole in the ring)
+	if c.ring[curIndex].live {
+		c.index[c.ring[curIndex].hash] = uint32(curIndex)
+	}
+}
+
+// Get looks up a key's value from the cache. The returned value is a copy,
+// which the caller is free to modify.
+func (c *ArenaCache) Get(key []byte) (value []byte, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	pos, ok := c.lookup(key)
+	if !ok {
+		return nil, false
+	}
+	value = bytes.Clone(c.value(pos))
+	c.promote(pos)
+	return value, true
+}
+
+// Peek returns a copy of the key's value without updating the "recently
+// used"-ness of the key.
+func (c *ArenaCache) Peek(key []byte) (value []byte, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	pos, ok := c.lookup(key)
+	if !ok {
+		return nil, false
+	}
+	return bytes.Clone(c.value(pos)), true
+}
+
+// Contains checks if a key is in the cache, without updating the
+// recent-ness.
+func (c *ArenaCache) Contains(key []byte) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	_, ok := c.lookup(key)
+	return ok
+}
+
+// Remove removes the provided key from the cache.
+func (c *ArenaCache) Remove(key []byte) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	pos, ok := c.lookup(key)
+	if !ok {
+		return false
+	}
+	c.remove(pos)
+	return true
+}
+
+// Len returns the number of items in the cache.
+func (c *ArenaCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.index)
+}
+
+// Purge is used to completely clear the cache
+func (c *ArenaCache) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.index = make(map[uint64]uint32)
+	clear(c.ring)
+	c.head, c.end, c.garbage = 0, 0, 0
+}
@@ -0,0 +1,59 @@
+package lruish
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestArenaCache(t *testing.T) {
+	c, err := NewArenaCache(4, 64)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	c.Add([]byte("a"), []byte("1"))
+	c.Add([]byte("b"), []byte("2"))
+	if v, ok := c.Get([]byte("a")); !ok || !bytes.Equal(v, []byte("1")) {
+		t.Fatalf("bad lookup: %q %v", v, ok)
+	}
+	c.Add([]byte("a"), []byte("11"))
+	if v, _ := c.Peek([]byte("a")); !bytes.Equal(v, []byte("11")) {
+		t.Fatalf("bad replaced value: %q", v)
+	}
+	if !c.Remove([]byte("b")) || c.Contains([]byte("b")) {
+		t.Fatal("b should be removed")
+	}
+	if c.Len() != 1 {
+		t.Fatalf("expected 1 entry, got %d", c.Len())
+	}
+	if c.Add([]byte("big"), make([]byte, 100)); c.Contains([]byte("big")) {
+		t.Fatal("oversized entry should not be cached")
+	}
+}
+
+func TestArenaCacheSpace(t *testing.T) {
+	c, err := NewArenaCache(100, 100)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// Every entry takes 10 bytes, so only 10 fit at once
+	for i := 0; i < 50; i++ {
+		c.Add([]byte(fmt.Sprintf("k%02d", i)), []byte(fmt.Sprintf("value%02d", i)))
+		if v, ok := c.Get([]byte(fmt.Sprintf("k%02d", i))); !ok || string(v) != fmt.Sprintf("value%02d", i) {
+			t.Fatalf("entry %d: bad lookup %q %v", i, v, ok)
+		}
+	}
+	if n := c.Len(); n == 0 || n > 10 {
+		t.Fatalf("bad number of entries %d", n)
+	}
+	// Surviving entries are intact after compactions
+	for i := 0; i < 50; i++ {
+		if v, ok := c.Peek([]byte(fmt.Sprintf("k%02d", i))); ok && string(v) != fmt.Sprintf("value%02d", i) {
+			t.Fatalf("entry %d corrupted: %q", i, v)
+		}
+	}
+	c.Purge()
+	if c.Len() != 0 {
+		t.Fatal("purge should empty the cache")
+	}
+}
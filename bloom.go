@@ -0,0 +1,114 @@
+package lruish
+
+import (
+	"hash/maphash"
+	"math"
+	"sync/atomic"
+)
+
+// WithBloomFilter puts a Bloom filter of the cached keys in front of a
+// synchronized cache, with the given number of bits per key. Get, Peek and
+// Contains consult it before taking the lock, so most lookups of keys which
+// aren't cached never wait for writers. About ten bits per key let through
+// one such lookup in a hundred.
+//
+// Keys stay in the filter after leaving the cache, so it is rebuilt from the
+// cached keys once as many keys have been added since the last rebuild as the
+// cache holds. It is thus sized for twice the capacity of the cache. Misses
+// answered by the filter are counted in Stats, but not by the other trackers
+// of lookups, such as namespace statistics, WithGhosts, WithHotKeys,
+// WithKeySampling or WithEvents.
+func WithBloomFilter(bitsPerEntry int) Option {
+	return func(c *config) {
+		c.bloomBits = bitsPerEntry
+	}
+}
+
+// bloomFront is the Bloom filter of a cache, which readers access without
+// holding the lock. Writers hold the lock.
+type bloomFront struct {
+	filter     atomic.Pointer[bloomFilter]
+	bitsPerKey int
+	added      int // Keys added since the filter was built
+}
+
+// bloomFilter is a Bloom filter whose bits can be set and tested concurrently.
+type bloomFilter struct {
+	seed   maphash.Seed
+	bits   []atomic.Uint64
+	hashes int
+}
+
+func newBloomFilter(keys, bitsPerKey int) *bloomFilter {
+	words := max(1, (keys*bitsPerKey+63)/64)
+	return &bloomFilter{
+		seed:   maphash.MakeSeed(),
+		bits:   make([]atomic.Uint64, words),
+		hashes: min(16, max(1, int(math.Round(float64(bitsPerKey)*math.Ln2)))),
+	}
+}
+
+// hash returns the two hashes of the key, from which its bit positions are
+// derived by double hashing.
+func (f *bloomFilter) hash(key interface{}) (h1, h2 uint32) {
+	h := maphash.Comparable(f.seed, key)
+	return uint32(h), uint32(h>>32) | 1
+}
+
+// bit returns the word and mask of the i-th bit position of the hashes.
+func (f *bloomFilter) bit(h1, h2 uint32, i int) (int, uint64) {
+	bit := (h1 + uint32(i)*h2) % uint32(len(f.bits)*64)
+	return int(bit / 64), 1 << (bit % 64)
+}
+
+func (f *bloomFilter) add(key interface{}) {
+	h1, h2 := f.hash(key)
+	for i := 0; i < f.hashes; i++ {
+		word, mask := f.bit(h1, h2, i)
+		f.bits[word].Or(mask)
+	}
+}
+
+// mayContain reports whether the key may have been added. There are no false
+// negatives.
+func (f *bloomFilter) mayContain(key interface{}) bool {
+	h1, h2 := f.hash(key)
+	for i := 0; i < f.hashes; i++ {
+		word, mask := f.bit(h1, h2, i)
+		if f.bits[word].Load()&mask == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomAdd adds a key which was just cached to the Bloom filter, rebuilding
+// the filter if it's due.
+func (c *lruish) bloomAdd(key interface{}) {
+	if c.bloom.added++; c.bloom.added > c.size {
+		c.rebuildBloom()
+		return
+	}
+	c.bloom.filter.Load().add(key)
+}
+
+// rebuildBloom replaces the Bloom filter with one of the cached keys only.
+func (c *lruish) rebuildBloom() {
+	filter := newBloomFilter(2*c.size, c.bloom.bitsPerKey)
+	for key := range c.items {
+		filter.add(key)
+	}
+	if c.aliases != nil {
+		for alias := range c.aliases.entries {
+			filter.add(alias)
+		}
+	}
+	c.bloom.filter.Store(filter)
+	c.bloom.added = 0
+}
+
+// filteredOut reports whether the Bloom filter rules out that the key is
+// cached. It is safe to call without holding the lock.
+func (c *SynchedLRU) filteredOut(key interface{}) bool {
+	return c.lru.bloom != nil && !c.lru.bloom.filter.Load().mayContain(key)
+}
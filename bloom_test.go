@@ -0,0 +1,90 @@
+package lruish
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBloomFilter(t *testing.T) {
+	l, _ := New(100, WithBloomFilter(10))
+	c := l.(*SynchedLRU)
+	for i := 0; i < 100; i++ {
+		l.Add(i, i)
+	}
+	// No false negatives
+	for i := 0; i < 100; i++ {
+		if c.filteredOut(i) {
+			t.Fatalf("cached key %d filtered out", i)
+		}
+		if v, ok := l.Get(i); !ok || v != i {
+			t.Fatalf("have %v (%v), want %d", v, ok, i)
+		}
+	}
+	// Most misses are filtered
+	passed := 0
+	for i := 1000; i < 2000; i++ {
+		if !c.filteredOut(i) {
+			passed++
+		}
+	}
+	if passed > 50 {
+		t.Errorf("%d of 1000 misses passed the filter", passed)
+	}
+	if _, ok := l.Get(1000); ok {
+		t.Error("missing key found")
+	}
+	if stats := l.Stats(); stats.Misses != 1 {
+		t.Errorf("have %d misses, want 1", stats.Misses)
+	}
+}
+
+func TestBloomFilterRebuild(t *testing.T) {
+	l, _ := New(10, WithBloomFilter(10))
+	c := l.(*SynchedLRU)
+	// Churn through many keys, the filter only remembers the recent ones
+	for i := 0; i < 1000; i++ {
+		l.Add(i, i)
+	}
+	passed := 0
+	for i := 0; i < 990; i++ {
+		if !c.filteredOut(i) {
+			passed++
+		}
+	}
+	if passed > 50 {
+		t.Errorf("%d of 990 evicted keys passed the filter", passed)
+	}
+	for i := 990; i < 1000; i++ {
+		if !l.Contains(i) {
+			t.Errorf("cached key %d not found", i)
+		}
+	}
+	// Aliases, resizing and purging
+	l.AddAlias("alias", 999)
+	if v, ok := l.Get("alias"); !ok || v != 999 {
+		t.Errorf("alias: have %v (%v)", v, ok)
+	}
+	l.Resize(20)
+	for i := 990; i < 1000; i++ {
+		if v, ok := l.Peek(i); !ok || v != i {
+			t.Errorf("key %d lost by resizing", i)
+		}
+	}
+	l.Purge()
+	l.Add("a", 1)
+	if !l.Contains("a") || c.filteredOut("a") {
+		t.Error("key added after purge not found")
+	}
+}
+
+func TestBloomFilterStriped(t *testing.T) {
+	l, _ := New(64, WithStripes(4), WithBloomFilter(10))
+	for i := 0; i < 16; i++ {
+		l.Add(fmt.Sprint(i), i)
+	}
+	for i := 0; i < 16; i++ {
+		if v, ok := l.Get(fmt.Sprint(i)); !ok || v != i {
+			t.Errorf("have %v (%v), want %d", v, ok, i)
+		}
+	}
+}
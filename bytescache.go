@@ -0,0 +1,146 @@
+package lruish
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+)
+
+// BytesCache is a thread-safe fixed size lruish cache with []byte keys, which
+// can't be used as interface{} keys since slices are not comparable. Keys are
+// hashed into a uint64 by a Hasher, and a private copy of each key is stored
+// to tell apart colliding keys: adding a key which collides with a cached one
+// replaces it, and lookups only succeed if the full key matches.
+type BytesCache struct {
+	lru        *typedLRU[uint64]
+	lock       sync.RWMutex
+	hasher     Hasher
+	copyValues bool
+}
+
+type bytesEntry struct {
+	key   []byte
+	value interface{}
+}
+
+// NewBytesCache creates a multi-thread safe cache of the given size, keyed
+// by byte slices. The only options it supports are WithHasher and
+// WithCopyValues.
+func NewBytesCache(size int, opts ...Option) (*BytesCache, error) {
+	if size <= 0 {
+		return nil, errors.New("must provide a positive size")
+	}
+	cfg := newConfig(opts)
+	if cfg.hasher == nil {
+		cfg.hasher = NewMapHasher()
+	}
+	c := &BytesCache{
+		lru:        newTypedLRU[uint64](size),
+		hasher:     cfg.hasher,
+		copyValues: cfg.copyValues,
+	}
+	return c, nil
+}
+
+// lookup returns the entry for key, unless it's missing or another key with
+// the same hash is stored in its place.
+func (c *BytesCache) lookup(key []byte) (*typedElem[uint64], bool) {
+	ent, ok := c.lru.items[c.hasher.Hash(key)]
+	if !ok || !bytes.Equal(ent.value.(*bytesEntry).key, key) {
+		return nil, false
+	}
+	return ent, true
+}
+
+// Add adds a value to the cache.  Returns true if an eviction occurred. The
+// key is copied, so the caller is free to modify it afterwards.
+func (c *BytesCache) Add(key []byte, value interface{}) bool {
+	if c.copyValues {
+		value = copyBytes(value)
+	}
+	ent := &bytesEntry{key: bytes.Clone(key), value: value}
+	if ent.key == nil {
+		ent.key = []byte{}
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.add(c.hasher.Hash(key), ent)
+}
+
+// Get looks up a key's value from the cache.
+func (c *BytesCache) Get(key []byte) (value interface{}, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ent, ok := c.lookup(key)
+	if !ok {
+		return nil, false
+	}
+	c.lru.promote(ent)
+	return c.value(ent), true
+}
+
+// Contains checks if a key is in the cache, without updating the
+// recent-ness.
+func (c *BytesCache) Contains(key []byte) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	_, ok := c.lookup(key)
+	return ok
+}
+
+// Peek returns the key value (or undefined if not found) without updating
+// the "recently used"-ness of the key.
+func (c *BytesCache) Peek(key []byte) (value interface{}, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	ent, ok := c.lookup(key)
+	if !ok {
+		return nil, false
+	}
+	return c.value(ent), true
+}
+
+// value returns the value of the entry, copied if so configured.
+func (c *BytesCache) value(ent *typedElem[uint64]) interface{} {
+	value := ent.value.(*bytesEntry).value
+	if c.copyValues {
+		return copyBytes(value)
+	}
+	return value
+}
+
+// Remove removes the provided key from the cache.
+func (c *BytesCache) Remove(key []byte) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ent, ok := c.lookup(key)
+	if !ok {
+		return false
+	}
+	return c.lru.remove(ent.key)
+}
+
+// Keys returns copies of the keys, unordered
+func (c *BytesCache) Keys() [][]byte {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	keys := make([][]byte, 0, len(c.lru.items))
+	for _, ent := range c.lru.items {
+		keys = append(keys, bytes.Clone(ent.value.(*bytesEntry).key))
+	}
+	return keys
+}
+
+// Len returns the number of items in the cache.
+func (c *BytesCache) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.lru.items)
+}
+
+// Purge is used to completely clear the cache
+func (c *BytesCache) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lru.purge()
+}
@@ -0,0 +1,79 @@
+package lruish
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestBytesCache(t *testing.T) {
+	l, err := NewBytesCache(2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	key := []byte("foo")
+	l.Add(key, 1)
+	key[0] = 'b'
+	if l.Contains(key) {
+		t.Errorf("cache should have copied the key")
+	}
+	if v, ok := l.Get([]byte("foo")); !ok || v != 1 {
+		t.Errorf("foo should be set to 1: %v, %v", v, ok)
+	}
+	l.Add(nil, 2)
+	if v, ok := l.Peek([]byte{}); !ok || v != 2 {
+		t.Errorf("empty key should be set to 2: %v, %v", v, ok)
+	}
+	if !l.Remove([]byte("foo")) || l.Len() != 1 {
+		t.Errorf("foo should have been removed")
+	}
+}
+
+func TestBytesCacheCollision(t *testing.T) {
+	l, _ := NewBytesCache(2)
+	// Simulate a hash collision by planting an entry with a different key
+	// under the hash of "foo"
+	l.lru.add(l.hasher.Hash([]byte("foo")), &bytesEntry{key: []byte("bar"), value: 1})
+	if l.Contains([]byte("foo")) {
+		t.Errorf("colliding key should not match")
+	}
+	l.Add([]byte("foo"), 2)
+	if v, ok := l.Get([]byte("foo")); !ok || v != 2 || l.Len() != 1 {
+		t.Errorf("foo should have replaced the colliding entry: %v, %v", v, ok)
+	}
+}
+
+// prefixHasher uses the first eight bytes of the key as its hash.
+type prefixHasher struct{}
+
+func (prefixHasher) Hash(key []byte) uint64 {
+	var buf [8]byte
+	copy(buf[:], key)
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+func TestBytesCacheHasher(t *testing.T) {
+	l, err := NewBytesCache(2, WithHasher(prefixHasher{}))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add([]byte("0123456789"), 1)
+	l.Add([]byte("01234567xx"), 2)
+	if l.Contains([]byte("0123456789")) || l.Len() != 1 {
+		t.Errorf("colliding key should have been replaced")
+	}
+	if v, ok := l.Get([]byte("01234567xx")); !ok || v != 2 {
+		t.Errorf("bad value: %v, %v", v, ok)
+	}
+}
+
+func TestBytesCacheGetAllocs(t *testing.T) {
+	l, _ := NewBytesCache(128)
+	key := []byte("key")
+	l.Add(key, nil)
+	if allocs := testing.AllocsPerRun(100, func() { l.Get(key) }); allocs != 0 {
+		t.Errorf("Get allocated %v times", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { l.Contains(key) }); allocs != 0 {
+		t.Errorf("Contains allocated %v times", allocs)
+	}
+}
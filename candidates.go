@@ -0,0 +1,80 @@
+package lruish
+
+// EvictionCandidates returns the keys of up to n entries which are next in
+// line for eviction, the first to be evicted first. Pinned entries are never
+// candidates, and entries of lower priority come before those of higher
+// priority. With cost-based eviction the order is only approximate, as the
+// victim is then picked among several entries near the tail.
+func (c *SynchedLRU) EvictionCandidates(n int) []interface{} {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.EvictionCandidates(n)
+}
+
+// EvictionCandidates returns the keys of up to n entries which are next in
+// line for eviction, the first to be evicted first. Pinned entries are never
+// candidates, and entries of lower priority come before those of higher
+// priority. With cost-based eviction the order is only approximate, as the
+// victim is then picked among several entries near the tail.
+func (c *lruish) EvictionCandidates(n int) []interface{} {
+	if n <= 0 || len(c.items) == 0 {
+		return nil
+	}
+	keys := make([]interface{}, 0, min(n, len(c.items)))
+	for band := 0; band < numPriorities && len(keys) < n; band++ {
+		if c.bands[band] == 0 {
+			continue
+		}
+		// Walk from the tail towards the head
+		for pos := c.size - 1; pos >= 0 && len(keys) < n; pos-- {
+			ent := c.ring[(c.head+pos)%c.size]
+			if ent == nil || ent.pinned || ent.priority.band() != band {
+				continue
+			}
+			keys = append(keys, ent.key)
+		}
+	}
+	return keys
+}
+
+// EvictionCandidates returns the keys of up to n entries of the namespace
+// which are next in line for eviction from the underlying cache.
+func (n *namespace) EvictionCandidates(count int) []interface{} {
+	var keys []interface{}
+	for _, key := range n.root.EvictionCandidates(n.root.Len()) {
+		if len(keys) == count {
+			break
+		}
+		if k, ok := n.unwrap(key); ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// EvictionCandidates returns the keys of up to n entries which are next in
+// line for eviction. As each stripe evicts independently, depending on where
+// new keys land, the candidates of the stripes are interleaved.
+func (c *stripedLRU) EvictionCandidates(n int) []interface{} {
+	c.rlockAll()
+	defer c.runlockAll()
+
+	perStripe := make([][]interface{}, len(c.stripes))
+	for i, s := range c.stripes {
+		perStripe[i] = s.lru.EvictionCandidates(n)
+	}
+	var keys []interface{}
+	for i := 0; len(keys) < n; i++ {
+		added := false
+		for _, candidates := range perStripe {
+			if i < len(candidates) && len(keys) < n {
+				keys = append(keys, candidates[i])
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	return keys
+}
@@ -0,0 +1,69 @@
+package lruish
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEvictionCandidates(t *testing.T) {
+	l, _ := New(4)
+	for i := 1; i <= 4; i++ {
+		l.Add(i, i)
+	}
+	if have, want := l.EvictionCandidates(2), []interface{}{1, 2}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %v, want %v", have, want)
+	}
+	// The candidates are indeed evicted first
+	l.Add(5, 5)
+	if l.Contains(1) {
+		t.Error("1 not evicted")
+	}
+	if have, want := l.EvictionCandidates(10), []interface{}{2, 3, 4, 5}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have := l.EvictionCandidates(0); len(have) != 0 {
+		t.Errorf("have %v, want none", have)
+	}
+}
+
+func TestEvictionCandidatesPinnedAndPriority(t *testing.T) {
+	l, _ := New(4)
+	l.Add(1, 1)
+	l.Add(2, 2)
+	l.AddWithPriority(3, 3, PriorityLow)
+	l.Add(4, 4)
+	l.Pin(1)
+	if have, want := l.EvictionCandidates(4), []interface{}{3, 2, 4}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %v, want %v", have, want)
+	}
+	for _, want := range []int{3, 2} {
+		l.Add(want+10, 0)
+		if l.Contains(want) {
+			t.Errorf("%d not evicted", want)
+		}
+	}
+}
+
+func TestEvictionCandidatesNamespace(t *testing.T) {
+	l, _ := New(4)
+	ns := l.Namespace("ns")
+	ns.Add("a", 1)
+	l.Add("b", 2)
+	ns.Add("c", 3)
+	if have, want := ns.EvictionCandidates(5), []interface{}{"a", "c"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestEvictionCandidatesStriped(t *testing.T) {
+	l, _ := New(64, WithStripes(4))
+	for i := 0; i < 16; i++ {
+		l.Add(i, i)
+	}
+	if have := l.EvictionCandidates(10); len(have) != 10 {
+		t.Errorf("have %d candidates, want 10", len(have))
+	}
+	if have := l.EvictionCandidates(100); len(have) != 16 {
+		t.Errorf("have %d candidates, want 16", len(have))
+	}
+}
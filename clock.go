@@ -0,0 +1,66 @@
+package lruish
+
+import "time"
+
+// Clock is the source of time used for expiry, refresh and other time-based
+// behaviour of a cache. It can be replaced through WithClock, for instance
+// with a fake clock in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// WithClock makes the cache tell time by the given clock instead of the
+// system clock.
+func WithClock(clock Clock) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
+
+// systemClock is the Clock backed by the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// now returns the current time of the cache's clock in unix nanoseconds.
+func (c *lruish) now() int64 {
+	return c.clock.Now().UnixNano()
+}
+
+// ClockOf returns the clock a cache tells time by, as set with WithClock, so
+// that code built on top of the cache can follow the same time. Caches not
+// created by this package tell time by the system clock.
+func ClockOf(c Cache) Clock {
+	if cc, ok := c.(clocked); ok {
+		return cc.cacheClock()
+	}
+	return systemClock{}
+}
+
+// clocked is implemented by caches which tell time by a configurable clock.
+type clocked interface {
+	cacheClock() Clock
+}
+
+func (c *SynchedLRU) cacheClock() Clock {
+	return c.lru.clock
+}
+
+func (c *lruish) cacheClock() Clock {
+	return c.clock
+}
+
+func (c *stripedLRU) cacheClock() Clock {
+	return c.stripes[0].cacheClock()
+}
+
+func (n *namespace) cacheClock() Clock {
+	return ClockOf(n.root)
+}
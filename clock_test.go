@@ -0,0 +1,175 @@
+package lruish
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock which only moves when advanced.
+type fakeClock struct {
+	lock   sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1000000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ch := make(chan time.Time, 1)
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward, firing all timers which became due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.ch <- c.now
+	}
+	c.timers = pending
+}
+
+// waitTimers waits until n timers are pending, so that advancing the clock
+// fires them.
+func (c *fakeClock) waitTimers(t *testing.T, n int) {
+	for i := 0; i < 1000; i++ {
+		c.lock.Lock()
+		pending := len(c.timers)
+		c.lock.Unlock()
+		if pending >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("no timer pending")
+}
+
+func TestClockExpiry(t *testing.T) {
+	clock := newFakeClock()
+	l, err := New(2, WithClock(clock), WithExpireAfterWrite(time.Minute))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	clock.Advance(59 * time.Second)
+	if _, expiresAt, ok := l.GetWithExpiry(1); !ok || !expiresAt.Equal(clock.Now().Add(time.Second)) {
+		t.Fatalf("bad lookup before expiry: %v %v", expiresAt, ok)
+	}
+	clock.Advance(time.Second)
+	if _, ok := l.Get(1); ok {
+		t.Fatal("1 should have expired")
+	}
+}
+
+func TestClockRefresh(t *testing.T) {
+	clock := newFakeClock()
+	loader := func(key interface{}) (interface{}, error) {
+		return "fresh", nil
+	}
+	l, err := New(2, WithClock(clock), WithRefreshAfterWrite(time.Minute, loader))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, "stale")
+	l.Get(1)
+	if v, _ := l.Peek(1); v != "stale" {
+		t.Fatalf("refresh should not start before the clock advanced, got %v", v)
+	}
+	clock.Advance(time.Minute)
+	l.Get(1)
+	for i := 0; i < 100; i++ {
+		if v, _ := l.Peek(1); v == "fresh" {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("value was not refreshed")
+}
+
+func TestClockSamples(t *testing.T) {
+	clock := newFakeClock()
+	l, _ := New(2, WithClock(clock), WithKeySampling(1, 4))
+	l.Get(1)
+	if samples := l.Sample(); len(samples) != 1 || !samples[0].Time.Equal(clock.Now()) {
+		t.Fatalf("bad samples %+v", samples)
+	}
+}
+
+func TestClockOf(t *testing.T) {
+	clock := newFakeClock()
+	for _, opts := range [][]Option{{WithClock(clock)}, {WithClock(clock), WithStripes(2)}} {
+		l, _ := New(4, opts...)
+		if ClockOf(l) != clock || ClockOf(l.Namespace("ns")) != clock {
+			t.Error("configured clock not returned")
+		}
+	}
+	l, _ := New(4)
+	if _, ok := ClockOf(l).(systemClock); !ok {
+		t.Errorf("have %T, want the system clock", ClockOf(l))
+	}
+}
+
+func TestClockTuner(t *testing.T) {
+	clock := newFakeClock()
+	l, _ := New(100, WithClock(clock))
+	tuner := NewTuner(l, FixedBudget(0), 1, 50, time.Minute)
+	defer tuner.Stop()
+
+	clock.waitTimers(t, 1)
+	if l.Cap() != 100 {
+		t.Fatal("tuned before the interval passed")
+	}
+	clock.Advance(time.Minute)
+	for i := 0; i < 1000 && l.Cap() != 50; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if l.Cap() != 50 {
+		t.Errorf("have capacity %d, want 50", l.Cap())
+	}
+}
+
+func TestClockSnapshotting(t *testing.T) {
+	clock := newFakeClock()
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	l, _ := New(4, WithClock(clock))
+	stop := StartSnapshotting(l, path, time.Hour)
+	defer stop()
+
+	clock.waitTimers(t, 1)
+	if _, err := os.Stat(path); err == nil {
+		t.Fatal("snapshot saved before the interval passed")
+	}
+	clock.Advance(time.Hour)
+	for i := 0; i < 1000; i++ {
+		if _, err := os.Stat(path); err == nil {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("no snapshot saved after the interval")
+}
@@ -0,0 +1,88 @@
+package lruish
+
+// Clone returns a point-in-time copy of the cache, taken under the lock. The
+// copy has its own ring and index but shares the values with the original.
+// Because of that, the copy never invokes eviction callbacks or refreshes.
+func (c *SynchedLRU) Clone() Cache {
+	// Buffered reads update access counts under the read lock
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return &SynchedLRU{lru: c.lru.clone()}
+}
+
+// Clone returns a copy of the cache. The copy has its own ring and index but
+// shares the values with the original. Because of that, the copy never
+// invokes eviction callbacks.
+func (c *lruish) Clone() Cache {
+	return c.clone()
+}
+
+func (c *lruish) clone() *lruish {
+	clone := &lruish{
+		size:              c.size,
+		head:              c.head,
+		clock:             c.clock,
+		expireAfterWrite:  c.expireAfterWrite,
+		expireAfterAccess: c.expireAfterAccess,
+		ttlJitter:         c.ttlJitter,
+		earlyRefreshBeta:  c.earlyRefreshBeta,
+		closed:            c.closed,
+		purgeOnClose:      c.purgeOnClose,
+		promotion:         c.promotion,
+		bands:             c.bands,
+		costWindow:        c.costWindow,
+		noEviction:        c.noEviction,
+		inflation:         c.inflation,
+		compressor:        c.compressor,
+		weakValues:        c.weakValues,
+		copyValues:        c.copyValues,
+		codec:             c.codec,
+		compressAbove:     c.compressAbove,
+		versionCompare:    c.versionCompare,
+		secondaries:       c.copyIndexes(),
+	}
+	if c.doorkeeper != nil {
+		clone.doorkeeper = &doorkeeper{window: c.doorkeeper.window}
+	}
+	if c.closed {
+		return clone
+	}
+	if c.valueKeys != nil {
+		clone.valueKeys = newValueKeyMap()
+	}
+	for ns, stats := range c.namespaces {
+		*clone.namespaceStats(ns) = *stats
+	}
+	clone.items = make(map[interface{}]*lruElem, len(c.items))
+	clone.ring = make([]*lruElem, len(c.ring))
+	for i, ent := range c.ring {
+		if ent == nil {
+			continue
+		}
+		cpy := *ent
+		cpy.refreshing = false
+		clone.ring[i] = &cpy
+		clone.items[cpy.key] = &cpy
+		if cpy.tags != nil {
+			clone.tag(&cpy)
+		}
+		if cpy.indexKeys != nil {
+			cpy.indexKeys = append([]string(nil), cpy.indexKeys...)
+			clone.addToIndexes(&cpy)
+		}
+		if c.valueKeys != nil {
+			if id, ok := c.valueKeys.ids[ent]; ok {
+				clone.valueKeys.add(&cpy, id)
+			}
+		}
+		if c.aliases != nil {
+			for _, alias := range c.aliases.keys[ent] {
+				if clone.aliases == nil {
+					clone.aliases = newAliasMap()
+				}
+				clone.aliases.add(alias, &cpy)
+			}
+		}
+	}
+	return clone
+}
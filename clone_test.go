@@ -0,0 +1,26 @@
+package lruish
+
+import "testing"
+
+func TestClone(t *testing.T) {
+	var evicted int
+	l, err := New(2, WithOnEvict(func(k, v interface{}) { evicted++ }))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	l.Add(2, 2)
+	clone := l.Clone()
+	l.Add(3, 3)
+	if !clone.Contains(1) || clone.Contains(3) {
+		t.Errorf("clone should not see changes to the original")
+	}
+	clone.Add(4, 4)
+	clone.Remove(1)
+	if !l.Contains(2) || l.Contains(4) {
+		t.Errorf("original should not see changes to the clone")
+	}
+	if evicted != 1 {
+		t.Errorf("clone should not invoke eviction callbacks, evicted %d", evicted)
+	}
+}
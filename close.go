@@ -0,0 +1,111 @@
+package lruish
+
+import "errors"
+
+// ErrClosed is returned by the operations of a closed cache which can report
+// errors: Close, TryAdd, and functions such as SaveTo and LoadFrom.
+var ErrClosed = errors.New("lruish: cache closed")
+
+// WithPurgeOnClose makes Close purge the cache, invoking the eviction callback
+// for every remaining entry. Otherwise the entries are dropped silently.
+func WithPurgeOnClose(enabled bool) Option {
+	return func(c *config) {
+		c.purgeOnClose = enabled
+	}
+}
+
+// Close shuts down the cache. It waits for background work such as refreshes,
+// queued eviction callbacks and asynchronous closes to finish, and drops all
+// entries. A closed cache behaves as an empty cache which cannot be added to:
+// lookups miss, and modifications do nothing and report that nothing changed.
+// TryAdd and a second Close return ErrClosed.
+func (c *SynchedLRU) Close() error {
+	if c.unsubscribe != nil {
+		c.unsubscribe()
+	}
+	c.lock.Lock()
+	err := c.lru.close()
+	if c.frozen.Load() {
+		// Closed caches are empty
+		empty := make(map[interface{}]interface{})
+		c.snapshot.Store(&empty)
+	}
+	c.lock.Unlock()
+
+	if c.snapshotQuit != nil && err == nil {
+		close(c.snapshotQuit)
+	}
+
+	// Background goroutines may need the lock to finish
+	c.lru.background.Wait()
+	return err
+}
+
+// Close shuts down the cache. It waits for background work such as queued
+// eviction callbacks and asynchronous closes to finish, and drops all
+// entries. A closed cache behaves as an empty cache which cannot be added to:
+// lookups miss, and modifications do nothing and report that nothing changed.
+// TryAdd and a second Close return ErrClosed.
+func (c *lruish) Close() error {
+	err := c.close()
+	c.background.Wait()
+	return err
+}
+
+func (c *lruish) close() error {
+	if c.closed {
+		return ErrClosed
+	}
+	c.frozen = false
+	if c.purgeOnClose {
+		c.Purge()
+	}
+	if c.evictions != nil {
+		// Let the worker drain the queue and exit
+		close(c.evictions)
+	}
+	if c.events != nil {
+		close(c.events)
+	}
+	c.closed = true
+	c.items = nil
+	c.ring = nil
+	c.tags = nil
+	c.clearIndexes()
+	c.valueKeys = nil
+	c.dropAliases()
+	if c.leaks != nil {
+		c.checkLeaks(c.now())
+	}
+	return nil
+}
+
+// closedReporter is implemented by caches which can tell whether they were
+// closed.
+type closedReporter interface {
+	isClosed() bool
+}
+
+// isClosed reports whether the cache was closed.
+func isClosed(c Cache) bool {
+	r, ok := c.(closedReporter)
+	return ok && r.isClosed()
+}
+
+func (c *SynchedLRU) isClosed() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.closed
+}
+
+func (c *lruish) isClosed() bool {
+	return c.closed
+}
+
+func (c *stripedLRU) isClosed() bool {
+	return c.stripes[0].isClosed()
+}
+
+func (n *namespace) isClosed() bool {
+	return isClosed(n.root)
+}
@@ -0,0 +1,82 @@
+package lruish
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestClose(t *testing.T) {
+	var evicted int
+	l, err := New(2, WithOnEvict(func(k, v interface{}) { evicted++ }), WithPurgeOnClose(true))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	l.Add(2, 2)
+	if err := l.Close(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if evicted != 2 {
+		t.Errorf("close should have purged the entries")
+	}
+	if l.Add(3, 3); l.Contains(3) || l.Len() != 0 {
+		t.Errorf("closed cache should not accept entries")
+	}
+	if err := l.Close(); err != ErrClosed {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestCloseWaitsForRefresh(t *testing.T) {
+	done := make(chan struct{})
+	loader := func(key interface{}) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		close(done)
+		return nil, nil
+	}
+	l, err := New(2, WithRefreshAfterWrite(0, loader))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	l.Get(1)
+	l.Close()
+	select {
+	case <-done:
+	default:
+		t.Fatalf("close returned before refresh finished")
+	}
+}
+
+func TestClosedErrors(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithStripes(2)}} {
+		l, _ := New(4, opts...)
+		l.Close()
+		if err := l.TryAdd(1, 1); err != ErrClosed {
+			t.Errorf("TryAdd: have %v, want ErrClosed", err)
+		}
+		var buf bytes.Buffer
+		if err := SaveTo(l, &buf); err != ErrClosed {
+			t.Errorf("SaveTo: have %v, want ErrClosed", err)
+		}
+		if err := LoadFrom(l.Namespace("ns"), &buf); err != ErrClosed {
+			t.Errorf("LoadFrom: have %v, want ErrClosed", err)
+		}
+	}
+	loads := 0
+	c, _ := NewLoading(4, func(key interface{}) (interface{}, error) {
+		loads++
+		return key, nil
+	})
+	c.Close()
+	if _, err := c.Load(1); err != ErrClosed {
+		t.Errorf("Load: have %v, want ErrClosed", err)
+	}
+	if _, _, err := c.LoadAll([]interface{}{1, 2}); err != ErrClosed {
+		t.Errorf("LoadAll: have %v, want ErrClosed", err)
+	}
+	if loads != 0 {
+		t.Errorf("closed cache loaded %d times", loads)
+	}
+}
@@ -0,0 +1,297 @@
+// Command lruish-inspect summarizes a cache snapshot written by
+// lruish.SaveTo or lruish.StartSnapshotting, without loading it into a cache.
+//
+// Usage:
+//
+//	lruish-inspect [-entries] [-key pattern] [-limit n] [-keyfile file] snapshot
+//
+// It prints the number of entries per namespace and per type, the
+// distribution of the encoded value sizes and a histogram of the entry ages.
+// With -entries, or -key to select entries by a glob pattern matched against
+// their formatted keys, it also lists the entries themselves. Snapshots
+// encrypted with lruish.WithSnapshotKey are decrypted with the raw key read
+// from the file given by -keyfile.
+//
+// Keys and values of types registered with gob by the application can't be
+// decoded by this command, and are shown by their type name only.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/holiman/lruish"
+)
+
+// ageBuckets are the upper bounds of the buckets of the age histogram.
+var ageBuckets = []time.Duration{
+	time.Minute,
+	10 * time.Minute,
+	time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+}
+
+// options select the entries listed besides the summary.
+type options struct {
+	entries bool   // List all entries
+	key     string // List the entries with keys matching this glob pattern
+	limit   int    // Maximum number of entries listed, zero for no limit
+}
+
+func main() {
+	var opts options
+	flag.BoolVar(&opts.entries, "entries", false, "list all entries")
+	flag.StringVar(&opts.key, "key", "", "list the entries with keys matching the glob `pattern`")
+	flag.IntVar(&opts.limit, "limit", 100, "list at most `n` entries, 0 for all")
+	keyfile := flag.String("keyfile", "", "decrypt the snapshot with the key read from `file`")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] snapshot\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	// Ages are relative to when the snapshot was written
+	now := time.Now()
+	if fi, err := f.Stat(); err == nil {
+		now = fi.ModTime()
+	}
+	var r io.Reader = f
+	if *keyfile != "" {
+		key, err := os.ReadFile(*keyfile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if r, err = lruish.DecryptSnapshot(f, key); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if err := inspect(r, os.Stdout, now, opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// summary accumulates the statistics of a snapshot.
+type summary struct {
+	entries    int
+	pinned     int
+	namespaces map[string]int
+	types      map[string]int
+	sizes      map[int]int // Entries by power of two of their size
+	totalSize  int
+	ages       []int // Entries by age bucket, the last one for older entries
+	untimed    int   // Entries without a write time
+}
+
+func inspect(r io.Reader, w io.Writer, now time.Time, opts options) error {
+	sum := &summary{
+		namespaces: make(map[string]int),
+		types:      make(map[string]int),
+		sizes:      make(map[int]int),
+		ages:       make([]int, len(ageBuckets)+1),
+	}
+	var listed []lruish.SnapshotEntry
+	err := lruish.ReadSnapshot(r, func(ent lruish.SnapshotEntry) error {
+		sum.add(ent, now)
+		if selected(ent, opts) {
+			listed = append(listed, ent)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sum.print(w)
+	if opts.entries || opts.key != "" {
+		printEntries(w, listed, now, opts.limit)
+	}
+	return nil
+}
+
+func (s *summary) add(ent lruish.SnapshotEntry, now time.Time) {
+	s.entries++
+	if ent.Pinned {
+		s.pinned++
+	}
+	s.namespaces[ent.Namespace]++
+	s.types[ent.KeyType+" => "+ent.ValueType]++
+	s.sizes[sizeBucket(ent.Size)]++
+	s.totalSize += ent.Size
+	if ent.Written.IsZero() {
+		s.untimed++
+		return
+	}
+	age := now.Sub(ent.Written)
+	bucket := sort.Search(len(ageBuckets), func(i int) bool { return age < ageBuckets[i] })
+	s.ages[bucket]++
+}
+
+// sizeBucket returns the smallest power of two not below size.
+func sizeBucket(size int) int {
+	bucket := 1
+	for bucket < size {
+		bucket <<= 1
+	}
+	return bucket
+}
+
+func (s *summary) print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintf(tw, "Entries:\t%d\n", s.entries)
+	fmt.Fprintf(tw, "Pinned:\t%d\n", s.pinned)
+	fmt.Fprintf(tw, "Value bytes:\t%d\n", s.totalSize)
+
+	fmt.Fprintf(tw, "\nNamespace\tEntries\n")
+	for _, ns := range sortedKeys(s.namespaces) {
+		name := ns
+		if name == "" {
+			name = "(none)"
+		}
+		fmt.Fprintf(tw, "%s\t%d\n", name, s.namespaces[ns])
+	}
+	fmt.Fprintf(tw, "\nTypes\tEntries\n")
+	for _, typ := range sortedKeys(s.types) {
+		fmt.Fprintf(tw, "%s\t%d\n", typ, s.types[typ])
+	}
+	fmt.Fprintf(tw, "\nValue size\tEntries\n")
+	buckets := make([]int, 0, len(s.sizes))
+	for bucket := range s.sizes {
+		buckets = append(buckets, bucket)
+	}
+	sort.Ints(buckets)
+	for _, bucket := range buckets {
+		fmt.Fprintf(tw, "<= %d B\t%d\t%s\n", bucket, s.sizes[bucket], bar(s.sizes[bucket], s.entries))
+	}
+	fmt.Fprintf(tw, "\nAge\tEntries\n")
+	for i, n := range s.ages {
+		label := "older"
+		if i < len(ageBuckets) {
+			label = "< " + formatDuration(ageBuckets[i])
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", label, n, bar(n, s.entries))
+	}
+	if s.untimed > 0 {
+		fmt.Fprintf(tw, "untracked\t%d\t%s\n", s.untimed, bar(s.untimed, s.entries))
+	}
+}
+
+// selected reports whether the entry is to be listed.
+func selected(ent lruish.SnapshotEntry, opts options) bool {
+	if opts.key == "" {
+		return opts.entries
+	}
+	ok, _ := path.Match(opts.key, formatKey(ent))
+	return ok
+}
+
+func printEntries(w io.Writer, entries []lruish.SnapshotEntry, now time.Time, limit int) {
+	// Most recently used first, as in the cache
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintf(tw, "\nKey\tValue\tSize\tAge\tFlags\n")
+	for i, ent := range entries {
+		if limit > 0 && i == limit {
+			fmt.Fprintf(tw, "... %d more\n", len(entries)-limit)
+			break
+		}
+		age := "-"
+		if !ent.Written.IsZero() {
+			age = formatDuration(now.Sub(ent.Written).Round(time.Second))
+		}
+		var flags []string
+		if ent.Pinned {
+			flags = append(flags, "pinned")
+		}
+		if ent.Priority != lruish.PriorityNormal {
+			flags = append(flags, fmt.Sprintf("priority=%d", ent.Priority))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", formatKey(ent), formatValue(ent), ent.Size, age, strings.Join(flags, ","))
+	}
+}
+
+// formatKey formats the key of an entry, prefixed by its namespace.
+func formatKey(ent lruish.SnapshotEntry) string {
+	key := fmt.Sprint(ent.Key)
+	if ent.Key == nil {
+		key = "<" + ent.KeyType + ">"
+	}
+	if ent.Namespace != "" {
+		key = ent.Namespace + ":" + key
+	}
+	return key
+}
+
+// formatValue formats the value of an entry, truncated to fit on a line.
+func formatValue(ent lruish.SnapshotEntry) string {
+	if ent.Value == nil {
+		return "<" + ent.ValueType + ">"
+	}
+	var s string
+	if b, ok := ent.Value.([]byte); ok {
+		s = fmt.Sprintf("%q", b)
+	} else {
+		s = fmt.Sprintf("%v", ent.Value)
+	}
+	s = strings.NewReplacer("\n", " ", "\t", " ").Replace(s)
+	if len(s) > 40 {
+		s = s[:37] + "..."
+	}
+	return s
+}
+
+// formatDuration formats durations like time.Duration, without trailing zero
+// units.
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+// bar draws a bar proportional to the share of n in total.
+func bar(n, total int) string {
+	if total == 0 {
+		return ""
+	}
+	return strings.Repeat("#", (n*40+total-1)/total)
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/holiman/lruish"
+)
+
+func snapshot(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	c, _ := lruish.New(10)
+	c.Add("a", "hello")
+	c.Add("b", []byte(strings.Repeat("x", 100)))
+	c.Namespace("users").Add(1, 2)
+	c.Pin("a")
+
+	var buf bytes.Buffer
+	if err := lruish.SaveTo(c, &buf); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestInspectSummary(t *testing.T) {
+	var out bytes.Buffer
+	if err := inspect(snapshot(t), &out, time.Now(), options{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Entries:",
+		"users",
+		"string => []uint8",
+		"int => int",
+		"<= 128 B",
+		"untracked",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, out.String())
+		}
+	}
+	if !regexp.MustCompile(`Entries: +3\n`).MatchString(out.String()) || !regexp.MustCompile(`Pinned: +1\n`).MatchString(out.String()) {
+		t.Errorf("wrong counts in:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "hello") {
+		t.Errorf("entries listed without asking:\n%s", out.String())
+	}
+}
+
+func TestInspectEntries(t *testing.T) {
+	var out bytes.Buffer
+	if err := inspect(snapshot(t), &out, time.Now(), options{key: "users:*"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "users:1") {
+		t.Errorf("missing users:1 in:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "hello") {
+		t.Errorf("unselected entry listed:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := inspect(snapshot(t), &out, time.Now(), options{entries: true, limit: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "... 2 more") {
+		t.Errorf("entries not limited:\n%s", out.String())
+	}
+}
+
+func TestInspectAges(t *testing.T) {
+	clock := time.Now()
+	c, _ := lruish.New(10, lruish.WithExpireAfterWrite(time.Hour))
+	c.Add("a", 1)
+	var buf bytes.Buffer
+	if err := lruish.SaveTo(c, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := inspect(&buf, &out, clock.Add(5*time.Minute), options{}); err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`< 10m +1`).MatchString(out.String()) {
+		t.Errorf("entry not in the 10m bucket:\n%s", out.String())
+	}
+}
+
+func TestInspectInvalid(t *testing.T) {
+	var out bytes.Buffer
+	if err := inspect(strings.NewReader("not a snapshot"), &out, time.Now(), options{}); err == nil {
+		t.Fatal("no error for invalid snapshot")
+	}
+}
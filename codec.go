@@ -0,0 +1,120 @@
+package lruish
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Codec serializes cached values. Unmarshal must return values of the type
+// they were marshalled from, for the cache to hand them out unchanged.
+type Codec interface {
+	Marshal(value interface{}) ([]byte, error)
+	Unmarshal(data []byte) (interface{}, error)
+}
+
+// encoded is a value stored serialized by the cache's codec, and compressed
+// if its encoding is longer than the compression threshold. It is held by
+// pointer so that comparing it with other values doesn't panic.
+type encoded struct {
+	data       []byte
+	compressed bool
+}
+
+// WithCodec makes the cache store values serialized by the given codec. Values
+// are marshalled on every write and unmarshalled on every access, so callers,
+// eviction callbacks and event subscribers each get their own copy. Combined
+// with WithCompression, encodings longer than its threshold are compressed,
+// whatever the type of the value. Encoded values are neither held weakly nor
+// copied as set by WithWeakValues and WithCopyValues. CompareAndSwap and
+// CompareAndDelete compare the decoded value with reflect.DeepEqual. Values
+// the codec fails to marshal make the write panic.
+func WithCodec(codec Codec) Option {
+	return func(c *config) {
+		c.codec = codec
+	}
+}
+
+// encode serializes a value with the codec, compressing it if it's long.
+func (c *lruish) encode(value interface{}) *encoded {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		panic(fmt.Sprintf("lruish: unencodable value: %v", err))
+	}
+	if c.compressor != nil && len(data) > c.compressAbove {
+		return &encoded{data: c.compressor.Compress(nil, data), compressed: true}
+	}
+	return &encoded{data: data}
+}
+
+// decode deserializes a value encoded by encode.
+func (c *lruish) decode(v *encoded) interface{} {
+	data := v.data
+	if v.compressed {
+		var err error
+		if data, err = c.compressor.Decompress(nil, data); err != nil {
+			panic(fmt.Sprintf("lruish: corrupt compressed value: %v", err))
+		}
+	}
+	value, err := c.codec.Unmarshal(data)
+	if err != nil {
+		panic(fmt.Sprintf("lruish: corrupt encoded value: %v", err))
+	}
+	return value
+}
+
+// GobCodec returns a Codec serializing values of type T with encoding/gob. If
+// T is an interface type, the concrete types of the values must be registered
+// with gob.Register.
+func GobCodec[T any]() Codec {
+	return gobCodec[T]{}
+}
+
+type gobCodec[T any] struct{}
+
+func (gobCodec[T]) Marshal(value interface{}) ([]byte, error) {
+	v, ok := value.(T)
+	if !ok {
+		return nil, fmt.Errorf("value of type %T is not a %v", value, reflect.TypeFor[T]())
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec[T]) Unmarshal(data []byte) (interface{}, error) {
+	var v T
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// JSONCodec returns a Codec serializing values of type T with encoding/json.
+// If T is an interface type, values are decoded as json.Unmarshal decodes
+// into an empty interface, such as map[string]interface{} for objects.
+func JSONCodec[T any]() Codec {
+	return jsonCodec[T]{}
+}
+
+type jsonCodec[T any] struct{}
+
+func (jsonCodec[T]) Marshal(value interface{}) ([]byte, error) {
+	v, ok := value.(T)
+	if !ok {
+		return nil, fmt.Errorf("value of type %T is not a %v", value, reflect.TypeFor[T]())
+	}
+	return json.Marshal(v)
+}
+
+func (jsonCodec[T]) Unmarshal(data []byte) (interface{}, error) {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,99 @@
+package lruish
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type block struct {
+	Number uint64
+	Data   []byte
+}
+
+func TestCodec(t *testing.T) {
+	for name, codec := range map[string]Codec{"gob": GobCodec[block](), "json": JSONCodec[block]()} {
+		t.Run(name, func(t *testing.T) {
+			var evicted interface{}
+			l, _ := New(1, WithCodec(codec), WithOnEvict(func(key, value interface{}) {
+				evicted = value
+			}))
+			b := block{Number: 1, Data: []byte("data")}
+			l.Add("a", b)
+			if _, ok := l.(*SynchedLRU).lru.items["a"].value.(*encoded); !ok {
+				t.Fatal("value not stored encoded")
+			}
+			b.Data[0] = 'D'
+			have, ok := l.Get("a")
+			if want := (block{Number: 1, Data: []byte("data")}); !ok || !reflect.DeepEqual(have, want) {
+				t.Fatalf("have %v, want %v", have, want)
+			}
+			l.Add("b", block{Number: 2})
+			if evicted.(block).Number != 1 {
+				t.Errorf("evicted %v, want block 1", evicted)
+			}
+		})
+	}
+}
+
+func TestCodecCompression(t *testing.T) {
+	l, _ := New(1, WithCodec(GobCodec[block]()), WithCompression(flateCompressor{}, 16))
+	b := block{Number: 1, Data: bytes.Repeat([]byte("abcd"), 100)}
+	l.Add("a", b)
+	stored := l.(*SynchedLRU).lru.items["a"].value.(*encoded)
+	if !stored.compressed || len(stored.data) >= len(b.Data) {
+		t.Fatalf("encoding not compressed, %d bytes", len(stored.data))
+	}
+	if have, _ := l.Get("a"); !reflect.DeepEqual(have, b) {
+		t.Errorf("have %v, want %v", have, b)
+	}
+}
+
+func TestCodecInterface(t *testing.T) {
+	gob.Register(block{})
+	l, _ := New(2, WithCodec(GobCodec[interface{}]()))
+	l.Add("a", block{Number: 1})
+	l.Add("b", "string")
+	if have, _ := l.Get("a"); !reflect.DeepEqual(have, block{Number: 1}) {
+		t.Errorf("have %v, want block 1", have)
+	}
+	if have, _ := l.Get("b"); have != "string" {
+		t.Errorf("have %v, want string", have)
+	}
+}
+
+func TestCodecWrongType(t *testing.T) {
+	l, _ := New(1, WithCodec(JSONCodec[block]()))
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "is not a lruish.block") {
+			t.Errorf("have %v, want a type mismatch panic", r)
+		}
+	}()
+	l.Add("a", 1)
+}
+
+func TestCodecCompareAndSwap(t *testing.T) {
+	for name, codec := range map[string]Codec{"gob": GobCodec[block](), "json": JSONCodec[block]()} {
+		t.Run(name, func(t *testing.T) {
+			l, _ := New(2, WithCodec(codec), WithCompression(flateCompressor{}, 16))
+			l.Add("a", block{Number: 5, Data: []byte("data")})
+			if l.CompareAndSwap("a", block{Number: 1}, block{Number: 6}) {
+				t.Error("swap should fail on mismatching old value")
+			}
+			if !l.CompareAndSwap("a", block{Number: 5, Data: []byte("data")}, block{Number: 6}) {
+				t.Fatal("swap should succeed on an equal value")
+			}
+			if have, _ := l.Peek("a"); have.(block).Number != 6 {
+				t.Errorf("have %v, want block 6", have)
+			}
+			if l.CompareAndDelete("a", block{Number: 5}) {
+				t.Error("delete should fail on mismatching old value")
+			}
+			if !l.CompareAndDelete("a", block{Number: 6}) || l.Contains("a") {
+				t.Error("delete should succeed on an equal value")
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package lruish
+
+// Compact repacks the ring, moving all holes left by Remove to the tail while
+// preserving the order of the entries. Subsequent additions then fill the
+// holes instead of evicting entries.
+func (c *SynchedLRU) Compact() {
+	c.lock.Lock()
+	defer c.unlock()
+	c.lru.Compact()
+}
+
+// Compact repacks the ring, moving all holes left by Remove to the tail while
+// preserving the order of the entries. Subsequent additions then fill the
+// holes instead of evicting entries.
+func (c *lruish) Compact() {
+	if c.frozen {
+		return
+	}
+	var moved int
+	next := 0 // position the next entry is moved to
+	for pos := 0; pos < len(c.ring); pos++ {
+		index := (c.head + pos) % c.size
+		ent := c.ring[index]
+		if ent == nil {
+			continue
+		}
+		if pos != next {
+			target := (c.head + next) % c.size
+			c.ring[index], c.ring[target] = nil, ent
+			ent.index = target
+			moved++
+		}
+		next++
+	}
+	c.log(c.logLevels.Compact, "lruish: compacted", "entries", next, "moved", moved)
+}
+
+func (n *namespace) Compact() {
+	n.root.Compact()
+}
@@ -0,0 +1,31 @@
+package lruish
+
+import "testing"
+
+func TestCompact(t *testing.T) {
+	l, err := NewSynched(4)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		l.Add(i, i)
+	}
+	l.Remove(2)
+	l.Remove(1)
+	l.Compact()
+	var keys []interface{}
+	l.Range(func(k, v interface{}) bool {
+		keys = append(keys, k)
+		return true
+	})
+	if len(keys) != 2 || keys[0] != 3 || keys[1] != 0 {
+		t.Fatalf("compaction should preserve order: %v", keys)
+	}
+	// Both holes are now at the tail, so two additions evict nothing
+	if l.Add(4, 4) || l.Add(5, 5) {
+		t.Errorf("additions should have filled the holes")
+	}
+	if !l.Contains(0) || !l.Contains(3) {
+		t.Errorf("entries should not have been evicted")
+	}
+}
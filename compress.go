@@ -0,0 +1,68 @@
+package lruish
+
+import "fmt"
+
+// Compressor compresses cached values. Its methods follow the conventions of
+// github.com/golang/snappy: dst is an optional buffer to reuse for the result.
+type Compressor interface {
+	Compress(dst, src []byte) []byte
+	Decompress(dst, src []byte) ([]byte, error)
+}
+
+// compressed is a []byte value stored in compressed form. It is held by
+// pointer so that comparing it with other values doesn't panic.
+type compressed struct {
+	data []byte
+}
+
+// WithCompression makes the cache store []byte values longer than threshold
+// bytes compressed by the given compressor, trading CPU time on every access
+// for memory. Values are returned decompressed, to callers as well as to
+// eviction callbacks and event subscribers, so each access yields a new slice.
+func WithCompression(compressor Compressor, threshold int) Option {
+	return func(c *config) {
+		c.compressor = compressor
+		c.compressAbove = threshold
+	}
+}
+
+// pack converts a value into the form it's stored in.
+func (c *lruish) pack(value interface{}) interface{} {
+	if c.codec != nil {
+		return c.encode(value)
+	}
+	if c.weakValues {
+		if w, ok := makeWeak(value); ok {
+			return w
+		}
+	}
+	if blob, ok := value.([]byte); ok && c.compressor != nil && len(blob) > c.compressAbove {
+		return &compressed{data: c.compressor.Compress(nil, blob)}
+	}
+	if c.copyValues {
+		return copyBytes(value)
+	}
+	return value
+}
+
+// unpack converts a stored value back into the form it was added in.
+func (c *lruish) unpack(value interface{}) interface{} {
+	if v, ok := value.(*encoded); ok {
+		return c.decode(v)
+	}
+	if w, ok := value.(*weakValue); ok {
+		return w.value()
+	}
+	v, ok := value.(*compressed)
+	if !ok {
+		if c.copyValues {
+			return copyBytes(value)
+		}
+		return value
+	}
+	blob, err := c.compressor.Decompress(nil, v.data)
+	if err != nil {
+		panic(fmt.Sprintf("lruish: corrupt compressed value: %v", err))
+	}
+	return blob
+}
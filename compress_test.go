@@ -0,0 +1,78 @@
+package lruish
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"testing"
+)
+
+// flateCompressor is a Compressor based on compress/flate.
+type flateCompressor struct{}
+
+func (flateCompressor) Compress(dst, src []byte) []byte {
+	buf := bytes.NewBuffer(dst[:0])
+	w, _ := flate.NewWriter(buf, flate.BestSpeed)
+	w.Write(src)
+	w.Close()
+	return buf.Bytes()
+}
+
+func (flateCompressor) Decompress(dst, src []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst[:0])
+	_, err := io.Copy(buf, flate.NewReader(bytes.NewReader(src)))
+	return buf.Bytes(), err
+}
+
+func TestCompression(t *testing.T) {
+	var evicted []byte
+	l, err := New(1,
+		WithCompression(flateCompressor{}, 16),
+		WithOnEvict(func(key, value interface{}) {
+			evicted = value.([]byte)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	big := bytes.Repeat([]byte("abcd"), 100)
+	l.Add(1, big)
+
+	stored := l.(*SynchedLRU).lru.items[1].value
+	if c, ok := stored.(*compressed); !ok || len(c.data) >= len(big) {
+		t.Fatalf("value should be stored compressed, got %T", stored)
+	}
+	if v, ok := l.Get(1); !ok || !bytes.Equal(v.([]byte), big) {
+		t.Fatalf("bad lookup: %v", ok)
+	}
+	if v, _ := l.Peek(1); !bytes.Equal(v.([]byte), big) {
+		t.Fatal("bad peek")
+	}
+	l.Add(2, []byte("small"))
+	if !bytes.Equal(evicted, big) {
+		t.Fatal("eviction callback should receive the decompressed value")
+	}
+	if _, ok := l.(*SynchedLRU).lru.items[2].value.([]byte); !ok {
+		t.Fatal("small values should be stored as is")
+	}
+}
+
+func TestCompareAndSwapBytes(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithCompression(flateCompressor{}, 16)}} {
+		l, _ := New(4, opts...)
+		big := bytes.Repeat([]byte("abcd"), 100)
+		l.Add(1, big)
+		if l.CompareAndSwap(1, []byte("abcd"), []byte("x")) {
+			t.Error("swap should fail on mismatching old value")
+		}
+		if l.CompareAndSwap(1, "abcd", []byte("x")) {
+			t.Error("swap should fail on old value of another type")
+		}
+		if !l.CompareAndSwap(1, bytes.Clone(big), []byte("x")) {
+			t.Fatal("swap should succeed on equal content")
+		}
+		if !l.CompareAndDelete(1, []byte("x")) || l.Contains(1) {
+			t.Error("delete should succeed on equal content")
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package lruish
+
+import "bytes"
+
+// WithCopyValues makes the cache copy []byte values as they are added and
+// as they are returned, so that neither the caller's slice nor the cached one
+// is shared: mutating one never changes the other. Values reach eviction
+// callbacks and event subscribers as copies too. Compressed values are copied
+// by their compression already.
+//
+// Byte slice keys, as used by BytesCache, are always copied.
+func WithCopyValues(enabled bool) Option {
+	return func(c *config) {
+		c.copyValues = enabled
+	}
+}
+
+// copyBytes returns a copy of the value if it is a []byte, and the value
+// itself otherwise.
+func copyBytes(value interface{}) interface{} {
+	if b, ok := value.([]byte); ok {
+		return bytes.Clone(b)
+	}
+	return value
+}
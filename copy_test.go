@@ -0,0 +1,51 @@
+package lruish
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCopyValues(t *testing.T) {
+	l, _ := New(4, WithCopyValues(true))
+	value := []byte("value")
+	l.Add("a", value)
+	value[0] = 'V'
+	have, _ := l.Get("a")
+	if !bytes.Equal(have.([]byte), []byte("value")) {
+		t.Fatalf("cached value changed with the caller's slice: %q", have)
+	}
+	have.([]byte)[0] = 'V'
+	if again, _ := l.Peek("a"); !bytes.Equal(again.([]byte), []byte("value")) {
+		t.Errorf("cached value changed with the returned slice: %q", again)
+	}
+	// Other values are left alone
+	l.Add("b", 1)
+	if v, _ := l.Get("b"); v != 1 {
+		t.Errorf("have %v, want 1", v)
+	}
+}
+
+func TestCopyValuesShared(t *testing.T) {
+	l, _ := New(4)
+	value := []byte("value")
+	l.Add("a", value)
+	value[0] = 'V'
+	if have, _ := l.Get("a"); !bytes.Equal(have.([]byte), value) {
+		t.Errorf("have %q, want the slice to be shared", have)
+	}
+}
+
+func TestCopyValuesBytesCache(t *testing.T) {
+	l, _ := NewBytesCache(4, WithCopyValues(true))
+	value := []byte("value")
+	l.Add([]byte("a"), value)
+	value[0] = 'V'
+	have, _ := l.Get([]byte("a"))
+	if !bytes.Equal(have.([]byte), []byte("value")) {
+		t.Fatalf("cached value changed with the caller's slice: %q", have)
+	}
+	have.([]byte)[0] = 'V'
+	if again, _ := l.Peek([]byte("a")); !bytes.Equal(again.([]byte), []byte("value")) {
+		t.Errorf("cached value changed with the returned slice: %q", again)
+	}
+}
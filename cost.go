@@ -0,0 +1,71 @@
+package lruish
+
+// WithCostEviction makes eviction take the recomputation cost of entries into
+// account, GreedyDual style. Instead of always evicting the tail, the cache
+// evicts the entry with the least credit among the window oldest entries.
+// An entry's credit is its cost, plus an inflation value which rises with
+// every eviction, and is renewed whenever the entry is accessed. Cheap
+// entries thus go first, but expensive entries still age out if unused.
+//
+// Entries added with Add have a cost of 1, use AddWithCost to set another.
+func WithCostEviction(window int) Option {
+	return func(c *config) {
+		c.costWindow = window
+	}
+}
+
+// AddWithCost adds a value to the cache, with the given recomputation cost.
+// If the key is already cached, its cost is updated. Returns true if an
+// eviction occurred. Costs are only considered by caches configured with
+// WithCostEviction.
+func (c *SynchedLRU) AddWithCost(key, value interface{}, cost float64) bool {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.AddWithCost(key, value, cost)
+}
+
+// AddWithCost adds a value to the cache, with the given recomputation cost.
+// If the key is already cached, its cost is updated. Returns true if an
+// eviction occurred. Costs are only considered by caches configured with
+// WithCostEviction.
+func (c *lruish) AddWithCost(key, value interface{}, cost float64) bool {
+	evicted := c.Add(key, value)
+	if ent, ok := c.entry(key); ok && !c.frozen {
+		ent.cost = cost
+		ent.credit = c.inflation + cost
+	}
+	return evicted
+}
+
+// skipCostly swaps the entry at the given tail index with the entry (or hole)
+// with the least credit among the oldest entries, so that one gets evicted
+// instead.
+func (c *lruish) skipCostly(tail int) {
+	victim := tail
+	for i := 1; i < c.costWindow && i < c.size; i++ {
+		index := tail - i
+		if index < 0 {
+			index += c.size
+		}
+		ent := c.ring[index]
+		if ent == nil {
+			victim = index
+			break
+		}
+		if !ent.pinned && ent.credit < c.ring[victim].credit {
+			victim = index
+		}
+	}
+	if victim == tail {
+		return
+	}
+	if ent := c.ring[victim]; ent != nil {
+		ent.index = tail
+	}
+	c.ring[tail].index = victim
+	c.ring[tail], c.ring[victim] = c.ring[victim], c.ring[tail]
+}
+
+func (n *namespace) AddWithCost(key, value interface{}, cost float64) bool {
+	return n.root.AddWithCost(n.wrap(key), value, cost)
+}
@@ -0,0 +1,40 @@
+package lruish
+
+import "testing"
+
+func TestCostEviction(t *testing.T) {
+	l, err := NewSynched(4, WithCostEviction(4))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.AddWithCost("expensive", 0, 100)
+	l.AddWithCost("cheap", 0, 1)
+	l.AddWithCost("medium", 0, 10)
+	l.AddWithCost("medium2", 0, 10)
+
+	l.Add(1, 1)
+	if l.Contains("cheap") || !l.Contains("expensive") {
+		t.Errorf("cheapest entry should have been evicted")
+	}
+	// Everything added now is cheap compared to the expensive entry
+	for i := 2; i < 5; i++ {
+		l.Add(i, i)
+	}
+	if !l.Contains("expensive") {
+		t.Errorf("expensive entry should have survived")
+	}
+}
+
+func TestCostEvictionDefault(t *testing.T) {
+	// Without WithCostEviction, costs are ignored
+	l, err := NewSynched(2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.AddWithCost(1, 1, 100)
+	l.AddWithCost(2, 2, 1)
+	l.Add(3, 3)
+	if l.Contains(1) {
+		t.Errorf("tail should have been evicted regardless of cost")
+	}
+}
@@ -0,0 +1,60 @@
+package lruish
+
+import (
+	"fmt"
+	"io"
+)
+
+// DebugDump writes the ring layout of the cache to w, for investigating the
+// effects of holes and promotions. The lock is held while writing, so w
+// should not block.
+func (c *SynchedLRU) DebugDump(w io.Writer) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	c.lru.DebugDump(w)
+}
+
+// DebugDump writes the ring layout of the cache to w: the head position and
+// index size, followed by every ring slot with its position counted from the
+// head, and the key of the entry or a hole. The slot at the head is marked
+// with '>', and entries are flagged if pinned, expired or not of normal
+// priority.
+func (c *lruish) DebugDump(w io.Writer) {
+	if c.closed {
+		fmt.Fprintln(w, "closed")
+		return
+	}
+	fmt.Fprintf(w, "size %d, head %d, items %d, holes %d\n", c.size, c.head, len(c.items), c.size-len(c.items))
+	fmt.Fprintln(w, "  index    pos  key")
+	for i, ent := range c.ring {
+		marker := " "
+		if i == c.head {
+			marker = ">"
+		}
+		pos := (i - c.head + c.size) % c.size
+		if ent == nil {
+			fmt.Fprintf(w, "%s%6d %6d  <hole>\n", marker, i, pos)
+			continue
+		}
+		fmt.Fprintf(w, "%s%6d %6d  %v", marker, i, pos, ent.key)
+		if ent.index != i {
+			fmt.Fprintf(w, " [index %d]", ent.index)
+		}
+		if ent.pinned {
+			fmt.Fprint(w, " [pinned]")
+		}
+		if ent.priority != PriorityNormal {
+			fmt.Fprintf(w, " [priority %d]", ent.priority)
+		}
+		if c.expired(ent) {
+			fmt.Fprint(w, " [expired]")
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// DebugDump writes the ring layout of the underlying cache, which the
+// namespace shares.
+func (n *namespace) DebugDump(w io.Writer) {
+	n.root.DebugDump(w)
+}
@@ -0,0 +1,27 @@
+package lruish
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDebugDump(t *testing.T) {
+	l, _ := New(4)
+	l.Add("a", 1)
+	l.Add("b", 2)
+	l.Add("c", 3)
+	l.Pin("a")
+	l.Remove("b")
+
+	var dump strings.Builder
+	l.DebugDump(&dump)
+	want := "size 4, head 1, items 2, holes 2\n" +
+		"  index    pos  key\n" +
+		"      0      3  <hole>\n" +
+		">     1      0  c\n" +
+		"      2      1  <hole>\n" +
+		"      3      2  a [pinned]\n"
+	if dump.String() != want {
+		t.Fatalf("have\n%s\nwant\n%s", dump.String(), want)
+	}
+}
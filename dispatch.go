@@ -0,0 +1,35 @@
+package lruish
+
+// evictEvent is an eviction waiting to be passed to the eviction callback.
+type evictEvent struct {
+	key, value interface{}
+	reason     EvictionReason
+}
+
+// WithAsyncEvictCallbacks makes the cache run the eviction callback on a
+// dedicated worker goroutine, rather than inline while the cache lock is held.
+// Evictions are queued in order, and once queueSize evictions are waiting,
+// the operation causing the next one blocks until the worker catches up.
+// Close waits for the queue to drain.
+func WithAsyncEvictCallbacks(queueSize int) Option {
+	return func(c *config) {
+		c.evictQueue = queueSize
+	}
+}
+
+// dispatchEvictions starts the worker which runs the eviction callback, and
+// makes evictions go through its queue.
+func (c *lruish) dispatchEvictions(queueSize int) {
+	onEvict := c.onEvict
+	c.evictions = make(chan evictEvent, queueSize)
+	c.onEvict = func(key, value interface{}, reason EvictionReason) {
+		c.evictions <- evictEvent{key, value, reason}
+	}
+	c.background.Add(1)
+	go func(queue chan evictEvent) {
+		defer c.background.Done()
+		for ev := range queue {
+			onEvict(ev.key, ev.value, ev.reason)
+		}
+	}(c.evictions)
+}
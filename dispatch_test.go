@@ -0,0 +1,37 @@
+package lruish
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAsyncEvictCallbacks(t *testing.T) {
+	var (
+		lock    sync.Mutex
+		evicted []interface{}
+	)
+	l, err := New(1, WithAsyncEvictCallbacks(4), WithOnEvict(func(k, v interface{}) {
+		lock.Lock()
+		defer lock.Unlock()
+		evicted = append(evicted, k)
+	}))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		l.Add(i, i)
+	}
+	l.Close()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(evicted) != 9 {
+		t.Fatalf("expected 9 evictions after close, got %d", len(evicted))
+	}
+	for i, k := range evicted {
+		if k != i {
+			t.Errorf("evictions out of order: %v", evicted)
+			break
+		}
+	}
+}
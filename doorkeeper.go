@@ -0,0 +1,47 @@
+package lruish
+
+import "time"
+
+// WithDoorkeeper makes a full cache turn away keys it hasn't seen recently:
+// the first Add of such a key only records it in a Bloom filter, and the key
+// is only cached if it is added again within the window. One-off keys, as read
+// by scans, then never evict the entries which are used repeatedly. Keys are
+// let in right away while the cache has room, so warming it up is unaffected.
+//
+// Add reports no eviction for keys turned away, use TryAdd to find out
+// whether the entry was stored: it returns ErrCacheFull for those. The filter
+// is reset once the window has passed, or once it has recorded as many keys
+// as it is sized for, which is twice the capacity of the cache.
+func WithDoorkeeper(window time.Duration) Option {
+	return func(c *config) {
+		c.doorkeeperWindow = window
+	}
+}
+
+// doorkeeperBits is the number of bits per key of the doorkeeper's filter.
+const doorkeeperBits = 8
+
+// doorkeeper records the keys which were turned away recently.
+type doorkeeper struct {
+	filter   *bloomFilter
+	window   int64
+	resetAt  int64 // Time at which the filter is due to be reset
+	recorded int   // Keys recorded since the filter was reset
+}
+
+// admit reports whether the key may be cached, which is when it was turned
+// away before within the window. Otherwise it records the key.
+func (c *lruish) admit(key interface{}) bool {
+	d := c.doorkeeper
+	if now := c.now(); now >= d.resetAt || d.recorded >= 2*c.size {
+		d.filter = newBloomFilter(2*c.size, doorkeeperBits)
+		d.resetAt = now + d.window
+		d.recorded = 0
+	}
+	if d.filter.mayContain(key) {
+		return true
+	}
+	d.filter.add(key)
+	d.recorded++
+	return false
+}
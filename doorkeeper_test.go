@@ -0,0 +1,56 @@
+package lruish
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDoorkeeper(t *testing.T) {
+	clock := newFakeClock()
+	l, _ := New(100, WithClock(clock), WithDoorkeeper(time.Minute))
+	// Keys are let in while there's room
+	for i := 0; i < 100; i++ {
+		l.Add(i, i)
+	}
+	if l.Len() != 100 {
+		t.Fatalf("have %d entries, want 100", l.Len())
+	}
+	// A scan doesn't flush the cache, apart from false positives of the filter
+	admitted := 0
+	for i := 1000; i < 2000; i++ {
+		if l.TryAdd(i, i) == nil {
+			admitted++
+		}
+	}
+	if admitted > 100 {
+		t.Errorf("%d of 1000 scanned keys admitted", admitted)
+	}
+	hot := 0
+	for i := 0; i < 100; i++ {
+		if l.Contains(i) {
+			hot++
+		}
+	}
+	if hot < 100-admitted {
+		t.Errorf("have %d hot keys left, want at least %d", hot, 100-admitted)
+	}
+	// A second Add gets in, starting with an empty filter
+	clock.Advance(time.Minute)
+	l.Add("x", 1)
+	if l.Contains("x") {
+		t.Fatal("first add of a new key admitted")
+	}
+	if l.Add("x", 1); !l.Contains("x") {
+		t.Error("second add not admitted")
+	}
+	// Updates of cached keys are unaffected
+	if l.Add("x", 2); !l.Contains("x") {
+		t.Error("cached key turned away")
+	}
+	// The window passes
+	l.Add("y", 1)
+	clock.Advance(time.Minute)
+	if l.Add("y", 1); l.Contains("y") {
+		t.Error("key admitted after the window passed")
+	}
+}
@@ -0,0 +1,88 @@
+package lruish
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// WithEarlyRefreshBeta sets the aggressiveness of the early refresh signal
+// reported by GetEarlyRefresh. The default of 1 is usually right, values
+// above 1 favour refreshing earlier, values below 1 later.
+func WithEarlyRefreshBeta(beta float64) Option {
+	return func(c *config) {
+		c.earlyRefreshBeta = beta
+	}
+}
+
+// AddWithRecompute adds a value to the cache, along with the time it took to
+// compute, which GetEarlyRefresh uses to decide when to refresh the value.
+// Returns true if an eviction occurred.
+func (c *SynchedLRU) AddWithRecompute(key, value interface{}, took time.Duration) bool {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.AddWithRecompute(key, value, took)
+}
+
+// GetEarlyRefresh looks up a key's value from the cache like Get, and also
+// reports whether the caller should recompute the value ahead of its expiry.
+// See the lruish.GetEarlyRefresh method for details.
+func (c *SynchedLRU) GetEarlyRefresh(key interface{}) (value interface{}, shouldRefresh, ok bool) {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.GetEarlyRefresh(key)
+}
+
+// AddWithRecompute adds a value to the cache, along with the time it took to
+// compute, which GetEarlyRefresh uses to decide when to refresh the value.
+// Returns true if an eviction occurred.
+func (c *lruish) AddWithRecompute(key, value interface{}, took time.Duration) bool {
+	evicted := c.Add(key, value)
+	if ent, ok := c.entry(key); ok && !c.frozen {
+		ent.recompute = int64(took)
+	}
+	return evicted
+}
+
+// GetEarlyRefresh looks up a key's value from the cache like Get, and also
+// reports whether the caller should recompute the value ahead of its expiry.
+//
+// This implements probabilistic early expiration (XFetch, Vattani et al.):
+// each lookup signals a refresh with a probability which rises exponentially
+// as the entry nears its expiry, and rises sooner for values which took long
+// to compute, as recorded by AddWithRecompute. Concurrent readers of a hot
+// entry thus tend to have a single one of them refresh it before it expires,
+// instead of all of them missing at once. Entries which don't expire never
+// signal a refresh.
+func (c *lruish) GetEarlyRefresh(key interface{}) (value interface{}, shouldRefresh, ok bool) {
+	if value, ok = c.Get(key); !ok {
+		return nil, false, false
+	}
+	ent, _ := c.entry(key)
+	if !c.hasTTL() || ent.recompute == 0 {
+		return value, false, true
+	}
+	beta := c.earlyRefreshBeta
+	if beta == 0 {
+		beta = 1
+	}
+	// The random gap is an exponentially distributed multiple of the cost
+	gap := float64(ent.recompute) * beta * -math.Log(1-rand.Float64())
+	return value, float64(c.now())+gap >= float64(c.expiresAt(ent).UnixNano()), true
+}
+
+func (n *namespace) AddWithRecompute(key, value interface{}, took time.Duration) bool {
+	return n.root.AddWithRecompute(n.wrap(key), value, took)
+}
+
+func (n *namespace) GetEarlyRefresh(key interface{}) (interface{}, bool, bool) {
+	return n.root.GetEarlyRefresh(n.wrap(key))
+}
+
+func (c *stripedLRU) AddWithRecompute(key, value interface{}, took time.Duration) bool {
+	return c.stripe(key).AddWithRecompute(key, value, took)
+}
+
+func (c *stripedLRU) GetEarlyRefresh(key interface{}) (interface{}, bool, bool) {
+	return c.stripe(key).GetEarlyRefresh(key)
+}
@@ -0,0 +1,43 @@
+package lruish
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetEarlyRefresh(t *testing.T) {
+	clock := newFakeClock()
+	l, err := New(4, WithClock(clock), WithExpireAfterWrite(100*time.Second))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.AddWithRecompute(1, 1, time.Second)
+	l.Add(2, 2)
+	refreshes := func(key interface{}) int {
+		var n int
+		for i := 0; i < 100; i++ {
+			v, refresh, ok := l.GetEarlyRefresh(key)
+			if !ok || v != key {
+				t.Fatalf("bad lookup of %v: %v %v", key, v, ok)
+			}
+			if refresh {
+				n++
+			}
+		}
+		return n
+	}
+	if n := refreshes(1); n != 0 {
+		t.Fatalf("expected no refresh long before expiry, got %d", n)
+	}
+	// Half a recompute time before expiry, most lookups signal a refresh
+	clock.Advance(99*time.Second + 500*time.Millisecond)
+	if n := refreshes(1); n < 30 || n > 90 {
+		t.Fatalf("expected about 60 refreshes near expiry, got %d", n)
+	}
+	if n := refreshes(2); n != 0 {
+		t.Fatalf("entries without recompute time should not refresh early, got %d", n)
+	}
+	if _, _, ok := l.GetEarlyRefresh(3); ok {
+		t.Fatal("missing key should not be found")
+	}
+}
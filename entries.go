@@ -0,0 +1,87 @@
+package lruish
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// EntryInfo describes a cached entry along with its metadata.
+type EntryInfo struct {
+	Key      interface{}
+	Value    interface{}
+	Accesses uint64    // Successful Gets since the entry was added
+	Position int       // Distance from the head of the ring
+	Pinned   bool      // Whether the entry is protected from eviction
+	Priority Priority  // Eviction priority band
+	Written  time.Time // Time of the last write, if tracked for a TTL or refresh
+}
+
+// AccessCount returns the number of successful Gets of the key since it was
+// added, without updating its recent-ness. Returns false if the key is not in
+// the cache.
+func (c *SynchedLRU) AccessCount(key interface{}) (uint64, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.AccessCount(key)
+}
+
+// Entries returns the entries of the cache with their metadata, most recently
+// used first, without updating their recent-ness.
+func (c *SynchedLRU) Entries() []EntryInfo {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.Entries()
+}
+
+// AccessCount returns the number of successful Gets of the key since it was
+// added, without updating its recent-ness. Returns false if the key is not in
+// the cache.
+func (c *lruish) AccessCount(key interface{}) (uint64, bool) {
+	ent, ok := c.lookup(key)
+	if !ok {
+		return 0, false
+	}
+	return atomic.LoadUint64(&ent.hits), true
+}
+
+// Entries returns the entries of the cache with their metadata, most recently
+// used first, without updating their recent-ness.
+func (c *lruish) Entries() []EntryInfo {
+	entries := make([]EntryInfo, 0, len(c.items))
+	for pos := 0; pos < len(c.ring); pos++ {
+		ent := c.ring[(c.head+pos)%c.size]
+		if ent == nil || c.expired(ent) {
+			continue
+		}
+		info := EntryInfo{
+			Key:      ent.key,
+			Value:    c.unpack(ent.value),
+			Accesses: atomic.LoadUint64(&ent.hits),
+			Position: pos,
+			Pinned:   ent.pinned,
+			Priority: ent.priority,
+		}
+		if c.timed() {
+			info.Written = time.Unix(0, ent.written)
+		}
+		entries = append(entries, info)
+	}
+	return entries
+}
+
+func (n *namespace) AccessCount(key interface{}) (uint64, bool) {
+	return n.root.AccessCount(n.wrap(key))
+}
+
+// Entries returns the entries of the namespace, positioned in the ring of the
+// underlying cache.
+func (n *namespace) Entries() []EntryInfo {
+	var entries []EntryInfo
+	for _, info := range n.root.Entries() {
+		if k, ok := n.unwrap(info.Key); ok {
+			info.Key = k
+			entries = append(entries, info)
+		}
+	}
+	return entries
+}
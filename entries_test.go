@@ -0,0 +1,50 @@
+package lruish
+
+import "testing"
+
+func TestAccessCount(t *testing.T) {
+	l, _ := New(4)
+	l.Add(1, 1)
+	l.Add(2, 2)
+	for i := 0; i < 3; i++ {
+		l.Get(1)
+	}
+	if n, ok := l.AccessCount(1); !ok || n != 3 {
+		t.Fatalf("bad access count %d %v", n, ok)
+	}
+	if n, ok := l.AccessCount(2); !ok || n != 0 {
+		t.Fatalf("bad access count %d %v", n, ok)
+	}
+	if _, ok := l.AccessCount(3); ok {
+		t.Fatal("3 is not cached")
+	}
+	// Re-adding resets the count, as the entry is new
+	l.Remove(1)
+	l.Add(1, 1)
+	if n, _ := l.AccessCount(1); n != 0 {
+		t.Fatalf("expected a fresh count, got %d", n)
+	}
+}
+
+func TestEntries(t *testing.T) {
+	l, _ := New(4)
+	l.Add(1, "a")
+	l.Add(2, "b")
+	l.Pin(1)
+	l.Get(2)
+	entries := l.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if e := entries[0]; e.Key != 2 || e.Value != "b" || e.Accesses != 1 || e.Position != 0 {
+		t.Errorf("bad first entry %+v", e)
+	}
+	if e := entries[1]; e.Key != 1 || !e.Pinned || e.Position != 1 {
+		t.Errorf("bad second entry %+v", e)
+	}
+	ns := l.Namespace("ns")
+	ns.Add("x", 1)
+	if entries := ns.Entries(); len(entries) != 1 || entries[0].Key != "x" {
+		t.Errorf("bad namespace entries %+v", entries)
+	}
+}
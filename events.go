@@ -0,0 +1,103 @@
+package lruish
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// EventType identifies what happened in an Event.
+type EventType int
+
+const (
+	EventAdd    EventType = iota // An entry was added or updated
+	EventHit                     // Get found an entry
+	EventMiss                    // Get did not find an entry
+	EventEvict                   // An entry was evicted to make room
+	EventRemove                  // An entry was removed, expired or purged
+	EventPurge                   // The cache was purged, Key and Value are nil
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventAdd:
+		return "add"
+	case EventHit:
+		return "hit"
+	case EventMiss:
+		return "miss"
+	case EventEvict:
+		return "evict"
+	case EventRemove:
+		return "remove"
+	case EventPurge:
+		return "purge"
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// Event describes an operation on the cache. Reason is set for events of
+// entries leaving the cache.
+type Event struct {
+	Type   EventType
+	Key    interface{}
+	Value  interface{}
+	Reason EvictionReason
+}
+
+// WithEvents enables the event stream returned by Events, buffering up to the
+// given number of events. The cache never blocks on the stream: when the
+// buffer is full, events are dropped and counted in DroppedEvents.
+func WithEvents(bufferSize int) Option {
+	return func(c *config) {
+		c.eventBuffer = bufferSize
+	}
+}
+
+// Events returns the event stream of the cache, or nil if it was not enabled
+// with WithEvents. The channel is closed when the cache is closed.
+func (c *SynchedLRU) Events() <-chan Event {
+	return c.lru.Events()
+}
+
+// DroppedEvents returns the number of events dropped because the event stream
+// was full.
+func (c *SynchedLRU) DroppedEvents() uint64 {
+	return c.lru.DroppedEvents()
+}
+
+// Events returns the event stream of the cache, or nil if it was not enabled
+// with WithEvents. The channel is closed when the cache is closed.
+func (c *lruish) Events() <-chan Event {
+	if c.events == nil {
+		return nil
+	}
+	return c.events
+}
+
+// DroppedEvents returns the number of events dropped because the event stream
+// was full.
+func (c *lruish) DroppedEvents() uint64 {
+	return atomic.LoadUint64(&c.dropped)
+}
+
+// emit sends an event on the stream, if enabled, without blocking.
+func (c *lruish) emit(typ EventType, key, value interface{}, reason EvictionReason) {
+	if c.events == nil || c.closed {
+		return
+	}
+	select {
+	case c.events <- Event{Type: typ, Key: key, Value: value, Reason: reason}:
+	default:
+		atomic.AddUint64(&c.dropped, 1)
+	}
+}
+
+// Events returns nil: the keys of namespaced entries are opaque, so the event
+// stream is only available on the underlying cache.
+func (n *namespace) Events() <-chan Event {
+	return nil
+}
+
+func (n *namespace) DroppedEvents() uint64 {
+	return n.root.DroppedEvents()
+}
@@ -0,0 +1,50 @@
+package lruish
+
+import "testing"
+
+func TestEvents(t *testing.T) {
+	l, err := New(1, WithEvents(16))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	l.Get(1)
+	l.Get(2)
+	l.Add(2, 2)
+	l.Remove(2)
+	l.Close()
+
+	want := []Event{
+		{Type: EventAdd, Key: 1, Value: 1},
+		{Type: EventHit, Key: 1, Value: 1},
+		{Type: EventMiss, Key: 2},
+		{Type: EventEvict, Key: 1, Value: 1, Reason: ReasonCapacity},
+		{Type: EventAdd, Key: 2, Value: 2},
+		{Type: EventRemove, Key: 2, Value: 2, Reason: ReasonRemoved},
+	}
+	var have []Event
+	for ev := range l.Events() {
+		have = append(have, ev)
+	}
+	if len(have) != len(want) {
+		t.Fatalf("have %v, want %v", have, want)
+	}
+	for i := range want {
+		if have[i] != want[i] {
+			t.Errorf("event %d: have %v, want %v", i, have[i], want[i])
+		}
+	}
+}
+
+func TestEventsDropped(t *testing.T) {
+	l, err := New(4, WithEvents(1))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	l.Add(2, 2)
+	l.Add(3, 3)
+	if n := l.DroppedEvents(); n != 2 {
+		t.Errorf("expected 2 dropped events, got %d", n)
+	}
+}
@@ -0,0 +1,98 @@
+package lruish
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrFrozen is returned by TryAdd when the cache has been frozen.
+var ErrFrozen = errors.New("lruish: cache frozen")
+
+// Freeze makes the cache immutable, for caches built once and only read
+// afterwards. Additions, removals and other modifications are rejected:
+// TryAdd returns ErrFrozen, while the methods returning whether they changed
+// the cache report no change. Get no longer promotes entries, and Get, Peek
+// and Contains are served without taking the lock, from a copy of the index
+// taken when freezing. Entries which expire afterwards are thus still
+// returned by these, unless WithSnapshotReads refreshes the copy.
+func (c *SynchedLRU) Freeze() {
+	c.lock.Lock()
+	defer c.unlock()
+	if c.lru.frozen || c.lru.closed {
+		return
+	}
+	if c.reads != nil {
+		c.applyReads()
+	}
+	c.lru.Freeze()
+	snap := c.lru.index()
+	c.snapshot.Store(&snap)
+	c.frozen.Store(true)
+}
+
+// getFrozen looks up a key's value from the index of a frozen cache. Counting
+// the lookup is safe without the lock, as it's safe under the read lock and
+// frozen caches have no writers.
+func (c *SynchedLRU) getFrozen(key interface{}) (interface{}, bool) {
+	value, ok := (*c.snapshot.Load())[key]
+	if ok {
+		value, ok = c.lru.unpackLive(value)
+	}
+	c.lru.recordLookup(key, ok)
+	return value, ok
+}
+
+// Freeze makes the cache immutable. Additions, removals and other
+// modifications are rejected: TryAdd returns ErrFrozen, while the methods
+// returning whether they changed the cache report no change. Get no longer
+// promotes entries.
+func (c *lruish) Freeze() {
+	c.frozen = true
+}
+
+// getFrozen looks up a key's value without promoting it.
+func (c *lruish) getFrozen(key interface{}) (interface{}, bool) {
+	ent, ok := c.lookup(key)
+	var value interface{}
+	if ok {
+		value, ok = c.unpackLive(ent.value)
+	}
+	c.recordLookup(key, ok)
+	if !ok {
+		return nil, false
+	}
+	atomic.AddUint64(&ent.hits, 1)
+	return value, true
+}
+
+// Freeze makes the underlying cache immutable, including the entries outside
+// of the namespace.
+func (n *namespace) Freeze() {
+	n.root.Freeze()
+}
+
+// Freeze makes all stripes immutable.
+func (c *stripedLRU) Freeze() {
+	for _, s := range c.stripes {
+		s.Freeze()
+	}
+}
+
+// index returns a copy of the index, mapping the keys and aliases of live
+// entries to their packed values.
+func (c *lruish) index() map[interface{}]interface{} {
+	index := make(map[interface{}]interface{}, len(c.items))
+	for key, ent := range c.items {
+		if !c.expired(ent) {
+			index[key] = ent.value
+		}
+	}
+	if c.aliases != nil {
+		for alias, ent := range c.aliases.entries {
+			if !c.expired(ent) {
+				index[alias] = ent.value
+			}
+		}
+	}
+	return index
+}
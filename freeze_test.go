@@ -0,0 +1,97 @@
+package lruish
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	l, _ := New(4)
+	for i := 0; i < 4; i++ {
+		l.Add(i, i)
+	}
+	l.Freeze()
+	before := l.Entries()
+
+	if l.Add(4, 4) || l.AddWithPriority(5, 5, PriorityHigh) || l.AddVersioned(0, 0, 1) {
+		t.Error("add to frozen cache succeeded")
+	}
+	if err := l.TryAdd(4, 4); err != ErrFrozen {
+		t.Errorf("have %v, want ErrFrozen", err)
+	}
+	if l.Remove(0) || l.Pin(0) || l.Touch(0) || l.CompareAndSwap(0, 0, 1) {
+		t.Error("modification of frozen cache succeeded")
+	}
+	if _, ok := l.GetAndRemove(0); ok {
+		t.Error("removal from frozen cache succeeded")
+	}
+	if n := l.RemoveFunc(func(key, value interface{}) bool { return true }); n != 0 {
+		t.Errorf("removed %d entries", n)
+	}
+	l.Purge()
+	l.Resize(8)
+	// Lookups don't promote
+	for i := 0; i < 4; i++ {
+		if v, ok := l.Get(i); !ok || v != i {
+			t.Errorf("key %d: have %v, %v", i, v, ok)
+		}
+	}
+	after := l.Entries()
+	if len(after) != len(before) || l.Cap() != 4 {
+		t.Fatalf("have %d entries of %d, want 4 of 4", len(after), l.Cap())
+	}
+	for i := range before {
+		if after[i].Key != before[i].Key {
+			t.Errorf("position %d: have %v, want %v", i, after[i].Key, before[i].Key)
+		}
+	}
+	if s := l.Stats(); s.Hits != 4 {
+		t.Errorf("have %d hits, want 4", s.Hits)
+	}
+	l.Close()
+	if _, ok := l.Get(0); ok {
+		t.Error("closed cache not empty")
+	}
+	if l.Contains(0) {
+		t.Error("closed cache not empty")
+	}
+}
+
+func TestFreezeConcurrentReads(t *testing.T) {
+	l, _ := New(128, WithStripes(4))
+	for i := 0; i < 64; i++ {
+		l.Add(i, i)
+	}
+	l.Freeze()
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if v, ok := l.Get(i % 64); !ok || v != i%64 {
+					t.Errorf("key %d: have %v", i%64, v)
+					return
+				}
+				l.Add(i, i)
+			}
+		}()
+	}
+	wg.Wait()
+	if l.Len() != 64 {
+		t.Errorf("have %d entries, want 64", l.Len())
+	}
+}
+
+func TestFreezeUnsynched(t *testing.T) {
+	l, _ := NewUnsynched(2)
+	l.Add(1, 1)
+	l.Add(2, 2)
+	l.Freeze()
+	if l.Add(3, 3) || !l.Contains(1) {
+		t.Error("frozen cache changed")
+	}
+	if v, ok := l.Get(1); !ok || v != 1 {
+		t.Errorf("have %v", v)
+	}
+}
@@ -0,0 +1,50 @@
+package lruish
+
+import "context"
+
+// Future is the pending result of an asynchronous cache operation.
+type Future struct {
+	done  chan struct{}
+	value interface{}
+	ok    bool
+	err   error
+
+	// abandoned is closed when the operation can no longer complete, such as
+	// when the cache processing it shuts down. Nil if that can't happen.
+	abandoned <-chan struct{}
+}
+
+func newFuture(abandoned <-chan struct{}) *Future {
+	return &Future{done: make(chan struct{}), abandoned: abandoned}
+}
+
+// resolve completes the future. It must be called exactly once.
+func (f *Future) resolve(value interface{}, ok bool, err error) {
+	f.value, f.ok, f.err = value, ok, err
+	close(f.done)
+}
+
+// Done returns a channel which is closed once the result is available.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the result is available or ctx is done. The meaning of
+// value and ok depends on the operation, as with the synchronous methods of
+// the cache.
+func (f *Future) Wait(ctx context.Context) (value interface{}, ok bool, err error) {
+	select {
+	case <-f.done:
+		return f.value, f.ok, f.err
+	case <-f.abandoned:
+		// The operation may have completed just before being abandoned
+		select {
+		case <-f.done:
+			return f.value, f.ok, f.err
+		default:
+			return nil, false, ErrClosed
+		}
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
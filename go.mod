@@ -0,0 +1,3 @@
+module github.com/holiman/lruish
+
+go 1.24
@@ -0,0 +1,34 @@
+package lruish
+
+import "hash/maphash"
+
+// Hasher hashes keys which can't be used as map keys directly, and picks the
+// stripe of string and integer keys in a striped cache. Keys which already
+// embed a good hash can supply a hasher which simply extracts it. Hash must
+// not modify or retain the key.
+type Hasher interface {
+	Hash(key []byte) uint64
+}
+
+// mapHasher is the default Hasher, based on hash/maphash with a random seed.
+type mapHasher struct {
+	seed maphash.Seed
+}
+
+// NewMapHasher returns a Hasher based on hash/maphash, using a random seed.
+func NewMapHasher() Hasher {
+	return &mapHasher{seed: maphash.MakeSeed()}
+}
+
+func (h *mapHasher) Hash(key []byte) uint64 {
+	return maphash.Bytes(h.seed, key)
+}
+
+// WithHasher sets the Hasher used for keys which need hashing, such as the
+// keys of a BytesCache, or the string and integer keys of a cache split with
+// WithStripes. Defaults to NewMapHasher, or hash/maphash for stripes.
+func WithHasher(hasher Hasher) Option {
+	return func(c *config) {
+		c.hasher = hasher
+	}
+}
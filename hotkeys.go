@@ -0,0 +1,142 @@
+package lruish
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+)
+
+// HotKey is a frequently accessed key, as estimated by WithHotKeys.
+type HotKey struct {
+	Key   interface{}
+	Count uint64 // Estimated number of accesses, an upper bound
+	Error uint64 // Maximum overestimation of Count
+}
+
+// WithHotKeys makes the cache estimate the most frequently accessed keys
+// with a Space-Saving sketch of the given number of counters, reported by
+// HotKeys. Gets and Adds count as accesses. Keys accessed more often than
+// once per counters accesses are guaranteed to be tracked.
+func WithHotKeys(counters int) Option {
+	return func(c *config) {
+		c.hotKeys = counters
+	}
+}
+
+// HotKeys returns up to n of the most frequently accessed keys, most
+// frequent first, or nil if not tracked.
+func (c *SynchedLRU) HotKeys(n int) []HotKey {
+	// The sketch has its own lock, as buffered reads update it too
+	return c.lru.HotKeys(n)
+}
+
+// HotKeys returns up to n of the most frequently accessed keys, most
+// frequent first, or nil if not tracked.
+func (c *lruish) HotKeys(n int) []HotKey {
+	if c.hot == nil {
+		return nil
+	}
+	return c.hot.top(n)
+}
+
+// HotKeys returns up to n of the most frequently accessed keys of the
+// namespace, out of those tracked for the whole cache.
+func (n *namespace) HotKeys(count int) []HotKey {
+	var hot []HotKey
+	for _, h := range n.root.HotKeys(-1) {
+		if len(hot) == count {
+			break
+		}
+		if k, ok := n.unwrap(h.Key); ok {
+			h.Key = k
+			hot = append(hot, h)
+		}
+	}
+	return hot
+}
+
+// spaceSaving is a Space-Saving heavy hitters sketch.
+type spaceSaving struct {
+	lock     sync.Mutex
+	counters map[interface{}]*hotCounter
+	heap     hotHeap // min-heap of the counters
+	size     int
+}
+
+type hotCounter struct {
+	HotKey
+	index int // position in the heap
+}
+
+func newSpaceSaving(size int) *spaceSaving {
+	return &spaceSaving{
+		counters: make(map[interface{}]*hotCounter, size),
+		size:     size,
+	}
+}
+
+// touch counts an access to the key. When all counters are taken, the least
+// frequent key is replaced, with its count carried over as the error.
+func (s *spaceSaving) touch(key interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if ctr, ok := s.counters[key]; ok {
+		ctr.Count++
+		heap.Fix(&s.heap, ctr.index)
+		return
+	}
+	if len(s.heap) < s.size {
+		ctr := &hotCounter{HotKey: HotKey{Key: key, Count: 1}}
+		s.counters[key] = ctr
+		heap.Push(&s.heap, ctr)
+		return
+	}
+	ctr := s.heap[0]
+	delete(s.counters, ctr.Key)
+	ctr.Key, ctr.Error = key, ctr.Count
+	ctr.Count++
+	s.counters[key] = ctr
+	heap.Fix(&s.heap, 0)
+}
+
+// top returns up to n of the most frequent keys, or all if n is negative.
+func (s *spaceSaving) top(n int) []HotKey {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	hot := make([]HotKey, len(s.heap))
+	for i, ctr := range s.heap {
+		hot[i] = ctr.HotKey
+	}
+	sort.Slice(hot, func(i, j int) bool {
+		return hot[i].Count > hot[j].Count
+	})
+	if n >= 0 && n < len(hot) {
+		hot = hot[:n]
+	}
+	return hot
+}
+
+type hotHeap []*hotCounter
+
+func (h hotHeap) Len() int           { return len(h) }
+func (h hotHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+
+func (h hotHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *hotHeap) Push(x interface{}) {
+	ctr := x.(*hotCounter)
+	ctr.index = len(*h)
+	*h = append(*h, ctr)
+}
+
+func (h *hotHeap) Pop() interface{} {
+	old := *h
+	ctr := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return ctr
+}
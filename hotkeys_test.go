@@ -0,0 +1,47 @@
+package lruish
+
+import "testing"
+
+func TestHotKeys(t *testing.T) {
+	l, err := New(16, WithHotKeys(8))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(l.HotKeys(1)) != 0 {
+		t.Fatal("expected no hot keys before any access")
+	}
+	for i := 0; i < 100; i++ {
+		l.Get("hot")
+		if i%2 == 0 {
+			l.Get("warm")
+		}
+		l.Get(i) // a stream of keys seen once
+	}
+	hot := l.HotKeys(2)
+	if len(hot) != 2 || hot[0].Key != "hot" || hot[1].Key != "warm" {
+		t.Fatalf("bad hot keys %+v", hot)
+	}
+	if hot[0].Count < 100 || hot[0].Count-hot[0].Error > 100 {
+		t.Fatalf("bad count for hot key: %+v", hot[0])
+	}
+	if n := len(l.HotKeys(10)); n != 8 {
+		t.Fatalf("expected all 8 counters, got %d", n)
+	}
+	untracked, _ := New(16)
+	untracked.Get(1)
+	if untracked.HotKeys(1) != nil {
+		t.Fatal("untracked cache should report no hot keys")
+	}
+}
+
+func TestHotKeysNamespace(t *testing.T) {
+	l, _ := New(16, WithHotKeys(8))
+	ns := l.Namespace("ns")
+	ns.Get("a")
+	ns.Get("a")
+	l.Get("b")
+	hot := ns.HotKeys(5)
+	if len(hot) != 1 || hot[0].Key != "a" || hot[0].Count != 2 {
+		t.Fatalf("bad namespace hot keys %+v", hot)
+	}
+}
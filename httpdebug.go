@@ -0,0 +1,86 @@
+package lruish
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// debugReport is the JSON document served by DebugHandler.
+type debugReport struct {
+	Len         int          `json:"len"`
+	Cap         int          `json:"cap"`
+	SizeBytes   int64        `json:"sizeBytes"`
+	Utilization Utilization  `json:"utilization"`
+	Stats       Stats        `json:"stats"`
+	Hottest     []string     `json:"hottest"`
+	HotKeys     []debugHot   `json:"hotKeys,omitempty"`
+	Entries     []debugEntry `json:"entries,omitempty"`
+}
+
+type debugHot struct {
+	Key   string `json:"key"`
+	Count uint64 `json:"count"`
+	Error uint64 `json:"error"`
+}
+
+type debugEntry struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Accesses uint64 `json:"accesses"`
+}
+
+// DebugHandler returns an http.Handler serving the state of the cache as
+// JSON, meant to be mounted at a path such as /debug/lruish. The report holds
+// the occupancy and counters of the cache, and the keys nearest to the head
+// of the ring, whose number is set by the "top" query parameter (default 20).
+// If the cache tracks hot keys, as many of the most frequent ones are listed
+// too.
+//
+// If listEntries is set, the "entries" query parameter additionally lists all
+// keys and values, formatted with fmt, along with their access counts. Listing exposes the cached values and
+// holds the cache lock for the duration, so it's disabled by default.
+func DebugHandler(c Cache, listEntries bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		top := 20
+		if s := r.URL.Query().Get("top"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid top parameter", http.StatusBadRequest)
+				return
+			}
+			top = n
+		}
+		report := debugReport{
+			Len:         c.Len(),
+			Cap:         c.Cap(),
+			SizeBytes:   c.SizeBytes(),
+			Utilization: c.Utilization(),
+			Stats:       c.Stats(),
+			Hottest:     []string{},
+		}
+		for key := range c.AllKeys() {
+			if len(report.Hottest) >= top {
+				break
+			}
+			report.Hottest = append(report.Hottest, fmt.Sprint(key))
+		}
+		for _, hot := range c.HotKeys(top) {
+			report.HotKeys = append(report.HotKeys, debugHot{Key: fmt.Sprint(hot.Key), Count: hot.Count, Error: hot.Error})
+		}
+		if listEntries && r.URL.Query().Get("entries") != "" {
+			for _, info := range c.Entries() {
+				report.Entries = append(report.Entries, debugEntry{
+					Key:      fmt.Sprint(info.Key),
+					Value:    fmt.Sprint(info.Value),
+					Accesses: info.Accesses,
+				})
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	})
+}
@@ -0,0 +1,47 @@
+package lruish
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	l, _ := New(8)
+	for i := 0; i < 5; i++ {
+		l.Add(i, i*10)
+	}
+	l.Get(1)
+
+	for _, tc := range []struct {
+		url         string
+		listEntries bool
+		hottest     int
+		entries     int
+	}{
+		{"/debug/lruish", false, 5, 0},
+		{"/debug/lruish?top=2", false, 2, 0},
+		{"/debug/lruish?entries=1", false, 5, 0},
+		{"/debug/lruish?entries=1", true, 5, 5},
+	} {
+		rec := httptest.NewRecorder()
+		DebugHandler(l, tc.listEntries).ServeHTTP(rec, httptest.NewRequest("GET", tc.url, nil))
+
+		var report debugReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatalf("%s: bad JSON: %v", tc.url, err)
+		}
+		if report.Len != 5 || report.Cap != 8 || report.Stats.Hits != 1 {
+			t.Errorf("%s: bad report %+v", tc.url, report)
+		}
+		if len(report.Hottest) != tc.hottest || len(report.Entries) != tc.entries {
+			t.Errorf("%s: have %d hottest and %d entries, want %d and %d",
+				tc.url, len(report.Hottest), len(report.Entries), tc.hottest, tc.entries)
+		}
+	}
+	rec := httptest.NewRecorder()
+	DebugHandler(l, false).ServeHTTP(rec, httptest.NewRequest("GET", "/?top=x", nil))
+	if rec.Code != 400 {
+		t.Errorf("expected bad request, got %d", rec.Code)
+	}
+}
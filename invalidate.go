@@ -0,0 +1,176 @@
+package lruish
+
+import (
+	"errors"
+	"sync"
+)
+
+// Invalidation tells the replicas of a cache to drop an entry, or all entries
+// of a namespace or of the whole cache.
+type Invalidation struct {
+	Key       interface{} // Key to remove, unless All is set
+	Namespace string      // Namespace of the key, or of the entries to purge
+	All       bool        // Purge the namespace, or the whole cache if Namespace is empty
+}
+
+// Invalidator distributes invalidations between the replicas of a cache, for
+// instance over a pub-sub broker. Implementations must be safe for concurrent
+// use, and are responsible for encoding the keys used by the application.
+type Invalidator interface {
+	// Publish sends an invalidation to the other replicas. Failures should
+	// be handled by the implementation, as the cache doesn't wait for the
+	// invalidation to be delivered.
+	Publish(inv Invalidation)
+
+	// Subscribe registers fn to receive the invalidations published by the
+	// other replicas, until unsubscribe is called. Invalidations published
+	// by a replica should not be delivered back to it.
+	Subscribe(fn func(Invalidation)) (unsubscribe func(), err error)
+}
+
+// WithInvalidator keeps the cache coherent with its replicas through the
+// given invalidator. Every explicit removal publishes invalidations: Remove,
+// RemoveMany, GetAndRemove, a successful CompareAndDelete, RemoveFunc,
+// RemovePrefix, InvalidateTag, Purge and PurgeNamespace. The replicas apply
+// them without publishing them again. Entries leaving the cache through
+// eviction or expiry are not published.
+//
+// Invalidation is only supported by synchronized caches, which subscribe to
+// the invalidator on creation and unsubscribe on Close.
+func WithInvalidator(inv Invalidator) Option {
+	return func(c *config) {
+		c.invalidator = inv
+	}
+}
+
+// errInvalidatorUnsynched is returned for unsynchronized caches configured
+// with an invalidator.
+var errInvalidatorUnsynched = errors.New("invalidation requires a synchronized cache")
+
+// subscribe registers fn with the invalidator, if any, and returns the
+// function unsubscribing it, which may be called more than once.
+func subscribe(inv Invalidator, fn func(Invalidation)) (func(), error) {
+	if inv == nil {
+		return func() {}, nil
+	}
+	unsubscribe, err := inv.Subscribe(fn)
+	if err != nil {
+		return nil, err
+	}
+	return sync.OnceFunc(unsubscribe), nil
+}
+
+// invalidation returns the invalidation removing key from the cache.
+func invalidation(key interface{}) Invalidation {
+	if k, ok := key.(nsKey); ok {
+		return Invalidation{Key: k.key, Namespace: k.ns}
+	}
+	return Invalidation{Key: key}
+}
+
+// publish sends the invalidation to the replicas of the cache, if any.
+func (c *SynchedLRU) publish(inv Invalidation) {
+	if c.invalidator != nil {
+		c.invalidator.Publish(inv)
+	}
+}
+
+// publishKeys publishes the removal of each of the keys.
+func (c *SynchedLRU) publishKeys(keys []interface{}) {
+	for _, key := range keys {
+		c.publish(invalidation(key))
+	}
+}
+
+// invalidated applies an invalidation received from another replica.
+func (c *SynchedLRU) invalidated(inv Invalidation) {
+	c.lock.Lock()
+	defer c.unlock()
+	c.lru.invalidated(inv)
+}
+
+func (c *lruish) invalidated(inv Invalidation) {
+	if c.closed {
+		return
+	}
+	switch {
+	case inv.All && inv.Namespace == "":
+		c.Purge()
+	case inv.All:
+		c.PurgeNamespace(inv.Namespace)
+	case inv.Namespace != "":
+		c.Remove(nsKey{ns: inv.Namespace, key: inv.Key})
+	default:
+		c.Remove(inv.Key)
+	}
+}
+
+// Purge removes all entries from the cache.
+func (c *SynchedLRU) Purge() {
+	c.lock.Lock()
+	if !c.lru.closed {
+		c.lru.Purge()
+	}
+	c.unlock()
+	c.publish(Invalidation{All: true})
+}
+
+// Purge removes all entries of the namespace, including those of namespaces
+// nested within it.
+func (n *namespace) Purge() {
+	n.root.PurgeNamespace(n.ns)
+}
+
+// Purge removes all entries from all stripes. The locks of all stripes are
+// held throughout, so the purge is atomic: no other call sees some stripes
+// purged and others not.
+func (c *stripedLRU) Purge() {
+	c.purge()
+	c.publish(Invalidation{All: true})
+}
+
+// purge removes all entries from all stripes, without publishing it.
+func (c *stripedLRU) purge() {
+	c.lockAll()
+	defer c.unlockAll()
+	for _, s := range c.stripes {
+		if !s.lru.closed {
+			s.lru.Purge()
+		}
+	}
+}
+
+// publish sends the invalidation to the replicas of the cache, if any. The
+// stripes themselves have no invalidator, so that invalidations spanning
+// several of them are published once.
+func (c *stripedLRU) publish(inv Invalidation) {
+	if c.invalidator != nil {
+		c.invalidator.Publish(inv)
+	}
+}
+
+// publishKeys publishes the removal of each of the keys.
+func (c *stripedLRU) publishKeys(keys []interface{}) {
+	for _, key := range keys {
+		c.publish(invalidation(key))
+	}
+}
+
+// invalidated applies an invalidation received from another replica.
+func (c *stripedLRU) invalidated(inv Invalidation) {
+	if inv.All && inv.Namespace == "" {
+		c.purge()
+		return
+	}
+	if !inv.All {
+		key := inv.Key
+		if inv.Namespace != "" {
+			key = nsKey{ns: inv.Namespace, key: inv.Key}
+		}
+		c.stripe(key).invalidated(inv)
+		return
+	}
+	for _, s := range c.stripes {
+		s.invalidated(inv)
+	}
+}
@@ -0,0 +1,156 @@
+package lruish
+
+import (
+	"sync"
+	"testing"
+)
+
+// bus is an in-process pub-sub, handing out an Invalidator per replica.
+type bus struct {
+	lock        sync.Mutex
+	subscribers map[*busReplica]func(Invalidation)
+}
+
+type busReplica struct {
+	bus       *bus
+	published []Invalidation
+}
+
+func newBus() *bus {
+	return &bus{subscribers: make(map[*busReplica]func(Invalidation))}
+}
+
+func (b *bus) replica() *busReplica {
+	return &busReplica{bus: b}
+}
+
+func (r *busReplica) Publish(inv Invalidation) {
+	r.bus.lock.Lock()
+	r.published = append(r.published, inv)
+	var deliver []func(Invalidation)
+	for other, fn := range r.bus.subscribers {
+		if other != r {
+			deliver = append(deliver, fn)
+		}
+	}
+	r.bus.lock.Unlock()
+	for _, fn := range deliver {
+		fn(inv)
+	}
+}
+
+func (r *busReplica) Subscribe(fn func(Invalidation)) (func(), error) {
+	r.bus.lock.Lock()
+	defer r.bus.lock.Unlock()
+	r.bus.subscribers[r] = fn
+	return func() {
+		r.bus.lock.Lock()
+		defer r.bus.lock.Unlock()
+		delete(r.bus.subscribers, r)
+	}, nil
+}
+
+func TestInvalidator(t *testing.T) {
+	for _, stripes := range []int{1, 4} {
+		b := newBus()
+		a, err := New(16, WithStripes(stripes), WithInvalidator(b.replica()))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		other, _ := New(16, WithStripes(stripes), WithInvalidator(b.replica()))
+		for _, c := range []Cache{a, other} {
+			for i := 0; i < 4; i++ {
+				c.Add(i, i)
+				c.Namespace("ns").Add(i, i)
+			}
+		}
+		a.Remove(0)
+		if other.Contains(0) {
+			t.Fatalf("stripes %d: removal should reach the other replica", stripes)
+		}
+		a.RemoveMany([]interface{}{1, 2})
+		if other.Contains(1) || other.Contains(2) || !other.Contains(3) {
+			t.Fatalf("stripes %d: bad keys after RemoveMany: %v", stripes, other.Keys())
+		}
+		a.Namespace("ns").Remove(3)
+		if other.Namespace("ns").Contains(3) || !other.Contains(3) {
+			t.Fatalf("stripes %d: namespaced removal should only drop the namespaced key", stripes)
+		}
+		a.PurgeNamespace("ns")
+		if other.Namespace("ns").Len() != 0 || other.Len() != 1 {
+			t.Fatalf("stripes %d: namespace purge should reach the other replica", stripes)
+		}
+		other.Purge()
+		if a.Len() != 0 {
+			t.Fatalf("stripes %d: purge should reach the other replica", stripes)
+		}
+		// Closed replicas stop listening
+		other.Close()
+		a.Add(5, 5)
+		a.Remove(5)
+		a.Close()
+	}
+}
+
+func TestInvalidatorRemovals(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove func(c Cache)
+		gone   []interface{}
+	}{
+		{"GetAndRemove", func(c Cache) { c.GetAndRemove("a") }, []interface{}{"a"}},
+		{"CompareAndDelete", func(c Cache) {
+			c.CompareAndDelete("a", 0) // Different value, not published
+			c.CompareAndDelete("b", 2)
+		}, []interface{}{"b"}},
+		{"RemoveFunc", func(c Cache) {
+			c.RemoveFunc(func(key, value interface{}) bool { return value.(int) > 2 })
+		}, []interface{}{"c", "xd"}},
+		{"RemovePrefix", func(c Cache) { c.RemovePrefix("x") }, []interface{}{"xd"}},
+		{"InvalidateTag", func(c Cache) { c.InvalidateTag("t") }, []interface{}{"a", "c"}},
+	}
+	for _, stripes := range []int{1, 4} {
+		for _, tt := range tests {
+			b := newBus()
+			a, _ := New(16, WithStripes(stripes), WithInvalidator(b.replica()))
+			other, _ := New(16, WithStripes(stripes), WithInvalidator(b.replica()))
+			for _, c := range []Cache{a, other} {
+				c.AddTagged("a", 1, "t")
+				c.Add("b", 2)
+				c.AddTagged("c", 3, "t")
+				c.Add("xd", 4)
+			}
+			tt.remove(a)
+			gone := make(map[interface{}]bool)
+			for _, key := range tt.gone {
+				gone[key] = true
+			}
+			for _, key := range []interface{}{"a", "b", "c", "xd"} {
+				if have := other.Contains(key); have == gone[key] {
+					t.Errorf("stripes %d, %s: other replica holds %v: %v, want %v", stripes, tt.name, key, have, !have)
+				}
+			}
+			a.Close()
+			other.Close()
+		}
+	}
+}
+
+func TestInvalidatorAppliedOnce(t *testing.T) {
+	b := newBus()
+	first, second := b.replica(), b.replica()
+	a, _ := New(16, WithInvalidator(first))
+	other, _ := New(16, WithInvalidator(second))
+	a.Remove(1)
+	if len(first.published) != 1 || len(second.published) != 0 {
+		t.Fatalf("remote invalidations should not be republished: %v %v", first.published, second.published)
+	}
+	a.Close()
+	other.Close()
+}
+
+func TestInvalidatorUnsynched(t *testing.T) {
+	if _, err := NewUnsynched(16, WithInvalidator(newBus().replica())); err == nil {
+		t.Fatal("unsynched cache should reject an invalidator")
+	}
+}
@@ -0,0 +1,66 @@
+package lruish
+
+import (
+	"fmt"
+	"strings"
+)
+
+// unlock releases the write lock, first verifying the internal invariants of
+// the cache if built with the lruish_invariants tag.
+func (c *SynchedLRU) unlock() {
+	if checkInvariants {
+		if err := c.lru.verify(); err != nil {
+			var dump strings.Builder
+			c.lru.DebugDump(&dump)
+			panic(fmt.Sprintf("lruish: invariant violated: %v\n%s", err, dump.String()))
+		}
+	}
+	c.lock.Unlock()
+}
+
+// verify checks the consistency of the ring, the index and the derived
+// counters, returning the first violation found.
+func (c *lruish) verify() error {
+	if c.closed {
+		return nil
+	}
+	if c.head < 0 || c.head >= c.size {
+		return fmt.Errorf("head %d out of bounds [0, %d)", c.head, c.size)
+	}
+	if len(c.ring) != c.size {
+		return fmt.Errorf("ring length %d, size %d", len(c.ring), c.size)
+	}
+	var (
+		entries int
+		bands   [numPriorities]int
+		nsLens  = make(map[string]int)
+	)
+	for i, ent := range c.ring {
+		if ent == nil {
+			continue
+		}
+		entries++
+		if ent.index != i {
+			return fmt.Errorf("entry %v at index %d believes it's at %d", ent.key, i, ent.index)
+		}
+		if c.items[ent.key] != ent {
+			return fmt.Errorf("entry %v at index %d is not indexed", ent.key, i)
+		}
+		bands[ent.priority.band()]++
+		if k, ok := ent.key.(nsKey); ok {
+			nsLens[k.ns]++
+		}
+	}
+	if entries != len(c.items) {
+		return fmt.Errorf("%d entries in the ring, %d in the index", entries, len(c.items))
+	}
+	if bands != c.bands {
+		return fmt.Errorf("priority bands %v, counted %v", c.bands, bands)
+	}
+	for ns, stats := range c.namespaces {
+		if stats.Len != nsLens[ns] {
+			return fmt.Errorf("namespace %q has length %d, counted %d", ns, stats.Len, nsLens[ns])
+		}
+	}
+	return nil
+}
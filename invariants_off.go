@@ -0,0 +1,8 @@
+//go:build !lruish_invariants
+
+package lruish
+
+// checkInvariants makes synchronized caches verify their internal invariants
+// after every write, panicking on violation. Enable it with the
+// lruish_invariants build tag.
+const checkInvariants = false
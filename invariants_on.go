@@ -0,0 +1,7 @@
+//go:build lruish_invariants
+
+package lruish
+
+// checkInvariants makes synchronized caches verify their internal invariants
+// after every write, panicking on violation.
+const checkInvariants = true
@@ -0,0 +1,28 @@
+package lruish
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	c, _ := newLruish(4, newConfig(nil))
+	for i := 0; i < 6; i++ {
+		c.Add(i, i)
+	}
+	c.Remove(4)
+	c.Get(2)
+	if err := c.verify(); err != nil {
+		t.Fatalf("healthy cache failed verification: %v", err)
+	}
+	// Corrupt the index of an entry
+	c.ring[c.head].index++
+	if err := c.verify(); err == nil || !strings.Contains(err.Error(), "believes") {
+		t.Fatalf("expected index violation, got %v", err)
+	}
+	c.ring[c.head].index--
+	c.bands[0]++
+	if err := c.verify(); err == nil {
+		t.Fatal("expected band violation")
+	}
+}
@@ -0,0 +1,52 @@
+package lruish
+
+import "iter"
+
+// All returns an iterator over the entries in the cache, most recently used
+// first, as with Range. In a synchronized cache the read lock is held while
+// iterating, so the loop body must not modify the cache.
+func (c *SynchedLRU) All() iter.Seq2[interface{}, interface{}] {
+	return c.Range
+}
+
+// AllKeys returns an iterator over the keys in the cache, most recently used
+// first. Unlike Keys, it doesn't copy them into a slice.
+func (c *SynchedLRU) AllKeys() iter.Seq[interface{}] {
+	return keysOf(c.Range)
+}
+
+// AllValues returns an iterator over the values in the cache, most recently
+// used first.
+func (c *SynchedLRU) AllValues() iter.Seq[interface{}] {
+	return valuesOf(c.Range)
+}
+
+// All returns an iterator over the entries in the cache, most recently used
+// first, as with Range.
+func (c *lruish) All() iter.Seq2[interface{}, interface{}] {
+	return c.Range
+}
+
+// AllKeys returns an iterator over the keys in the cache, most recently used
+// first. Unlike Keys, it doesn't copy them into a slice.
+func (c *lruish) AllKeys() iter.Seq[interface{}] {
+	return keysOf(c.Range)
+}
+
+// AllValues returns an iterator over the values in the cache, most recently
+// used first.
+func (c *lruish) AllValues() iter.Seq[interface{}] {
+	return valuesOf(c.Range)
+}
+
+func keysOf(all iter.Seq2[interface{}, interface{}]) iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		all(func(k, _ interface{}) bool { return yield(k) })
+	}
+}
+
+func valuesOf(all iter.Seq2[interface{}, interface{}]) iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		all(func(_, v interface{}) bool { return yield(v) })
+	}
+}
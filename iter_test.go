@@ -0,0 +1,32 @@
+package lruish
+
+import "testing"
+
+func TestIterators(t *testing.T) {
+	l, err := NewSynched(4)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		l.Add(i, i*10)
+	}
+	var n int
+	for k, v := range l.All() {
+		if v != k.(int)*10 {
+			t.Errorf("bad value for %v: %v", k, v)
+		}
+		if n++; n == 2 {
+			break
+		}
+	}
+	var keys, values []interface{}
+	for k := range l.AllKeys() {
+		keys = append(keys, k)
+	}
+	for v := range l.AllValues() {
+		values = append(values, v)
+	}
+	if len(keys) != 4 || keys[0] != 3 || len(values) != 4 || values[0] != 30 {
+		t.Errorf("bad iteration: %v, %v", keys, values)
+	}
+}
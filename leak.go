@@ -0,0 +1,102 @@
+package lruish
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// WithLeakDetection is a debugging aid which reports values implementing
+// io.Closer that left the cache through eviction, Remove or Purge, and were
+// not closed within the given window. The reports go to the logger set by
+// WithLogger, at the Leak level of WithLogLevels, and help catch resources
+// leaked because eviction callbacks were not wired up to close them.
+//
+// To tell whether a value was closed, the eviction callbacks and the event
+// stream are handed closers wrapped in a *TrackedCloser, which records the
+// call to Close. Callbacks should thus close values through io.Closer, and
+// use Unwrap to reach the original value. If the cache closes the values
+// itself, as set by WithCloseOnEvict, detection is disabled. Leaks are checked
+// for as other values leave the cache, and when it is closed. The values are
+// kept alive until they are checked.
+func WithLeakDetection(window time.Duration) Option {
+	return func(c *config) {
+		c.leakWindow = window
+	}
+}
+
+// TrackedCloser wraps the closers leaving a cache configured with
+// WithLeakDetection, recording whether they were closed.
+type TrackedCloser struct {
+	closer io.Closer
+	closed atomic.Bool
+}
+
+// Close closes the wrapped closer, and records that it was.
+func (t *TrackedCloser) Close() error {
+	t.closed.Store(true)
+	return t.closer.Close()
+}
+
+// Unwrap returns the wrapped closer, the value which left the cache.
+func (t *TrackedCloser) Unwrap() io.Closer {
+	return t.closer
+}
+
+// leakCandidate is a closer which left the cache, to be checked for having
+// been closed.
+type leakCandidate struct {
+	key     interface{}
+	closer  *TrackedCloser
+	evicted int64
+}
+
+// leakDetector tracks the closers which left the cache within the window.
+type leakDetector struct {
+	window  int64
+	pending []leakCandidate // By the time they left the cache
+}
+
+// trackLeak starts tracking the value if it is a closer, and reports the
+// tracked ones which are due. Returns the value to hand out to the eviction
+// callbacks, which is wrapped if tracked.
+func (c *lruish) trackLeak(key, value interface{}) interface{} {
+	now := c.now()
+	c.checkLeaks(now)
+	closer, ok := value.(io.Closer)
+	if !ok {
+		return value
+	}
+	tracked := &TrackedCloser{closer: closer}
+	c.leaks.pending = append(c.leaks.pending, leakCandidate{key: key, closer: tracked, evicted: now})
+	return tracked
+}
+
+// checkLeaks reports the tracked closers whose window has passed, unless they
+// have been closed, and stops tracking them.
+func (c *lruish) checkLeaks(now int64) {
+	n := 0
+	for _, cand := range c.leaks.pending {
+		if now-cand.evicted < c.leaks.window {
+			break
+		}
+		n++
+		if cand.closer.closed.Load() {
+			continue
+		}
+		level := c.logLevels.Leak
+		if level == nil {
+			level = slog.LevelWarn
+		}
+		args := []interface{}{"key", cand.key}
+		if k, ok := cand.key.(nsKey); ok {
+			args = []interface{}{"namespace", k.ns, "key", k.key}
+		}
+		args = append(args, "type", fmt.Sprintf("%T", cand.closer.closer), "evicted", time.Unix(0, cand.evicted))
+		c.log(level, "lruish: evicted value not closed", args...)
+	}
+	clear(c.leaks.pending[:n])
+	c.leaks.pending = c.leaks.pending[n:]
+}
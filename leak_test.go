@@ -0,0 +1,105 @@
+package lruish
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// opaqueCloser is a closer which can't tell whether it was closed.
+type opaqueCloser struct{}
+
+func (opaqueCloser) Close() error { return nil }
+
+func TestDetectLeaks(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	clock := newFakeClock()
+	closeEvicted := true
+	c, _ := New(2, WithLogger(logger), WithClock(clock), WithLeakDetection(time.Second),
+		WithOnEvict(func(key, value interface{}) {
+			if closeEvicted {
+				value.(io.Closer).Close()
+			}
+		}))
+	c.Add("a", &testCloser{})
+	c.Add("b", &testCloser{})
+	c.Add("c", &testCloser{}) // Evicts a, which is closed
+	closeEvicted = false
+	c.Remove("b") // Not closed
+
+	clock.Advance(time.Second)
+	c.Add("d", &testCloser{}) // Evicts nothing, no check
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected report: %s", buf.String())
+	}
+	c.Remove("c") // Checks the due ones, c is not due yet
+	have := strings.TrimSpace(buf.String())
+	if want := `level=WARN msg="lruish: evicted value not closed" key=b type=*lruish.testCloser`; !strings.Contains(have, want) || strings.Count(have, "\n") != 0 {
+		t.Errorf("have %q, want %q", have, want)
+	}
+	buf.Reset()
+	clock.Advance(time.Second)
+	c.Close()
+	if want := `key=c`; !strings.Contains(buf.String(), want) {
+		t.Errorf("have %q, want a report of c", buf.String())
+	}
+}
+
+func TestDetectLeaksCloseOnEvict(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	clock := newFakeClock()
+	c, _ := New(1, WithLogger(logger), WithClock(clock), WithLeakDetection(time.Second), WithCloseOnEvict(true))
+	c.Add("a", opaqueCloser{})
+	c.Add("b", opaqueCloser{})
+	clock.Advance(time.Second)
+	c.Purge()
+	c.Close()
+	if buf.Len() != 0 {
+		t.Errorf("unexpected report: %s", buf.String())
+	}
+}
+
+func TestDetectLeaksNamespace(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	clock := newFakeClock()
+	c, _ := New(2, WithLogger(logger), WithClock(clock), WithLeakDetection(time.Second))
+	c.Namespace("ns").Add("a", opaqueCloser{})
+	c.Purge()
+	clock.Advance(time.Second)
+	c.Close()
+	if want := `namespace=ns key=a type=lruish.opaqueCloser`; !strings.Contains(buf.String(), want) {
+		t.Errorf("have %q, want %q", buf.String(), want)
+	}
+}
+
+func TestDetectLeaksUnwrap(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	clock := newFakeClock()
+	var evicted []interface{}
+	c, _ := New(1, WithLogger(logger), WithClock(clock), WithLeakDetection(time.Second),
+		WithOnEvict(func(key, value interface{}) {
+			evicted = append(evicted, value)
+		}))
+	a := &testCloser{}
+	c.Add("a", a)
+	c.Add("b", 1)
+	c.Remove("b")
+	tracked, ok := evicted[0].(*TrackedCloser)
+	if !ok || tracked.Unwrap() != a || evicted[1] != 1 {
+		t.Fatalf("have %v, want a tracked a and a bare 1", evicted)
+	}
+	// Closing the value itself, rather than the wrapper, isn't recorded
+	a.Close()
+	clock.Advance(time.Second)
+	c.Close()
+	if want := `key=a type=*lruish.testCloser`; !strings.Contains(buf.String(), want) {
+		t.Errorf("have %q, want %q", buf.String(), want)
+	}
+}
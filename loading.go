@@ -0,0 +1,301 @@
+package lruish
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNotFound is returned by LoadingCache.Load for keys which the batch loader
+// returned no value for.
+var ErrNotFound = errors.New("lruish: key not found")
+
+// ErrLoadPanicked is returned to the lookups waiting on a load whose loader
+// panicked. The lookup which ran the loader panics itself.
+var ErrLoadPanicked = errors.New("lruish: loader panicked")
+
+// LoadingCache is a thread-safe cache which loads missing values on demand.
+// Concurrent lookups of the same missing key share a single load.
+type LoadingCache struct {
+	Cache
+
+	loader      func(key interface{}) (interface{}, error)
+	batchLoader func(keys []interface{}) (map[interface{}]interface{}, error)
+	lock        sync.Mutex
+	calls       map[interface{}]*loadCall // loads in flight
+}
+
+// loadCall is a load in flight, which waiting lookups share the result of.
+type loadCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// WithBatchLoader makes a LoadingCache load all keys missing from a GetMany or
+// LoadAll in a single call to loadMany, instead of one by one. Keys missing
+// from the returned map are reported as not found, and are not cached. If
+// the cache has no single key loader, Load uses loadMany too.
+func WithBatchLoader(loadMany func(keys []interface{}) (map[interface{}]interface{}, error)) Option {
+	return func(c *config) {
+		c.batchLoader = loadMany
+	}
+}
+
+// NewLoading creates a loading cache of the given size, which calls loader to
+// obtain the values of keys missing from the cache. Optional features are
+// configured through opts. The loader may be nil if a batch loader is
+// configured with WithBatchLoader.
+func NewLoading(size int, loader func(key interface{}) (interface{}, error), opts ...Option) (*LoadingCache, error) {
+	batchLoader := newConfig(opts).batchLoader
+	if loader == nil && batchLoader == nil {
+		return nil, errors.New("must provide a loader or a batch loader")
+	}
+	cache, err := New(size, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &LoadingCache{
+		Cache:       cache,
+		loader:      loader,
+		batchLoader: batchLoader,
+		calls:       make(map[interface{}]*loadCall),
+	}, nil
+}
+
+// Get looks up a key's value from the cache, loading it if missing. Returns
+// false if the loader fails; use Load to obtain the error.
+func (c *LoadingCache) Get(key interface{}) (value interface{}, ok bool) {
+	value, err := c.Load(key)
+	return value, err == nil
+}
+
+// Load looks up a key's value from the cache, loading it if missing. Failed
+// loads are not cached, so the next lookup tries again. Once the cache is
+// closed, Load returns ErrClosed without loading.
+func (c *LoadingCache) Load(key interface{}) (interface{}, error) {
+	return c.LoadContext(context.Background(), key)
+}
+
+// LoadContext is like Load, but stops waiting for the value once ctx is done,
+// returning ctx.Err(). The load itself isn't abandoned: it carries on in the
+// background, still shared with other lookups of the key, and its value is
+// cached once loaded.
+func (c *LoadingCache) LoadContext(ctx context.Context, key interface{}) (interface{}, error) {
+	if value, ok := c.Cache.Get(key); ok {
+		return value, nil
+	}
+	if isClosed(c.Cache) {
+		return nil, ErrClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	if call, ok := c.calls[key]; ok {
+		c.lock.Unlock()
+		return call.wait(ctx)
+	}
+	// A load may have completed since the first lookup
+	if value, ok := c.Cache.Get(key); ok {
+		c.lock.Unlock()
+		return value, nil
+	}
+	// The error stands unless the loader returns
+	call := &loadCall{done: make(chan struct{}), err: ErrLoadPanicked}
+	c.calls[key] = call
+	c.lock.Unlock()
+
+	start(ctx, func() {
+		defer func() {
+			c.lock.Lock()
+			delete(c.calls, key)
+			c.lock.Unlock()
+			close(call.done)
+		}()
+		call.value, call.err = c.load(key)
+		if call.err == nil {
+			c.Cache.Add(key, call.value)
+		}
+	})
+	return call.wait(ctx)
+}
+
+// wait waits for the call to complete and returns its result, or ctx.Err()
+// if ctx is done first.
+func (call *loadCall) wait(ctx context.Context) (interface{}, error) {
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// start runs load in the calling goroutine if ctx can't be cancelled, so that
+// a panicking loader panics the caller, and in the background otherwise, so
+// that the caller can stop waiting for it. A loader panicking in the
+// background fails the lookups waiting on it with ErrLoadPanicked, as there
+// is no caller left to panic.
+func start(ctx context.Context, load func()) {
+	if ctx.Done() == nil {
+		load()
+		return
+	}
+	go func() {
+		defer func() { recover() }()
+		load()
+	}()
+}
+
+// load loads a single key, with the batch loader if there is no loader.
+func (c *LoadingCache) load(key interface{}) (interface{}, error) {
+	if c.loader != nil {
+		return c.loader(key)
+	}
+	loaded, err := c.batchLoader([]interface{}{key})
+	if err != nil {
+		return nil, err
+	}
+	value, ok := loaded[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return value, nil
+}
+
+// GetMany looks up the values of several keys from the cache, loading the
+// missing ones. Keys which fail to load are reported as not found; use
+// LoadAll to obtain the error.
+func (c *LoadingCache) GetMany(keys []interface{}) ([]interface{}, []bool) {
+	values, ok, _ := c.LoadAll(keys)
+	return values, ok
+}
+
+// LoadAll looks up the values of several keys from the cache, loading the
+// missing ones. With a batch loader, the keys not already being loaded are
+// fetched in a single call and added to the cache at once; otherwise they
+// are loaded one by one. Returns the first load error encountered, keys
+// which failed to load or weren't found are reported as not ok. Once the
+// cache is closed, LoadAll returns ErrClosed without loading.
+func (c *LoadingCache) LoadAll(keys []interface{}) (values []interface{}, ok []bool, err error) {
+	return c.LoadAllContext(context.Background(), keys)
+}
+
+// LoadAllContext is like LoadAll, but stops waiting once ctx is done,
+// returning ctx.Err() along with the values available by then. As with
+// LoadContext, the loads carry on in the background.
+func (c *LoadingCache) LoadAllContext(ctx context.Context, keys []interface{}) (values []interface{}, ok []bool, err error) {
+	values, ok = c.Cache.GetMany(keys)
+	if isClosed(c.Cache) {
+		return values, ok, ErrClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return values, ok, err
+	}
+	if c.batchLoader == nil {
+		for i, key := range keys {
+			if ok[i] {
+				continue
+			}
+			value, loadErr := c.LoadContext(ctx, key)
+			if loadErr != nil {
+				if loadErr == ctx.Err() {
+					return values, ok, loadErr
+				}
+				if err == nil {
+					err = loadErr
+				}
+				continue
+			}
+			values[i], ok[i] = value, true
+		}
+		return values, ok, err
+	}
+	// Claim the missing keys nobody is loading yet, and wait for the others
+	var (
+		waiting = make(map[int]*loadCall)
+		owned   = make(map[interface{}]*loadCall)
+		load    []interface{}
+	)
+	c.lock.Lock()
+	for i, key := range keys {
+		if ok[i] {
+			continue
+		}
+		if call, loading := c.calls[key]; loading {
+			waiting[i] = call
+			continue
+		}
+		call := &loadCall{done: make(chan struct{}), err: ErrLoadPanicked}
+		c.calls[key] = call
+		owned[key] = call
+		waiting[i] = call
+		load = append(load, key)
+	}
+	c.lock.Unlock()
+
+	if len(load) > 0 {
+		start(ctx, func() { c.loadBatch(load, owned) })
+	}
+	for i, call := range waiting {
+		value, loadErr := call.wait(ctx)
+		switch {
+		case loadErr == nil:
+			values[i], ok[i] = value, true
+		case loadErr == ctx.Err():
+			return values, ok, loadErr
+		case loadErr != ErrNotFound && err == nil:
+			err = loadErr
+		}
+	}
+	return values, ok, err
+}
+
+// loadBatch loads the given keys with the batch loader, adds the results to
+// the cache in one go, and completes the calls of the keys.
+func (c *LoadingCache) loadBatch(keys []interface{}, calls map[interface{}]*loadCall) {
+	defer func() {
+		c.lock.Lock()
+		for key := range calls {
+			delete(c.calls, key)
+		}
+		c.lock.Unlock()
+		for _, call := range calls {
+			close(call.done)
+		}
+	}()
+	loaded, err := c.batchLoader(keys)
+	var addKeys, addValues []interface{}
+	for _, key := range keys {
+		call := calls[key]
+		if err != nil {
+			call.err = err
+			continue
+		}
+		value, found := loaded[key]
+		if !found {
+			call.err = ErrNotFound
+			continue
+		}
+		call.value, call.err = value, nil
+		addKeys, addValues = append(addKeys, key), append(addValues, value)
+	}
+	c.Cache.AddMany(addKeys, addValues)
+}
+
+// GetAsync looks up a key's value from the cache, loading it in the
+// background if missing, and returns a future for the result. Concurrent
+// lookups of the same key share a single load, as with Load.
+func (c *LoadingCache) GetAsync(key interface{}) *Future {
+	f := newFuture(nil)
+	if value, ok := c.Cache.Get(key); ok {
+		f.resolve(value, true, nil)
+		return f
+	}
+	go func() {
+		value, err := c.Load(key)
+		f.resolve(value, err == nil, err)
+	}()
+	return f
+}
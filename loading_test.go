@@ -0,0 +1,268 @@
+package lruish
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoading(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	c, err := NewLoading(10, func(key interface{}) (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return key.(int) * 2, nil
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, ok := c.Get(21); !ok || v != 42 {
+				t.Errorf("bad lookup: %v %v", v, ok)
+			}
+		}()
+	}
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Fatalf("expected a single load, got %d", n)
+	}
+	if !c.Contains(21) {
+		t.Fatal("loaded value should be cached")
+	}
+}
+
+func TestLoadingError(t *testing.T) {
+	fail := errors.New("boom")
+	var loads int
+	c, err := NewLoading(10, func(key interface{}) (interface{}, error) {
+		loads++
+		return nil, fail
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := c.Load(1); err != fail {
+		t.Fatalf("expected loader error, got %v", err)
+	}
+	if _, ok := c.Get(1); ok || loads != 2 {
+		t.Fatalf("failed loads should not be cached, %d loads", loads)
+	}
+}
+
+func TestLoadingPanic(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	c, err := NewLoading(10, func(key interface{}) (interface{}, error) {
+		close(started)
+		<-release
+		panic("boom")
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	panicked := make(chan interface{})
+	go func() {
+		defer func() { panicked <- recover() }()
+		c.Load(1)
+	}()
+	<-started
+	// This is the call which concurrent lookups wait on
+	c.lock.Lock()
+	call := c.calls[1]
+	c.lock.Unlock()
+	close(release)
+	if r := <-panicked; r != "boom" {
+		t.Errorf("have panic %v, want boom", r)
+	}
+	<-call.done
+	if call.err != ErrLoadPanicked {
+		t.Errorf("have %v, want ErrLoadPanicked", call.err)
+	}
+	if c.Contains(1) {
+		t.Error("failed load cached")
+	}
+}
+
+func TestLoadContext(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	c, err := NewLoading(10, func(key interface{}) (interface{}, error) {
+		close(started)
+		<-release
+		return 2, nil
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	// Both the lookup starting the load and one joining it give up on cancel
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	go func() {
+		_, err := c.LoadContext(ctx, 1)
+		errs <- err
+	}()
+	<-started
+	go func() {
+		_, err := c.LoadContext(ctx, 1)
+		errs <- err
+	}()
+	cancel()
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != context.Canceled {
+			t.Errorf("have %v, want context.Canceled", err)
+		}
+	}
+	if _, err := c.LoadContext(ctx, 2); err != context.Canceled {
+		t.Errorf("have %v, want context.Canceled", err)
+	}
+	// The load carries on, and its value is cached for the next lookup
+	close(release)
+	if v, err := c.Load(1); err != nil || v != 2 {
+		t.Errorf("have %v, %v, want 2", v, err)
+	}
+}
+
+func TestLoadAllContext(t *testing.T) {
+	release := make(chan struct{})
+	c, _ := NewLoading(10, nil, WithBatchLoader(func(keys []interface{}) (map[interface{}]interface{}, error) {
+		<-release
+		loaded := make(map[interface{}]interface{})
+		for _, key := range keys {
+			loaded[key] = key
+		}
+		return loaded, nil
+	}))
+	c.Add(1, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	values, ok, err := c.LoadAllContext(ctx, []interface{}{1, 2})
+	if err != context.DeadlineExceeded {
+		t.Fatalf("have %v, want context.DeadlineExceeded", err)
+	}
+	if !ok[0] || values[0] != 1 || ok[1] {
+		t.Errorf("have %v %v, want only the cached value", values, ok)
+	}
+	close(release)
+	if values, ok, err := c.LoadAll([]interface{}{2}); err != nil || !ok[0] || values[0] != 2 {
+		t.Errorf("have %v %v %v, want 2", values, ok, err)
+	}
+}
+
+func TestLoadAllBatch(t *testing.T) {
+	var batches [][]interface{}
+	c, err := NewLoading(10, nil, WithBatchLoader(func(keys []interface{}) (map[interface{}]interface{}, error) {
+		batches = append(batches, keys)
+		loaded := make(map[interface{}]interface{})
+		for _, key := range keys {
+			if key.(int) != 3 {
+				loaded[key] = key.(int) * 2
+			}
+		}
+		return loaded, nil
+	}))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	c.Add(1, 100)
+	values, ok, err := c.LoadAll([]interface{}{1, 2, 3, 4, 2})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	want := []interface{}{100, 4, nil, 8, 4}
+	for i := range want {
+		if values[i] != want[i] || ok[i] != (want[i] != nil) {
+			t.Fatalf("bad result %d: %v %v", i, values[i], ok[i])
+		}
+	}
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Fatalf("expected a single batch of the 3 missing keys, got %v", batches)
+	}
+	if !c.Contains(2) || !c.Contains(4) || c.Contains(3) {
+		t.Fatal("only found values should be cached")
+	}
+	if _, err := c.Load(3); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if v, err := c.Load(5); err != nil || v != 10 {
+		t.Fatalf("single load through the batch loader failed: %v %v", v, err)
+	}
+}
+
+func TestLoadingNoLoader(t *testing.T) {
+	if _, err := NewLoading(10, nil); err == nil {
+		t.Fatal("expected error without a loader")
+	}
+	batch := WithBatchLoader(func(keys []interface{}) (map[interface{}]interface{}, error) {
+		return map[interface{}]interface{}{keys[0]: 1}, nil
+	})
+	c, err := NewLoading(10, nil, batch)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if v, err := c.Load("a"); err != nil || v != 1 {
+		t.Errorf("have %v, %v, want 1", v, err)
+	}
+}
+
+func TestLoadAllBatchError(t *testing.T) {
+	c, _ := NewLoading(10, nil, WithBatchLoader(func(keys []interface{}) (map[interface{}]interface{}, error) {
+		return nil, errors.New("backend down")
+	}))
+	values, ok := c.GetMany([]interface{}{1, 2})
+	if ok[0] || ok[1] || values[0] != nil {
+		t.Fatalf("failed loads should not be found: %v %v", values, ok)
+	}
+	if _, _, err := c.LoadAll([]interface{}{1}); err == nil {
+		t.Fatal("expected the batch error")
+	}
+	if c.Len() != 0 {
+		t.Fatal("nothing should be cached")
+	}
+}
+
+func TestLoadingGetAsync(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	c, _ := NewLoading(10, func(key interface{}) (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		if key == "bad" {
+			return nil, errors.New("load failed")
+		}
+		return key.(int) * 2, nil
+	})
+	futures := []*Future{c.GetAsync(1), c.GetAsync(2), c.GetAsync(1)}
+	failed := c.GetAsync("bad")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	if _, _, err := futures[0].Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected wait to time out, got %v", err)
+	}
+	cancel()
+	close(release)
+	for i, want := range []int{2, 4, 2} {
+		if v, ok, err := futures[i].Wait(context.Background()); !ok || err != nil || v != want {
+			t.Fatalf("bad result %d: %v %v %v", i, v, ok, err)
+		}
+	}
+	if _, ok, err := failed.Wait(context.Background()); ok || err == nil {
+		t.Fatal("expected load error")
+	}
+	if n := atomic.LoadInt32(&loads); n != 3 {
+		t.Fatalf("expected concurrent loads to be shared, got %d", n)
+	}
+	// Cached values resolve immediately
+	select {
+	case <-c.GetAsync(1).Done():
+	default:
+		t.Fatal("cached value should resolve immediately")
+	}
+}
@@ -0,0 +1,184 @@
+package lruish
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LocalGroup gives each worker goroutine its own unsynchronized cache, in
+// front of a synchronized cache shared by all workers. Workers read and write
+// their local cache without taking any lock; only local misses go to the
+// shared cache. Values added locally are flushed to the shared cache in a
+// single batch on an interval, or once a worker has accumulated as many of
+// them as its cache holds.
+//
+// Local caches trade coherence for speed: values read from the shared cache
+// are kept locally until they expire after one interval, so a worker may not
+// see the values added by other workers for up to two intervals.
+type LocalGroup struct {
+	shared    Cache
+	localSize int
+	interval  time.Duration
+	clock     Clock
+
+	lock   sync.Mutex
+	locals map[*LocalCache]struct{}
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+// NewLocalGroup creates a group of local caches of localSize entries each, in
+// front of a shared cache of the given size configured through opts. Local
+// values are flushed to the shared cache every interval.
+func NewLocalGroup(size, localSize int, interval time.Duration, opts ...Option) (*LocalGroup, error) {
+	if localSize <= 0 {
+		return nil, errors.New("must provide a positive local size")
+	}
+	if interval <= 0 {
+		return nil, errors.New("must provide a positive flush interval")
+	}
+	shared, err := New(size, opts...)
+	if err != nil {
+		return nil, err
+	}
+	g := &LocalGroup{
+		shared:    shared,
+		localSize: localSize,
+		interval:  interval,
+		clock:     newConfig(opts).clock,
+		locals:    make(map[*LocalCache]struct{}),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go g.loop()
+	return g, nil
+}
+
+// loop asks the local caches to flush every interval. The flushes are done by
+// the workers themselves, on their next access, as only they may touch their
+// local caches.
+func (g *LocalGroup) loop() {
+	defer close(g.done)
+	for {
+		select {
+		case <-g.clock.After(g.interval):
+			g.lock.Lock()
+			for l := range g.locals {
+				l.flushDue.Store(true)
+			}
+			g.lock.Unlock()
+		case <-g.quit:
+			return
+		}
+	}
+}
+
+// Shared returns the cache shared by the workers of the group.
+func (g *LocalGroup) Shared() Cache {
+	return g.shared
+}
+
+// Local creates a local cache for a worker. The local cache must only be used
+// by one goroutine at a time, and be closed when the worker is done.
+func (g *LocalGroup) Local() *LocalCache {
+	lru, _ := newLruish(g.localSize, &config{clock: g.clock, expireAfterWrite: g.interval})
+	l := &LocalCache{
+		group: g,
+		lru:   lru,
+		dirty: make(map[interface{}]interface{}),
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.locals[l] = struct{}{}
+	return l
+}
+
+// Close stops flushing the local caches, and closes the shared cache. Local
+// caches must be closed first, so that their values are flushed.
+func (g *LocalGroup) Close() error {
+	select {
+	case <-g.quit:
+		return ErrClosed
+	default:
+	}
+	close(g.quit)
+	<-g.done
+	return g.shared.Close()
+}
+
+// LocalCache is the cache of a single worker of a LocalGroup. It is not safe
+// for concurrent use.
+type LocalCache struct {
+	group    *LocalGroup
+	lru      *lruish
+	dirty    map[interface{}]interface{} // Values not flushed to the shared cache yet
+	flushDue atomic.Bool
+}
+
+// Get looks up a key's value in the local cache, then in the shared cache.
+func (l *LocalCache) Get(key interface{}) (value interface{}, ok bool) {
+	l.maybeFlush()
+	if value, ok = l.lru.Get(key); ok {
+		return value, true
+	}
+	if value, ok = l.group.shared.Get(key); ok {
+		l.lru.Add(key, value)
+	}
+	return value, ok
+}
+
+// Add adds a value to the local cache. It reaches the shared cache on the
+// next flush.
+func (l *LocalCache) Add(key, value interface{}) {
+	l.maybeFlush()
+	l.lru.Add(key, value)
+	l.dirty[key] = value
+	if len(l.dirty) >= l.group.localSize {
+		l.Flush()
+	}
+}
+
+// Remove removes the key from the local cache and, right away, from the
+// shared cache. Returns whether the key was in either.
+func (l *LocalCache) Remove(key interface{}) bool {
+	_, dirty := l.dirty[key]
+	delete(l.dirty, key)
+	local := l.lru.Remove(key)
+	return l.group.shared.Remove(key) || local || dirty
+}
+
+// maybeFlush flushes the local values if the interval elapsed.
+func (l *LocalCache) maybeFlush() {
+	if l.flushDue.Load() {
+		l.Flush()
+	}
+}
+
+// Flush adds the values added locally since the last flush to the shared
+// cache, in a single batch.
+func (l *LocalCache) Flush() {
+	l.flushDue.Store(false)
+	if len(l.dirty) == 0 {
+		return
+	}
+	keys := make([]interface{}, 0, len(l.dirty))
+	values := make([]interface{}, 0, len(l.dirty))
+	for key, value := range l.dirty {
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	l.group.shared.AddMany(keys, values)
+	clear(l.dirty)
+}
+
+// Close flushes the local values and detaches the cache from its group.
+func (l *LocalCache) Close() {
+	l.Flush()
+	l.group.lock.Lock()
+	delete(l.group.locals, l)
+	l.group.lock.Unlock()
+	l.lru.Close()
+}
@@ -0,0 +1,93 @@
+package lruish
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLocalGroup(t *testing.T) {
+	clock := newFakeClock()
+	g, err := NewLocalGroup(100, 10, time.Second, WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	a, b := g.Local(), g.Local()
+	a.Add("x", 1)
+	if _, ok := g.Shared().Peek("x"); ok {
+		t.Fatal("x flushed early")
+	}
+	if v, ok := a.Get("x"); !ok || v != 1 {
+		t.Fatalf("have %v, want local 1", v)
+	}
+	// The next access after the interval flushes
+	deadline := time.Now().Add(5 * time.Second)
+	for !a.flushDue.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("flush not requested")
+		}
+		// The flushing goroutine may not be waiting on the clock yet
+		clock.Advance(time.Second)
+		time.Sleep(time.Millisecond)
+	}
+	a.Get("y")
+	if v, ok := b.Get("x"); !ok || v != 1 {
+		t.Errorf("have %v, want 1 from the shared cache", v)
+	}
+	// Removals go through right away
+	if !b.Remove("x") {
+		t.Error("x not removed")
+	}
+	if _, ok := g.Shared().Peek("x"); ok {
+		t.Error("x still shared")
+	}
+	a.Close()
+	b.Close()
+}
+
+func TestLocalGroupFlushWhenFull(t *testing.T) {
+	g, _ := NewLocalGroup(100, 4, time.Hour)
+	defer g.Close()
+
+	l := g.Local()
+	for i := 0; i < 4; i++ {
+		l.Add(i, i)
+	}
+	if n := g.Shared().Len(); n != 4 {
+		t.Errorf("have %d shared entries, want 4", n)
+	}
+	l.Add(4, 4)
+	l.Close()
+	if n := g.Shared().Len(); n != 5 {
+		t.Errorf("have %d shared entries after close, want 5", n)
+	}
+}
+
+func TestLocalGroupConcurrent(t *testing.T) {
+	g, _ := NewLocalGroup(1000, 16, time.Millisecond)
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			l := g.Local()
+			defer l.Close()
+			for i := 0; i < 1000; i++ {
+				l.Add(w*1000+i%100, i)
+				l.Get((w+1)%4*1000 + i%100)
+			}
+		}(w)
+	}
+	wg.Wait()
+	if n := g.Shared().Len(); n != 400 {
+		t.Errorf("have %d shared entries, want 400", n)
+	}
+	if err := g.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Close(); err != ErrClosed {
+		t.Errorf("have %v, want ErrClosed", err)
+	}
+}
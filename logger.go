@@ -0,0 +1,58 @@
+package lruish
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogLevels sets the levels at which the events of the cache are logged. Nil
+// levels default to slog.LevelDebug, except for Leak which defaults to
+// slog.LevelWarn.
+type LogLevels struct {
+	Evict   slog.Leveler // Entries evicted for capacity, or dropped on expiry
+	Resize  slog.Leveler // Capacity changes through Resize
+	Purge   slog.Leveler // Purge and PurgeNamespace
+	Compact slog.Leveler // Compaction of the ring, sweeping out the holes
+	Leak    slog.Leveler // Closers left unclosed, as found by WithLeakDetection
+}
+
+// WithLogger makes the cache log evictions, resizes, purges and compactions
+// to the given logger, at the levels set by WithLogLevels. Explicit removals
+// are not logged. In synchronized caches the logger is invoked while the cache
+// lock is held.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = l
+	}
+}
+
+// WithLogLevels sets the levels at which WithLogger logs each kind of event.
+func WithLogLevels(levels LogLevels) Option {
+	return func(c *config) {
+		c.logLevels = levels
+	}
+}
+
+// log logs an event at the given level, if the cache has a logger.
+func (c *lruish) log(level slog.Leveler, msg string, args ...interface{}) {
+	if c.logger == nil {
+		return
+	}
+	lvl := slog.LevelDebug
+	if level != nil {
+		lvl = level.Level()
+	}
+	if !c.logger.Enabled(context.Background(), lvl) {
+		return
+	}
+	c.logger.Log(context.Background(), lvl, msg, args...)
+}
+
+// logEviction logs an entry evicted for capacity, or dropped on expiry.
+func (c *lruish) logEviction(key interface{}, reason EvictionReason) {
+	if k, ok := key.(nsKey); ok {
+		c.log(c.logLevels.Evict, "lruish: evicted", "namespace", k.ns, "key", k.key, "reason", reason)
+		return
+	}
+	c.log(c.logLevels.Evict, "lruish: evicted", "key", key, "reason", reason)
+}
@@ -0,0 +1,59 @@
+package lruish
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c, _ := New(2, WithLogger(logger))
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Add("c", 3) // Evicts a
+	c.Remove("b") // Not logged
+	c.Compact()
+	c.Resize(4)
+	c.Namespace("ns").Add("d", 4)
+	c.PurgeNamespace("ns")
+	c.Purge()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		`level=DEBUG msg="lruish: evicted" key=a reason=capacity`,
+		`level=DEBUG msg="lruish: compacted" entries=1`,
+		`level=DEBUG msg="lruish: compacted"`,
+		`level=DEBUG msg="lruish: resized" from=2 to=4 evicted=0`,
+		`level=DEBUG msg="lruish: purged namespace" namespace=ns entries=1`,
+		`level=DEBUG msg="lruish: purged" entries=1`,
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("have %d lines, want %d:\n%s", len(lines), len(want), buf.String())
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, want[i]) {
+			t.Errorf("line %d: have %q, want %q", i, line, want[i])
+		}
+	}
+}
+
+func TestLogLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil)) // Info and above
+	clock := newFakeClock()
+	c, _ := New(2, WithLogger(logger), WithClock(clock), WithExpireAfterWrite(time.Second),
+		WithLogLevels(LogLevels{Evict: slog.LevelWarn}))
+	c.Add("a", 1)
+	c.Purge() // Debug, not logged
+	c.Add("b", 2)
+	clock.Advance(time.Second)
+	c.Get("b")
+
+	if have, want := strings.TrimSpace(buf.String()), `level=WARN msg="lruish: evicted" key=b reason=expired`; !strings.Contains(have, want) || strings.Contains(have, "purged") {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
@@ -1,64 +1,241 @@
 package lruish
 
 import (
+	"bytes"
 	"errors"
+	"io"
+	"iter"
+	"log/slog"
+	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// Cache is the interface of the caches created by New and NewUnsynched. Once
+// closed, a cache behaves as an empty cache which cannot be added to: lookups
+// miss, and modifications do nothing and report that nothing changed. Only
+// TryAdd and Close, which return an error, report ErrClosed.
 type Cache interface {
 	Add(key, value interface{}) bool
+	TryAdd(key, value interface{}) error
 	Get(key interface{}) (value interface{}, ok bool)
+	GetStale(key interface{}) (value interface{}, stale, ok bool)
+	GetWithExpiry(key interface{}) (value interface{}, expiresAt time.Time, ok bool)
+	GetEarlyRefresh(key interface{}) (value interface{}, shouldRefresh, ok bool)
 	Contains(key interface{}) bool
+	ContainsMany(keys []interface{}) []bool
 	Peek(key interface{}) (value interface{}, ok bool)
+	PeekWithRank(key interface{}) (value interface{}, rank int, ok bool)
 	ContainsOrAdd(key, value interface{}) (ok, evicted bool)
 	Remove(key interface{}) bool
+	GetAndRemove(key interface{}) (value interface{}, ok bool)
+	Swap(key, value interface{}) (previous interface{}, loaded bool)
+	AddWithPriority(key, value interface{}, priority Priority) bool
+	AddWithCost(key, value interface{}, cost float64) bool
+	AddWithRecompute(key, value interface{}, took time.Duration) bool
+	AddVersioned(key, value, version interface{}) bool
+	AddTagged(key, value interface{}, tags ...string) bool
+	InvalidateTag(tag string) int
+	GetByIndex(name, indexKey string) []Entry
+	KeysOf(value interface{}) []interface{}
+	AddAlias(aliasKey, canonicalKey interface{}) bool
+	Touch(key interface{}) bool
+	Demote(key interface{}) bool
+	Pin(key interface{}) bool
+	Unpin(key interface{}) bool
+	CompareAndSwap(key, old, new interface{}) (swapped bool)
+	CompareAndDelete(key, old interface{}) (deleted bool)
+	AddMany(keys, values []interface{}) (evicted []bool)
+	Warm(entries []Entry)
+	WarmFrom(seq iter.Seq2[interface{}, interface{}])
+	GetMany(keys []interface{}) (values []interface{}, ok []bool)
+	RemoveMany(keys []interface{}) (removed []bool)
+	RemoveFunc(pred func(key, value interface{}) bool) int
+	RemovePrefix(prefix string) int
 	Keys() []interface{}
+	Range(fn func(key, value interface{}) bool)
+	All() iter.Seq2[interface{}, interface{}]
+	AllKeys() iter.Seq[interface{}]
+	AllValues() iter.Seq[interface{}]
 	Len() int
+	Cap() int
+	Resize(size int) (evicted int)
+	Utilization() Utilization
+	SizeBytes() int64
+	AllocStats() AllocStats
+	Stats() Stats
+	HotKeys(n int) []HotKey
+	AccessCount(key interface{}) (uint64, bool)
+	Entries() []EntryInfo
+	EvictionCandidates(n int) []interface{}
+	Sample() []KeySample
+	Compact()
+	Namespace(prefix string) Cache
+	Purge()
+	PurgeNamespace(prefix string) int
+	SetNamespaceQuota(prefix string, fraction float64)
+	NamespaceStats(prefix string) NamespaceStats
+	Clone() Cache
+	Freeze()
+	Merge(other Cache, conflict func(a, b interface{}) interface{})
+	DebugDump(w io.Writer)
+	Events() <-chan Event
+	DroppedEvents() uint64
+	Close() error
 }
 
 // SynchedLRU is a thread-safe fixed size LRU cache.
 type SynchedLRU struct {
-	lru  Cache
-	lock sync.RWMutex
+	lru   *lruish
+	lock  sync.RWMutex
+	reads *readBuffer // Buffered promotions, if enabled
+
+	snapshot     atomic.Pointer[map[interface{}]interface{}] // Index for lock-free reads, if enabled
+	snapshotQuit chan struct{}
+
+	refreshLoader func(key interface{}) (interface{}, error)
+
+	frozen atomic.Bool // Whether reads are served from the snapshot, set by Freeze
+
+	invalidator Invalidator // Replicas to keep coherent with, if any
+	unsubscribe func()
+}
+
+// New creates an multi-thread safe LRU cache of the given size, with optional
+// features configured through opts.
+func New(size int, opts ...Option) (Cache, error) {
+	cfg := newConfig(opts)
+	if cfg.stripes > 1 {
+		return newStriped(size, cfg)
+	}
+	return newSynched(size, cfg)
 }
 
-// NewSynched creates an multi-thread safe LRU cache of the given size.
-func NewSynched(size int) (Cache, error) {
-	lru, err := NewUnsyched(size)
+func newSynched(size int, cfg *config) (*SynchedLRU, error) {
+	lru, err := newLruish(size, cfg)
 	if err != nil {
 		return nil, err
 	}
 	c := &SynchedLRU{
-		lru: lru,
+		lru:           lru,
+		refreshLoader: cfg.refreshLoader,
+	}
+	if cfg.refreshLoader != nil {
+		lru.refresh = c.refresh
+	}
+	if cfg.readBuffer > 0 && !lru.timed() {
+		c.reads = newReadBuffer(cfg.readBuffer)
+	}
+	if c.unsubscribe, err = subscribe(cfg.invalidator, c.invalidated); err != nil {
+		return nil, err
+	}
+	c.invalidator = cfg.invalidator
+	if cfg.snapshotInterval > 0 {
+		c.startSnapshots(cfg.snapshotInterval)
 	}
 	return c, nil
 }
 
+// NewSynched creates an multi-thread safe LRU cache of the given size. It is
+// equivalent to New.
+func NewSynched(size int, opts ...Option) (Cache, error) {
+	return New(size, opts...)
+}
+
+// NewSynchedWithEvict creates an multi-thread safe LRU cache of the given size,
+// which calls onEvicted whenever an entry leaves the cache.
+//
+// Deprecated: use New with the WithOnEvict option.
+func NewSynchedWithEvict(size int, onEvicted func(key, value interface{}), opts ...Option) (Cache, error) {
+	return New(size, append([]Option{WithOnEvict(onEvicted)}, opts...)...)
+}
+
 // Add adds a value to the cache.  Returns true if an eviction occurred.
 func (c *SynchedLRU) Add(key, value interface{}) bool {
 	c.lock.Lock()
-	defer c.lock.Unlock()
+	defer c.unlock()
 	return c.lru.Add(key, value)
 }
 
 // Get looks up a key's value from the cache.
 func (c *SynchedLRU) Get(key interface{}) (value interface{}, ok bool) {
+	if c.filteredOut(key) {
+		atomic.AddUint64(&c.lru.stats.Misses, 1)
+		return nil, false
+	}
+	if c.frozen.Load() {
+		return c.getFrozen(key)
+	}
+	if c.reads != nil {
+		if _, isNs := key.(nsKey); !isNs {
+			return c.getBuffered(key)
+		}
+	}
 	c.lock.Lock()
-	defer c.lock.Unlock()
+	defer c.unlock()
 	return c.lru.Get(key)
 }
 
+// GetStale looks up a key's value from the cache like Get, but also returns
+// values which have expired, flagged as stale.
+func (c *SynchedLRU) GetStale(key interface{}) (value interface{}, stale, ok bool) {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.GetStale(key)
+}
+
+// GetWithExpiry looks up a key's value from the cache like Get, and also
+// returns when the entry will expire.
+func (c *SynchedLRU) GetWithExpiry(key interface{}) (value interface{}, expiresAt time.Time, ok bool) {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.GetWithExpiry(key)
+}
+
 // Contains checks if a key is in the cache, without updating the
 // recent-ness or deleting it for being stale.
 func (c *SynchedLRU) Contains(key interface{}) bool {
+	if snap := c.snapshot.Load(); snap != nil {
+		_, ok := (*snap)[key]
+		return ok
+	}
+	if c.filteredOut(key) {
+		return false
+	}
 	c.lock.RLock()
 	defer c.lock.RUnlock()
 	return c.lru.Contains(key)
 }
 
+// ContainsMany checks whether each of the keys is in the cache, without
+// updating their recent-ness, under a single acquisition of the read lock.
+func (c *SynchedLRU) ContainsMany(keys []interface{}) []bool {
+	if snap := c.snapshot.Load(); snap != nil {
+		ok := make([]bool, len(keys))
+		for i, key := range keys {
+			_, ok[i] = (*snap)[key]
+		}
+		return ok
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.ContainsMany(keys)
+}
+
 // Peek returns the key value (or undefined if not found) without updating
 // the "recently used"-ness of the key.
 func (c *SynchedLRU) Peek(key interface{}) (value interface{}, ok bool) {
+	if snap := c.snapshot.Load(); snap != nil {
+		value, ok := (*snap)[key]
+		if !ok {
+			return nil, false
+		}
+		return c.lru.unpackLive(value)
+	}
+	if c.filteredOut(key) {
+		return nil, false
+	}
 	c.lock.RLock()
 	defer c.lock.RUnlock()
 	return c.lru.Peek(key)
@@ -69,6 +246,15 @@ func (c *SynchedLRU) Keys() []interface{} {
 	return c.lru.Keys()
 }
 
+// Range calls fn for each entry in the cache, most recently used first,
+// without updating their recent-ness. Iteration stops if fn returns false.
+// The read lock is held during the iteration, so fn must not modify the cache.
+func (c *SynchedLRU) Range(fn func(key, value interface{}) bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	c.lru.Range(fn)
+}
+
 // Len returns the number of items in the cache.
 func (c *SynchedLRU) Len() int {
 	c.lock.RLock()
@@ -81,29 +267,190 @@ func (c *SynchedLRU) Len() int {
 // Returns whether found and whether an eviction occurred.
 func (c *SynchedLRU) ContainsOrAdd(key, value interface{}) (ok, evicted bool) {
 	c.lock.Lock()
-	defer c.lock.Unlock()
-	return c.ContainsOrAdd(key, value)
+	defer c.unlock()
+	return c.lru.ContainsOrAdd(key, value)
 }
 
 // Remove removes the provided key from the cache.
 func (c *SynchedLRU) Remove(key interface{}) bool {
 	c.lock.Lock()
-	defer c.lock.Unlock()
-	return c.lru.Remove(key)
+	removed := c.lru.Remove(key)
+	c.unlock()
+	c.publish(invalidation(key))
+	return removed
+}
+
+// AddMany adds the values to the cache under the given keys, which must be
+// of equal length. Returns whether each addition caused an eviction.
+func (c *SynchedLRU) AddMany(keys, values []interface{}) []bool {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.AddMany(keys, values)
+}
+
+// GetMany looks up the values of several keys from the cache.
+func (c *SynchedLRU) GetMany(keys []interface{}) ([]interface{}, []bool) {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.GetMany(keys)
+}
+
+// RemoveMany removes the provided keys from the cache, returning whether each
+// key was contained.
+func (c *SynchedLRU) RemoveMany(keys []interface{}) []bool {
+	c.lock.Lock()
+	removed := c.lru.RemoveMany(keys)
+	c.unlock()
+	c.publishKeys(keys)
+	return removed
+}
+
+// GetAndRemove removes the provided key from the cache, returning its value
+// and whether it was contained.
+func (c *SynchedLRU) GetAndRemove(key interface{}) (value interface{}, ok bool) {
+	c.lock.Lock()
+	value, ok = c.lru.GetAndRemove(key)
+	c.unlock()
+	c.publish(invalidation(key))
+	return value, ok
+}
+
+// Pin protects the entry for key from being evicted until it is unpinned.
+// Returns false if the key is not in the cache. If every entry is pinned,
+// new entries are not added to the cache.
+func (c *SynchedLRU) Pin(key interface{}) bool {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.Pin(key)
+}
+
+// Unpin makes the entry for key evictable again. Returns false if the key
+// is not in the cache.
+func (c *SynchedLRU) Unpin(key interface{}) bool {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.Unpin(key)
+}
+
+// Swap stores value for key and returns the previous value, if any. The
+// loaded result reports whether the key was present.
+func (c *SynchedLRU) Swap(key, value interface{}) (previous interface{}, loaded bool) {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.Swap(key, value)
+}
 
+// CompareAndSwap swaps the old and new values for key if the value stored
+// in the cache is equal to old. Byte slices are compared by content, other
+// values must be of a comparable type. Under WithCodec, values are compared
+// with reflect.DeepEqual instead.
+func (c *SynchedLRU) CompareAndSwap(key, old, new interface{}) bool {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.CompareAndSwap(key, old, new)
+}
+
+// CompareAndDelete deletes the entry for key if its value is equal to old.
+// Byte slices are compared by content, other values must be of a comparable
+// type. Under WithCodec, values are compared with reflect.DeepEqual instead.
+func (c *SynchedLRU) CompareAndDelete(key, old interface{}) bool {
+	c.lock.Lock()
+	deleted := c.lru.CompareAndDelete(key, old)
+	c.unlock()
+	if deleted {
+		c.publish(invalidation(key))
+	}
+	return deleted
 }
 
 // NewUnsynched creates an non-multi-thread safe LRU cache of the given size.
-func NewUnsynched(size int) (Cache, error) {
+func NewUnsynched(size int, opts ...Option) (Cache, error) {
+	cfg := newConfig(opts)
+	if cfg.refreshLoader != nil {
+		return nil, errors.New("refreshing requires a synchronized cache")
+	}
+	if cfg.stripes > 1 {
+		return nil, errors.New("striping requires a synchronized cache")
+	}
+	if cfg.invalidator != nil {
+		return nil, errInvalidatorUnsynched
+	}
+	c, err := newLruish(size, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewUnsynchedWithEvict creates an non-multi-thread safe LRU cache of the given
+// size, which calls onEvicted whenever an entry leaves the cache.
+//
+// Deprecated: use NewUnsynched with the WithOnEvict option.
+func NewUnsynchedWithEvict(size int, onEvicted func(key, value interface{}), opts ...Option) (Cache, error) {
+	return NewUnsynched(size, append([]Option{WithOnEvict(onEvicted)}, opts...)...)
+}
+
+func newLruish(size int, cfg *config) (*lruish, error) {
 	if size <= 0 {
 		return nil, errors.New("must provide a positive size")
 	}
-
 	c := &lruish{
-		size:  size,
-		head:  0,
-		items: make(map[interface{}]*lruElem),
-		ring:  make([]*lruElem, size),
+		size:              size,
+		head:              0,
+		items:             make(map[interface{}]*lruElem),
+		ring:              make([]*lruElem, size),
+		clock:             cfg.clock,
+		expireAfterWrite:  cfg.expireAfterWrite,
+		expireAfterAccess: cfg.expireAfterAccess,
+		ttlJitter:         cfg.ttlJitter,
+		earlyRefreshBeta:  cfg.earlyRefreshBeta,
+		refreshAfter:      cfg.refreshAfter,
+		purgeOnClose:      cfg.purgeOnClose,
+		promotion:         cfg.promotion,
+		costWindow:        cfg.costWindow,
+		noEviction:        cfg.noEviction,
+		onPressure:        cfg.onPressure,
+		compressor:        cfg.compressor,
+		weakValues:        cfg.weakValues,
+		copyValues:        cfg.copyValues,
+		codec:             cfg.codec,
+		compressAbove:     cfg.compressAbove,
+		versionCompare:    cfg.versionCompare,
+		secondaries:       newSecondaryIndexes(cfg.indexes),
+		logger:            cfg.logger,
+		logLevels:         cfg.logLevels,
+	}
+	if cfg.doorkeeperWindow > 0 {
+		c.doorkeeper = &doorkeeper{window: int64(cfg.doorkeeperWindow)}
+	}
+	if cfg.bloomBits > 0 {
+		c.bloom = &bloomFront{bitsPerKey: cfg.bloomBits}
+		c.rebuildBloom()
+	}
+	if cfg.leakWindow > 0 && !cfg.closeOnEvict {
+		c.leaks = &leakDetector{window: int64(cfg.leakWindow)}
+	}
+	if cfg.trackValueKeys {
+		c.valueKeys = newValueKeyMap()
+	}
+	if cfg.onPressure != nil {
+		c.watermark = max(1, int(cfg.watermark*float64(size)))
+	}
+	c.onEvict = cfg.evictCallback(&c.background)
+	if cfg.eventBuffer > 0 {
+		c.events = make(chan Event, cfg.eventBuffer)
+	}
+	if cfg.ghosts > 0 {
+		c.ghosts = newGhostList(cfg.ghosts)
+	}
+	if cfg.hotKeys > 0 {
+		c.hot = newSpaceSaving(cfg.hotKeys)
+	}
+	if cfg.sampleCapacity > 0 {
+		c.sampler = newSampler(cfg.sampleEvery, cfg.sampleCapacity)
+	}
+	if c.onEvict != nil && cfg.evictQueue > 0 {
+		c.dispatchEvictions(cfg.evictQueue)
 	}
 	return c, nil
 }
@@ -113,13 +460,94 @@ type lruElem struct {
 	value interface{}
 	key   interface{}
 	index int
+	// Pinned elements are never evicted
+	pinned bool
+	// Last write and access time in unix nanoseconds, only tracked if
+	// the cache has a time-to-live or refresh configured.
+	written  int64
+	accessed int64
+	// Factor the TTLs of this entry are scaled by, if jittered
+	ttlScale float64
+	// Whether a background refresh of the value is in flight
+	refreshing bool
+	// Tags the entry can be invalidated by
+	tags []string
+	// Entries are evicted from lower priority bands first
+	priority Priority
+	// Recomputation cost, and the GreedyDual credit derived from it
+	cost   float64
+	credit float64
+	// Time it took to compute the value in nanoseconds, if recorded
+	recompute int64
+	// Number of successful Gets, updated atomically
+	hits uint64
+	// Version of the value, if added with AddVersioned
+	version interface{}
+	// Keys of the value in the secondary indexes, empty if not indexed
+	indexKeys []string
 }
 
 type lruish struct {
-	size  int
-	items map[interface{}]*lruElem
-	head  int
-	ring  []*lruElem
+	size      int
+	items     map[interface{}]*lruElem
+	head      int
+	ring      []*lruElem
+	onEvict   func(key, value interface{}, reason EvictionReason)
+	promotion Promotion // nil means PromoteHalfway
+
+	clock             Clock
+	expireAfterWrite  time.Duration
+	expireAfterAccess time.Duration
+	ttlJitter         float64 // Maximum relative deviation of entry TTLs
+	earlyRefreshBeta  float64 // Aggressiveness of GetEarlyRefresh, 1 if zero
+
+	refreshAfter time.Duration
+	refresh      func(ent *lruElem) // Starts reloading a stale entry
+
+	tags        map[string]map[*lruElem]struct{} // Tagged entries, by tag
+	secondaries []*secondaryIndex                // Indexes registered with WithIndex
+	valueKeys   *valueKeyMap                     // Entries by value identity, if tracked
+	aliases     *aliasMap                        // Entries by alias key, once aliases are added
+	onUnalias   func(alias interface{})          // Called when an alias is dropped, if set
+	namespaces  map[string]*NamespaceStats       // Quotas and stats, by namespace
+	bands       [numPriorities]int               // Number of entries, by priority
+
+	noEviction bool // Reject additions instead of evicting
+
+	watermark  int                // Occupancy at which onPressure is invoked
+	onPressure func(len, cap int) // Callback for crossing the watermark
+	pressured  bool               // Whether the watermark has been crossed
+
+	compressor    Compressor // Codec for large []byte values, if any
+	weakValues    bool       // Whether pointer values are held weakly
+	copyValues    bool       // Whether []byte values are copied in and out
+	codec         Codec      // Serializer of values, if any
+	compressAbove int        // Size above which []byte values are compressed
+
+	versionCompare func(a, b interface{}) int // Comparator of AddVersioned, if set
+
+	costWindow int     // Number of tail entries considered for cost-based eviction
+	inflation  float64 // GreedyDual credit of the last evicted entry
+
+	allocs  AllocStats    // Allocated and reused elements
+	stats   Stats         // Lookup and eviction counters, updated atomically
+	ghosts  *ghostList    // Recently evicted keys, if tracked
+	hot     *spaceSaving  // Most frequently accessed keys, if tracked
+	sampler *sampler      // Sampled accesses, if enabled
+	leaks   *leakDetector // Closers which left the cache, if detecting leaks
+	bloom   *bloomFront   // Filter of the cached keys, if enabled
+
+	doorkeeper *doorkeeper // Keys turned away recently, if enabled
+
+	evictions    chan evictEvent // Queue of the eviction callback worker, if any
+	events       chan Event      // Event stream, if enabled
+	dropped      uint64          // Events dropped because the stream was full
+	logger       *slog.Logger    // Logger of evictions and maintenance, if any
+	logLevels    LogLevels
+	background   sync.WaitGroup // Tracks goroutines spawned by the cache
+	closed       bool
+	frozen       bool // Whether modifications are rejected, set by Freeze
+	purgeOnClose bool
 }
 
 // ContainsOrAdd checks if a key is in the cache  without updating the
@@ -135,15 +563,32 @@ func (c *lruish) ContainsOrAdd(key, value interface{}) (ok, evicted bool) {
 
 // Keys returns the keys, unordered
 func (c *lruish) Keys() []interface{} {
-	keys := make([]interface{}, len(c.items))
-	i := 0
-	for k := range c.items {
-		keys[i] = k
-		i++
+	keys := make([]interface{}, 0, len(c.items))
+	for k, ent := range c.items {
+		if !c.expired(ent) {
+			keys = append(keys, k)
+		}
 	}
 	return keys
 }
 
+// Range calls fn for each entry in the cache in ring order, which starts with
+// the most recently used entry, without updating their recent-ness. Iteration
+// stops if fn returns false.
+func (c *lruish) Range(fn func(key, value interface{}) bool) {
+	for i := 0; i < len(c.ring); i++ {
+		ent := c.ring[(c.head+i)%c.size]
+		if ent == nil || c.expired(ent) {
+			continue
+		}
+		if !fn(ent.key, c.unpack(ent.value)) {
+			return
+		}
+	}
+}
+
+// Len returns the number of items in the cache. Expired items which have not
+// been removed yet are included in the count.
 func (c *lruish) Len() int {
 	return len(c.items)
 }
@@ -156,85 +601,464 @@ func (c *lruish) promote(ent *lruElem) {
 		position += c.size
 	}
 	// Calculate new index to place this item at
-	newIndex := (c.head + position/2) % c.size
+	newPosition := position / 2
+	if c.promotion != nil {
+		newPosition = min(max(c.promotion(position), 0), position)
+	}
+	newIndex := (c.head + newPosition) % c.size
 	// Update the downgraded item, if non-nil (could be a hole in the ring)
 	if c.ring[newIndex] != nil {
 		c.ring[newIndex].index = curIndex
 	}
 	// Update the promoted item
 	ent.index = newIndex
+	ent.credit = c.inflation + ent.cost
 	// Swap them
 	c.ring[curIndex], c.ring[newIndex] = c.ring[newIndex], c.ring[curIndex]
 }
 
+// lookup returns the entry for key, unless it has expired.
+func (c *lruish) lookup(key interface{}) (*lruElem, bool) {
+	ent, ok := c.entry(key)
+	if !ok || c.expired(ent) {
+		return nil, false
+	}
+	return ent, true
+}
+
+// get returns the entry for key, dropping it from the cache if it has expired.
+// The entries of frozen caches are never returned, as they must not change.
+func (c *lruish) get(key interface{}) (*lruElem, bool) {
+	ent, ok := c.entry(key)
+	if !ok || c.frozen {
+		return nil, false
+	}
+	if c.expired(ent) {
+		reason := ReasonExpired
+		if c.weakValues && c.collected(ent) {
+			reason = ReasonCollected
+		}
+		c.removeElem(ent, reason)
+		return nil, false
+	}
+	return ent, true
+}
+
 func (c *lruish) Get(key interface{}) (interface{}, bool) {
-	if ent, ok := c.items[key]; ok {
+	if c.frozen {
+		return c.getFrozen(key)
+	}
+	ent, ok := c.get(key)
+	var value interface{}
+	if ok {
+		if value, ok = c.unpackLive(ent.value); !ok {
+			// Reclaimed since the lookup
+			c.removeElem(ent, ReasonCollected)
+		}
+	}
+	if k, isNs := key.(nsKey); isNs {
+		c.countLookup(k.ns, ok)
+	}
+	c.recordLookup(key, ok)
+	if ok {
 		c.promote(ent)
-		return ent.value, true
+		c.touch(ent, false)
+		c.maybeRefresh(ent)
+		atomic.AddUint64(&ent.hits, 1)
+		c.emit(EventHit, key, value, 0)
+		return value, true
 	}
+	c.emit(EventMiss, key, nil, 0)
 	return nil, false
 }
 
 // Add adds a value to the cache.  Returns true if an eviction occurred.
 func (c *lruish) Add(key, value interface{}) bool {
+	return c.add(key, value, PriorityNormal)
+}
+
+// add adds a value to the cache, with the given priority if the key is new.
+// Returns true if an eviction occurred.
+func (c *lruish) add(key, value interface{}, priority Priority) bool {
+	if c.frozen {
+		return false
+	}
+	c.recordAccess(key)
 	// Check for existing item
-	if ent, ok := c.items[key]; ok {
+	if ent, ok := c.get(key); ok {
 		c.promote(ent)
-		ent.value = value
+		c.touch(ent, true)
+		c.replace(ent, value)
+		c.emit(EventAdd, key, value, 0)
+		return false
+	}
+	if c.closed {
 		return false
 	}
 	// Add a new item
-	// new head position is h-1
-	c.head--
-	if c.head < 0 {
-		c.head += c.size
+	// new head position is h-1, which is where the current tail lives
+	head := c.head - 1
+	if head < 0 {
+		head += c.size
 	}
-	// new tail position is h -2
-	tailIndex := c.head - 1
-	if tailIndex < 0 {
-		tailIndex += c.size
+	if c.ring[head] != nil && len(c.items) == c.size && priority < c.lowestPriority() {
+		// The new item would be the first to go
+		return false
 	}
-	if toDelete := c.ring[tailIndex]; toDelete != nil {
-		delete(c.items, toDelete.key)
+	if c.doorkeeper != nil && c.ring[head] != nil && !c.admit(key) {
+		return false
 	}
-	ent := &lruElem{value: value, key: key, index: c.head}
+	if c.noEviction && c.ring[head] != nil {
+		if len(c.items) == c.size {
+			return false
+		}
+		// There are holes elsewhere, move them to the tail
+		c.Compact()
+	}
+	evicted := false
+	k, isNs := key.(nsKey)
+	if isNs && c.overQuota(k.ns) {
+		// Make room among the namespace's own entries instead of the tail
+		evicted = c.evictFromNamespace(k.ns, head)
+	}
+	if c.costWindow > 1 && c.ring[head] != nil {
+		// Pick the cheapest entry near the tail as the victim
+		c.skipCostly(head)
+	}
+	if tail := c.ring[head]; tail != nil && tail.priority > c.lowestPriority() {
+		// Lower priority entries must go first
+		c.skipPriority(head)
+	}
+	if tail := c.ring[head]; tail != nil && tail.pinned && !c.skipPinned(head) {
+		// Everything is pinned, there's no room for the new item
+		return false
+	}
+	c.head = head
+	var ent *lruElem
+	if toDelete := c.ring[c.head]; toDelete != nil {
+		c.evictElem(toDelete, ReasonCapacity)
+		c.countEviction(toDelete)
+		c.inflation = toDelete.credit
+		evicted = true
+		// Reuse the evicted element, unless a refresh still refers to it
+		if !toDelete.refreshing {
+			ent = toDelete
+			c.allocs.Reused++
+		}
+	}
+	if ent == nil {
+		ent = new(lruElem)
+		c.allocs.Allocated++
+	}
+	*ent = lruElem{value: c.pack(value), key: key, index: c.head, priority: priority, cost: 1, credit: c.inflation + 1}
+	if c.ghosts != nil {
+		c.ghosts.remove(key)
+	}
+	c.touch(ent, true)
+	c.indexValue(ent, value)
+	c.trackValue(ent, value)
 	c.items[key] = ent
+	if c.bloom != nil {
+		c.bloomAdd(key)
+	}
 	c.ring[c.head] = ent
-	return true
+	c.bands[ent.priority.band()]++
+	if isNs {
+		c.namespaceStats(k.ns).Len++
+	}
+	c.emit(EventAdd, key, value, 0)
+	if c.watermark > 0 && !c.pressured && len(c.items) >= c.watermark {
+		c.pressured = true
+		c.onPressure(len(c.items), c.size)
+	}
+	return evicted
+}
+
+// skipPinned swaps the pinned entry at the given tail index with the oldest
+// unpinned entry (or hole), so that one gets evicted instead. Returns false
+// if there is no such entry.
+func (c *lruish) skipPinned(tail int) bool {
+	for i := 1; i < c.size; i++ {
+		index := tail - i
+		if index < 0 {
+			index += c.size
+		}
+		if ent := c.ring[index]; ent == nil || !ent.pinned {
+			if ent != nil {
+				ent.index = tail
+			}
+			c.ring[tail].index = index
+			c.ring[tail], c.ring[index] = c.ring[index], c.ring[tail]
+			return true
+		}
+	}
+	return false
+}
+
+// Pin protects the entry for key from being evicted until it is unpinned.
+// Returns false if the key is not in the cache. If every entry is pinned,
+// new entries are not added to the cache.
+func (c *lruish) Pin(key interface{}) bool {
+	if ent, ok := c.lookup(key); ok && !c.frozen {
+		ent.pinned = true
+		return true
+	}
+	return false
+}
+
+// Unpin makes the entry for key evictable again. Returns false if the key
+// is not in the cache.
+func (c *lruish) Unpin(key interface{}) bool {
+	if ent, ok := c.lookup(key); ok && !c.frozen {
+		ent.pinned = false
+		return true
+	}
+	return false
+}
+
+// Swap stores value for key and returns the previous value, if any. The
+// loaded result reports whether the key was present.
+func (c *lruish) Swap(key, value interface{}) (previous interface{}, loaded bool) {
+	if ent, ok := c.get(key); ok {
+		c.promote(ent)
+		c.touch(ent, true)
+		previous = c.unpack(ent.value)
+		c.replace(ent, value)
+		return previous, true
+	}
+	c.Add(key, value)
+	return nil, false
 }
 
 // Check if a key is in the cache, without updating the recent-ness
 // or deleting it for being stale.
 func (c *lruish) Contains(key interface{}) (ok bool) {
-	_, ok = c.items[key]
+	_, ok = c.lookup(key)
 	return ok
 }
 
 // Returns the key value (or undefined if not found) without updating
 // the "recently used"-ness of the key.
 func (c *lruish) Peek(key interface{}) (interface{}, bool) {
-	if ent, ok := c.items[key]; ok {
-		return ent.value, true
+	if ent, ok := c.lookup(key); ok {
+		return c.unpackLive(ent.value)
 	}
 	return nil, false
 }
 
 // Purge is used to completely clear the cache
 func (c *lruish) Purge() {
+	if c.frozen {
+		return
+	}
+	purged := len(c.items)
+	if c.onEvict != nil || c.leaks != nil {
+		for k, ent := range c.items {
+			value := c.unpack(ent.value)
+			if c.leaks != nil {
+				value = c.trackLeak(k, value)
+			}
+			if c.onEvict != nil {
+				c.onEvict(k, value, ReasonPurged)
+			}
+		}
+	}
 	c.items = make(map[interface{}]*lruElem)
 	c.ring = make([]*lruElem, c.size)
+	c.tags = nil
+	c.clearIndexes()
+	if c.valueKeys != nil {
+		c.valueKeys = newValueKeyMap()
+	}
+	c.dropAliases()
+	if c.bloom != nil {
+		c.rebuildBloom()
+	}
+	c.bands = [numPriorities]int{}
+	c.pressured = false
+	for _, stats := range c.namespaces {
+		stats.Len = 0
+	}
 	c.head = 0
+	c.emit(EventPurge, nil, nil, ReasonPurged)
+	c.log(c.logLevels.Purge, "lruish: purged", "entries", purged)
 }
 
 // Remove removes the provided key from the cache, returning if the
 // key was contained.
 func (c *lruish) Remove(key interface{}) bool {
-	if ent, ok := c.items[key]; ok {
-		delete(c.items, key)
-		// We'll leave a whole in the ring, but
-		// it will gradually be moved out
-		c.ring[ent.index] = nil
+	if ent, ok := c.get(key); ok {
+		c.removeElem(ent, ReasonRemoved)
 		return true
 	}
 	return false
 }
+
+func (c *lruish) removeElem(ent *lruElem, reason EvictionReason) {
+	// We'll leave a whole in the ring, but
+	// it will gradually be moved out
+	c.ring[ent.index] = nil
+	c.evictElem(ent, reason)
+}
+
+// evictElem drops the entry from the index and notifies the eviction
+// callback and event stream. The caller is responsible for the ring slot.
+func (c *lruish) evictElem(ent *lruElem, reason EvictionReason) {
+	delete(c.items, ent.key)
+	if c.pressured && len(c.items) < c.watermark {
+		c.pressured = false
+	}
+	c.bands[ent.priority.band()]--
+	if ent.tags != nil {
+		c.untag(ent)
+	}
+	if ent.indexKeys != nil {
+		c.unindexValue(ent)
+	}
+	if c.valueKeys != nil {
+		c.untrackValue(ent)
+	}
+	if c.aliases != nil {
+		c.unalias(ent)
+	}
+	if k, ok := ent.key.(nsKey); ok {
+		c.namespaces[k.ns].Len--
+	}
+	if reason == ReasonCapacity {
+		atomic.AddUint64(&c.stats.Evictions, 1)
+		if c.ghosts != nil {
+			c.ghosts.add(ent.key)
+		}
+	}
+	if c.logger != nil && (reason == ReasonCapacity || reason == ReasonExpired || reason == ReasonCollected) {
+		c.logEviction(ent.key, reason)
+	}
+	if c.onEvict == nil && c.events == nil && c.leaks == nil {
+		return
+	}
+	value := c.unpack(ent.value)
+	if c.leaks != nil {
+		value = c.trackLeak(ent.key, value)
+	}
+	if c.onEvict != nil {
+		c.onEvict(ent.key, value, reason)
+	}
+	if reason == ReasonCapacity {
+		c.emit(EventEvict, ent.key, value, reason)
+	} else {
+		c.emit(EventRemove, ent.key, value, reason)
+	}
+}
+
+// replace stores a new value in the entry, notifying the eviction callback
+// of the value it replaces.
+func (c *lruish) replace(ent *lruElem, value interface{}) {
+	if c.onEvict != nil {
+		c.onEvict(ent.key, c.unpack(ent.value), ReasonReplaced)
+	}
+	ent.value = c.pack(value)
+	if c.secondaries != nil {
+		c.unindexValue(ent)
+		c.indexValue(ent, value)
+	}
+	if c.valueKeys != nil {
+		c.untrackValue(ent)
+		c.trackValue(ent, value)
+	}
+}
+
+// AddMany adds the values to the cache under the given keys, which must be
+// of equal length. Returns whether each addition caused an eviction.
+func (c *lruish) AddMany(keys, values []interface{}) []bool {
+	if len(keys) != len(values) {
+		panic("lruish: keys and values differ in length")
+	}
+	evicted := make([]bool, len(keys))
+	for i, key := range keys {
+		evicted[i] = c.Add(key, values[i])
+	}
+	return evicted
+}
+
+// ContainsMany checks whether each of the keys is in the cache, without
+// updating their recent-ness.
+func (c *lruish) ContainsMany(keys []interface{}) []bool {
+	ok := make([]bool, len(keys))
+	for i, key := range keys {
+		ok[i] = c.Contains(key)
+	}
+	return ok
+}
+
+// GetMany looks up the values of several keys from the cache.
+func (c *lruish) GetMany(keys []interface{}) ([]interface{}, []bool) {
+	values := make([]interface{}, len(keys))
+	ok := make([]bool, len(keys))
+	for i, key := range keys {
+		values[i], ok[i] = c.Get(key)
+	}
+	return values, ok
+}
+
+// RemoveMany removes the provided keys from the cache, returning whether each
+// key was contained.
+func (c *lruish) RemoveMany(keys []interface{}) []bool {
+	removed := make([]bool, len(keys))
+	for i, key := range keys {
+		removed[i] = c.Remove(key)
+	}
+	return removed
+}
+
+// GetAndRemove removes the provided key from the cache, returning its value
+// and whether it was contained.
+func (c *lruish) GetAndRemove(key interface{}) (interface{}, bool) {
+	if ent, ok := c.get(key); ok {
+		c.removeElem(ent, ReasonRemoved)
+		return c.unpack(ent.value), true
+	}
+	return nil, false
+}
+
+// CompareAndSwap swaps the old and new values for key if the value stored
+// in the cache is equal to old. A successful swap promotes the entry, just
+// like an Add would.
+func (c *lruish) CompareAndSwap(key, old, new interface{}) bool {
+	ent, ok := c.get(key)
+	if !ok || !c.holds(ent, old) {
+		return false
+	}
+	c.promote(ent)
+	c.touch(ent, true)
+	c.replace(ent, new)
+	return true
+}
+
+// CompareAndDelete deletes the entry for key if its value is equal to old.
+func (c *lruish) CompareAndDelete(key, old interface{}) bool {
+	ent, ok := c.get(key)
+	if !ok || !c.holds(ent, old) {
+		return false
+	}
+	c.removeElem(ent, ReasonRemoved)
+	return true
+}
+
+// holds reports whether the value of the entry is equal to old. Byte slices
+// are compared by content, other values with ==. Values reclaimed by the
+// garbage collector equal nothing. Values stored by a codec are decoded into
+// copies, so they are compared deeply.
+func (c *lruish) holds(ent *lruElem, old interface{}) bool {
+	if v, ok := ent.value.(*encoded); ok {
+		return reflect.DeepEqual(c.decode(v), old)
+	}
+	value, live := c.unpackLive(ent.value)
+	if !live {
+		return false
+	}
+	if blob, ok := value.([]byte); ok {
+		oldBlob, ok := old.([]byte)
+		return ok && bytes.Equal(blob, oldBlob)
+	}
+	return value == old
+}
@@ -96,6 +96,171 @@ func BenchmarkLRU_Freq(b *testing.B) {
 	b.Logf("hit: %d miss: %d ratio: %f", hit, miss, float64(hit)/float64(miss))
 }
 
+func TestLRU(t *testing.T) {
+	evictCounter := 0
+	onEvicted := func(k interface{}, v interface{}) {
+		if k != v {
+			t.Fatalf("Evict values not equal (%v!=%v)", k, v)
+		}
+		evictCounter++
+	}
+	l, err := NewUnsynched(128, WithOnEvict(onEvicted))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 0; i < 256; i++ {
+		if evicted := l.Add(i, i); evicted != (i >= 128) {
+			t.Fatalf("add %d: unexpected eviction result %v", i, evicted)
+		}
+	}
+	if l.Len() != 128 {
+		t.Fatalf("bad len: %v", l.Len())
+	}
+	if evictCounter != 128 {
+		t.Fatalf("bad evict count: %v", evictCounter)
+	}
+}
+
+func TestSwap(t *testing.T) {
+	l, err := NewSynched(2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if prev, loaded := l.Swap(1, 1); loaded || prev != nil {
+		t.Errorf("unexpected previous value: %v, %v", prev, loaded)
+	}
+	if prev, loaded := l.Swap(1, 2); !loaded || prev != 1 {
+		t.Errorf("unexpected previous value: %v, %v", prev, loaded)
+	}
+	if v, _ := l.Peek(1); v != 2 {
+		t.Errorf("expected 2, got %v", v)
+	}
+}
+
+func TestGetAndRemove(t *testing.T) {
+	l, err := NewSynched(2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	if v, ok := l.GetAndRemove(1); !ok || v != 1 {
+		t.Errorf("1 should be set to 1: %v, %v", v, ok)
+	}
+	if l.Contains(1) {
+		t.Errorf("1 should have been removed")
+	}
+	if _, ok := l.GetAndRemove(1); ok {
+		t.Errorf("1 should not be contained")
+	}
+}
+
+func TestBatch(t *testing.T) {
+	l, err := NewSynched(2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	evicted := l.AddMany([]interface{}{1, 2, 3}, []interface{}{1, 2, 3})
+	if evicted[0] || evicted[1] || !evicted[2] {
+		t.Errorf("bad evictions: %v", evicted)
+	}
+	values, ok := l.GetMany([]interface{}{1, 2, 3})
+	if ok[0] || !ok[1] || !ok[2] || values[1] != 2 || values[2] != 3 {
+		t.Errorf("bad lookups: %v, %v", values, ok)
+	}
+	if ok := l.ContainsMany([]interface{}{1, 2, 3}); ok[0] || !ok[1] || !ok[2] {
+		t.Errorf("bad membership: %v", ok)
+	}
+	removed := l.RemoveMany([]interface{}{1, 2})
+	if removed[0] || !removed[1] {
+		t.Errorf("bad removals: %v", removed)
+	}
+	if l.Len() != 1 {
+		t.Errorf("bad len: %v", l.Len())
+	}
+}
+
+func TestPin(t *testing.T) {
+	l, err := NewSynched(2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	l.Add(2, 2)
+	if !l.Pin(1) {
+		t.Fatalf("1 should be pinnable")
+	}
+	l.Add(3, 3)
+	if !l.Contains(1) || l.Contains(2) {
+		t.Errorf("2 should have been evicted instead of 1")
+	}
+	l.Pin(3)
+	l.Add(4, 4)
+	if l.Contains(4) || l.Len() != 2 {
+		t.Errorf("4 should not have been added to a fully pinned cache")
+	}
+	l.Unpin(1)
+	l.Add(4, 4)
+	if l.Contains(1) || !l.Contains(3) || !l.Contains(4) {
+		t.Errorf("1 should have been evicted after unpinning")
+	}
+}
+
+func TestRange(t *testing.T) {
+	l, err := NewSynched(4)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		l.Add(i, i)
+	}
+	l.Remove(2)
+	var keys []interface{}
+	l.Range(func(k, v interface{}) bool {
+		keys = append(keys, k)
+		return len(keys) < 2
+	})
+	if len(keys) != 2 || keys[0] != 3 || keys[1] != 1 {
+		t.Errorf("bad iteration order: %v", keys)
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	l, err := NewSynched(2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	if l.CompareAndSwap(1, 2, 3) {
+		t.Errorf("swap should fail on mismatching old value")
+	}
+	if l.CompareAndSwap(2, nil, 3) {
+		t.Errorf("swap should fail on missing key")
+	}
+	if !l.CompareAndSwap(1, 1, 3) {
+		t.Errorf("swap should succeed")
+	}
+	if v, _ := l.Peek(1); v != 3 {
+		t.Errorf("expected 3, got %v", v)
+	}
+}
+
+func TestCompareAndDelete(t *testing.T) {
+	l, err := NewSynched(2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	if l.CompareAndDelete(1, 2) {
+		t.Errorf("delete should fail on mismatching old value")
+	}
+	if !l.CompareAndDelete(1, 1) {
+		t.Errorf("delete should succeed")
+	}
+	if l.Contains(1) {
+		t.Errorf("1 should have been deleted")
+	}
+}
+
 /*
 // test that Contains doesn't update recent-ness
 func TestLRUContains(t *testing.T) {
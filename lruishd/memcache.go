@@ -0,0 +1,206 @@
+// Package lruishd serves lruish caches over the network, so that processes
+// written in other languages can share a cache with Go code, or tools can
+// inspect the cache of a running process.
+package lruishd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/holiman/lruish"
+)
+
+// maxItemSize is the largest value accepted by set, as with memcached.
+const maxItemSize = 1 << 20
+
+// relativeExpiryLimit is the largest expiry time memcached treats as relative
+// to the current time, rather than as a unix timestamp.
+const relativeExpiryLimit = 60 * 60 * 24 * 30
+
+// item is a value stored through the memcached protocol.
+type item struct {
+	flags   uint32
+	data    []byte
+	expires time.Time // Zero if the item doesn't expire
+}
+
+func (it *item) expired(now time.Time) bool {
+	return !it.expires.IsZero() && !now.Before(it.expires)
+}
+
+// MemcacheServer serves a cache over the memcached text protocol. It supports
+// the get, gets, set, delete and quit commands, with string keys and []byte
+// values. Items are kept as stored in the cache, so that Go code sharing the
+// cache can read them too, through the Item function.
+type MemcacheServer struct {
+	server
+	cache lruish.Cache
+	clock lruish.Clock // Clock of the cache, which items expire by
+}
+
+// NewMemcacheServer creates a memcached server backed by a cache of the given
+// size, split into the given number of stripes so that concurrent clients
+// rarely contend. Further features of the cache are configured through opts.
+func NewMemcacheServer(size, stripes int, opts ...lruish.Option) (*MemcacheServer, error) {
+	cache, err := lruish.New(size, append(opts, lruish.WithStripes(stripes))...)
+	if err != nil {
+		return nil, err
+	}
+	s := &MemcacheServer{cache: cache, clock: lruish.ClockOf(cache)}
+	s.server.init(s.serveConn)
+	return s, nil
+}
+
+// Cache returns the cache backing the server.
+func (s *MemcacheServer) Cache() lruish.Cache {
+	return s.cache
+}
+
+// Item returns the flags and data of an item stored through the server, or
+// false if the value is missing, expired or wasn't stored by the server.
+func Item(cache lruish.Cache, key string) (flags uint32, data []byte, ok bool) {
+	v, ok := cache.Get(key)
+	if !ok {
+		return 0, nil, false
+	}
+	it, ok := v.(*item)
+	if !ok || it.expired(lruish.ClockOf(cache).Now()) {
+		return 0, nil, false
+	}
+	return it.flags, it.data, true
+}
+
+func (s *MemcacheServer) serveConn(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			w.WriteString("ERROR\r\n")
+		} else if quit, err := s.execute(fields, r, w); quit || err != nil {
+			w.Flush()
+			return
+		}
+		if r.Buffered() == 0 {
+			// Batch the replies to pipelined commands
+			if w.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+// execute runs a single command, returning whether the connection should be
+// closed. Errors are only returned if the connection is no longer usable.
+func (s *MemcacheServer) execute(fields []string, r *bufio.Reader, w *bufio.Writer) (quit bool, err error) {
+	switch cmd, args := fields[0], fields[1:]; cmd {
+	case "get", "gets":
+		s.get(args, cmd == "gets", w)
+	case "set":
+		return false, s.set(args, r, w)
+	case "delete":
+		s.delete(args, w)
+	case "quit":
+		return true, nil
+	default:
+		w.WriteString("ERROR\r\n")
+	}
+	return false, nil
+}
+
+func (s *MemcacheServer) get(keys []string, cas bool, w *bufio.Writer) {
+	if len(keys) == 0 {
+		w.WriteString("ERROR\r\n")
+		return
+	}
+	for _, key := range keys {
+		flags, data, ok := Item(s.cache, key)
+		if !ok {
+			continue
+		}
+		if cas {
+			// Compare-and-swap is not supported, so the unique value is a dummy
+			fmt.Fprintf(w, "VALUE %s %d %d 0\r\n", key, flags, len(data))
+		} else {
+			fmt.Fprintf(w, "VALUE %s %d %d\r\n", key, flags, len(data))
+		}
+		w.Write(data)
+		w.WriteString("\r\n")
+	}
+	w.WriteString("END\r\n")
+}
+
+// set stores an item: set <key> <flags> <exptime> <bytes> [noreply].
+func (s *MemcacheServer) set(args []string, r *bufio.Reader, w *bufio.Writer) error {
+	if len(args) != 4 && len(args) != 5 {
+		w.WriteString("ERROR\r\n")
+		return nil
+	}
+	noreply := len(args) == 5 && args[4] == "noreply"
+	flags, err1 := strconv.ParseUint(args[1], 10, 32)
+	exptime, err2 := strconv.ParseInt(args[2], 10, 64)
+	size, err3 := strconv.Atoi(args[3])
+	if err := errors.Join(err1, err2, err3); err != nil || size < 0 {
+		w.WriteString("CLIENT_ERROR bad command line format\r\n")
+		return nil
+	}
+	if size > maxItemSize {
+		// The data can't be skipped reliably, so give up on the connection
+		w.WriteString("SERVER_ERROR object too large for cache\r\n")
+		return errors.New("item too large")
+	}
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return err
+	}
+	if string(data[size:]) != "\r\n" {
+		w.WriteString("CLIENT_ERROR bad data chunk\r\n")
+		return nil
+	}
+	it := &item{flags: uint32(flags), data: data[:size]}
+	now := s.clock.Now()
+	switch {
+	case exptime < 0:
+		s.cache.Remove(args[0])
+	case exptime == 0:
+		s.cache.Add(args[0], it)
+	default:
+		if exptime <= relativeExpiryLimit {
+			it.expires = now.Add(time.Duration(exptime) * time.Second)
+		} else {
+			it.expires = time.Unix(exptime, 0)
+		}
+		s.cache.Add(args[0], it)
+	}
+	if !noreply {
+		w.WriteString("STORED\r\n")
+	}
+	return nil
+}
+
+// delete removes an item: delete <key> [noreply].
+func (s *MemcacheServer) delete(args []string, w *bufio.Writer) {
+	if len(args) != 1 && len(args) != 2 {
+		w.WriteString("ERROR\r\n")
+		return
+	}
+	removed := s.cache.Remove(args[0])
+	if len(args) == 2 && args[1] == "noreply" {
+		return
+	}
+	if removed {
+		w.WriteString("DELETED\r\n")
+	} else {
+		w.WriteString("NOT_FOUND\r\n")
+	}
+}
@@ -0,0 +1,92 @@
+package lruishd
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+// startMemcache serves a fresh cache on a local port, returning a client
+// connection to it.
+func startMemcache(t *testing.T) (*MemcacheServer, net.Conn) {
+	t.Helper()
+	s, err := NewMemcacheServer(64, 4)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go s.Serve(l)
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		s.Close()
+	})
+	return s, conn
+}
+
+func roundTrip(t *testing.T, conn net.Conn, r *bufio.Reader, request string, lines int) string {
+	t.Helper()
+	if _, err := conn.Write([]byte(request)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var reply strings.Builder
+	for i := 0; i < lines; i++ {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		reply.WriteString(line)
+	}
+	return reply.String()
+}
+
+func TestMemcacheProtocol(t *testing.T) {
+	s, conn := startMemcache(t)
+	r := bufio.NewReader(conn)
+
+	if reply := roundTrip(t, conn, r, "set foo 42 0 5\r\nhello\r\n", 1); reply != "STORED\r\n" {
+		t.Fatalf("bad set reply %q", reply)
+	}
+	if reply := roundTrip(t, conn, r, "get foo missing\r\n", 3); reply != "VALUE foo 42 5\r\nhello\r\nEND\r\n" {
+		t.Fatalf("bad get reply %q", reply)
+	}
+	if flags, data, ok := Item(s.Cache(), "foo"); !ok || flags != 42 || string(data) != "hello" {
+		t.Fatalf("item not visible through the cache: %v %q %v", flags, data, ok)
+	}
+	if reply := roundTrip(t, conn, r, "delete foo\r\ndelete foo\r\n", 2); reply != "DELETED\r\nNOT_FOUND\r\n" {
+		t.Fatalf("bad delete reply %q", reply)
+	}
+	if reply := roundTrip(t, conn, r, "get foo\r\n", 1); reply != "END\r\n" {
+		t.Fatalf("deleted item should be gone, got %q", reply)
+	}
+	// Items with an expiry time in the past are never served
+	if reply := roundTrip(t, conn, r, "set old 0 1000000000 1\r\nx\r\nget old\r\n", 2); reply != "STORED\r\nEND\r\n" {
+		t.Fatalf("expired item should not be served, got %q", reply)
+	}
+	if reply := roundTrip(t, conn, r, "set quiet 0 0 1 noreply\r\nx\r\nbogus\r\n", 1); reply != "ERROR\r\n" {
+		t.Fatalf("expected only the error reply, got %q", reply)
+	}
+	if reply := roundTrip(t, conn, r, "set bad 0 0 1\r\nxyz\r\n", 1); !strings.HasPrefix(reply, "CLIENT_ERROR") {
+		t.Fatalf("expected client error for bad data chunk, got %q", reply)
+	}
+}
+
+func TestMemcacheClose(t *testing.T) {
+	s, conn := startMemcache(t)
+	r := bufio.NewReader(conn)
+	roundTrip(t, conn, r, "set foo 0 0 1\r\nx\r\n", 1)
+	s.Close()
+	if _, err := r.ReadString('\n'); err == nil {
+		t.Fatal("connection should be closed")
+	}
+	if s.Cache().Len() != 1 {
+		t.Fatal("closing the server should leave the cache intact")
+	}
+}
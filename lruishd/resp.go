@@ -0,0 +1,260 @@
+package lruishd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/holiman/lruish"
+)
+
+// maxRespArgs and maxRespBulk bound the size of the commands accepted by a
+// RespServer.
+const (
+	maxRespArgs = 1024
+	maxRespBulk = 1 << 20
+)
+
+// respArity is the number of arguments of the commands taking a fixed number.
+var respArity = map[string]int{"GET": 1, "SET": 2, "TTL": 1, "KEYS": 1}
+
+// errProtocol is returned for malformed commands, after which the connection
+// is closed.
+var errProtocol = errors.New("protocol error")
+
+// RespServer serves a cache over a minimal subset of the Redis protocol
+// (RESP), meant for inspecting the cache of a running process with tools
+// such as redis-cli. It supports these commands:
+//
+//   - GET key: the value, as is for strings and byte slices, or formatted
+//     with fmt otherwise;
+//   - SET key value: stores the value as a string;
+//   - DEL key [key ...]: removes the keys, replying how many were cached;
+//   - TTL key: the seconds until the entry expires, -1 if it doesn't, or -2
+//     if it's not cached;
+//   - KEYS pattern: the keys matching a glob pattern, in which '*' matches
+//     any sequence of characters and '?' any single character;
+//   - PING and QUIT.
+//
+// The protocol only has string keys, so cache entries with keys of other
+// types can't be looked up, and are listed by KEYS formatted with fmt.
+type RespServer struct {
+	server
+	cache lruish.Cache
+	clock lruish.Clock // Clock of the cache, which items expire by
+}
+
+// NewRespServer creates a RESP server exposing the given cache.
+func NewRespServer(cache lruish.Cache) *RespServer {
+	s := &RespServer{cache: cache, clock: lruish.ClockOf(cache)}
+	s.server.init(s.serveConn)
+	return s
+}
+
+func (s *RespServer) serveConn(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(r)
+		if err == errProtocol {
+			w.WriteString("-ERR Protocol error\r\n")
+			w.Flush()
+			return
+		}
+		if err != nil {
+			return
+		}
+		if len(args) > 0 && s.execute(args, w) {
+			w.Flush()
+			return
+		}
+		if r.Buffered() == 0 {
+			// Batch the replies to pipelined commands
+			if w.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+// readCommand reads a command, either as an array of bulk strings or as an
+// inline command separated by spaces.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n > maxRespArgs {
+		return nil, errProtocol
+	}
+	args := make([]string, 0, max(n, 0))
+	for i := 0; i < n; i++ {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, errProtocol
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > maxRespBulk {
+			return nil, errProtocol
+		}
+		bulk := make([]byte, size+2)
+		if _, err := io.ReadFull(r, bulk); err != nil {
+			return nil, err
+		}
+		args = append(args, string(bulk[:size]))
+	}
+	return args, nil
+}
+
+// readLine reads a line, without its terminator.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// execute runs a single command, returning whether the connection should be
+// closed.
+func (s *RespServer) execute(args []string, w *bufio.Writer) (quit bool) {
+	cmd, args := strings.ToUpper(args[0]), args[1:]
+	if want, ok := respArity[cmd]; ok && len(args) != want || cmd == "DEL" && len(args) == 0 {
+		fmt.Fprintf(w, "-ERR wrong number of arguments for '%s' command\r\n", strings.ToLower(cmd))
+		return false
+	}
+	switch cmd {
+	case "GET":
+		var value string
+		cached, ok := s.cache.Get(args[0])
+		if ok {
+			value, ok = format(cached, s.clock.Now())
+		}
+		if !ok {
+			w.WriteString("$-1\r\n")
+			return false
+		}
+		writeBulk(w, value)
+	case "SET":
+		s.cache.Add(args[0], args[1])
+		w.WriteString("+OK\r\n")
+	case "DEL":
+		var removed int
+		for _, key := range args {
+			if s.cache.Remove(key) {
+				removed++
+			}
+		}
+		fmt.Fprintf(w, ":%d\r\n", removed)
+	case "TTL":
+		fmt.Fprintf(w, ":%d\r\n", s.ttl(args[0]))
+	case "KEYS":
+		var keys []string
+		for _, key := range s.cache.Keys() {
+			if name := fmt.Sprint(key); match(args[0], name) {
+				keys = append(keys, name)
+			}
+		}
+		fmt.Fprintf(w, "*%d\r\n", len(keys))
+		for _, key := range keys {
+			writeBulk(w, key)
+		}
+	case "PING":
+		w.WriteString("+PONG\r\n")
+	case "QUIT":
+		w.WriteString("+OK\r\n")
+		return true
+	default:
+		fmt.Fprintf(w, "-ERR unknown command '%s'\r\n", sanitize(cmd))
+	}
+	return false
+}
+
+// ttl returns the seconds until the entry for key expires, -1 if it doesn't
+// expire, and -2 if it's not cached.
+func (s *RespServer) ttl(key string) int64 {
+	value, expires, ok := s.cache.GetWithExpiry(key)
+	if !ok {
+		return -2
+	}
+	now := s.clock.Now()
+	if it, isItem := value.(*item); isItem && !it.expires.IsZero() {
+		if it.expired(now) {
+			return -2
+		}
+		if expires.IsZero() || it.expires.Before(expires) {
+			expires = it.expires
+		}
+	}
+	if expires.IsZero() {
+		return -1
+	}
+	// Round up, so that entries about to expire don't look non-expiring
+	return int64((expires.Sub(now) + time.Second - 1) / time.Second)
+}
+
+// format renders a cached value as a string, returning false for items stored
+// through the memcached protocol which have expired by now.
+func format(value interface{}, now time.Time) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	case *item:
+		if v.expired(now) {
+			return "", false
+		}
+		return string(v.data), true
+	}
+	return fmt.Sprint(value), true
+}
+
+func writeBulk(w *bufio.Writer, s string) {
+	fmt.Fprintf(w, "$%d\r\n%s\r\n", len(s), s)
+}
+
+// sanitize makes a command name safe for inclusion in an error reply.
+func sanitize(cmd string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(cmd)
+}
+
+// match reports whether name matches the glob pattern, in which '*' matches
+// any sequence of characters, and '?' any single character. It backtracks to
+// the last star only, so it runs in O(len(pattern)*len(name)) whatever the
+// number of stars.
+func match(pattern, name string) bool {
+	p, n := 0, 0
+	star, next := -1, 0 // Position of the last star, and of name after it
+	for n < len(name) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, next = p, n
+			p++
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == name[n]):
+			p, n = p+1, n+1
+		case star >= 0:
+			// Let the last star match one more character
+			next++
+			p, n = star+1, next
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,124 @@
+package lruishd
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/holiman/lruish"
+)
+
+func startResp(t *testing.T, cache lruish.Cache) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	s := NewRespServer(cache)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go s.Serve(l)
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		s.Close()
+	})
+	return conn, bufio.NewReader(conn)
+}
+
+func TestRespCommands(t *testing.T) {
+	cache, _ := lruish.New(16, lruish.WithExpireAfterWrite(time.Hour))
+	cache.Add("user:1", []byte("alice"))
+	cache.Add(42, "answer")
+	conn, r := startResp(t, cache)
+
+	for _, tt := range []struct {
+		request string
+		lines   int
+		reply   string
+	}{
+		{"*2\r\n$3\r\nGET\r\n$6\r\nuser:1\r\n", 2, "$5\r\nalice\r\n"},
+		{"*3\r\n$3\r\nset\r\n$6\r\nuser:2\r\n$3\r\nbob\r\n", 1, "+OK\r\n"},
+		{"GET user:2\r\n", 2, "$3\r\nbob\r\n"},
+		{"GET missing\r\n", 1, "$-1\r\n"},
+		{"KEYS user:?\r\n", 5, "*2\r\n$6\r\nuser:1\r\n$6\r\nuser:2\r\n"},
+		{"KEYS 4*\r\n", 3, "*1\r\n$2\r\n42\r\n"},
+		{"TTL user:1\r\n", 1, ":3600\r\n"},
+		{"TTL missing\r\n", 1, ":-2\r\n"},
+		{"DEL user:1 user:2 missing\r\n", 1, ":2\r\n"},
+		{"GET\r\n", 1, "-ERR wrong number of arguments for 'get' command\r\n"},
+		{"FLUSHALL\r\n", 1, "-ERR unknown command 'FLUSHALL'\r\n"},
+		{"PING\r\n", 1, "+PONG\r\n"},
+	} {
+		if _, err := conn.Write([]byte(tt.request)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		var reply string
+		for i := 0; i < tt.lines; i++ {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read reply to %q: %v", tt.request, err)
+			}
+			reply += line
+		}
+		if tt.request == "KEYS user:?\r\n" && reply == "*2\r\n$6\r\nuser:2\r\n$6\r\nuser:1\r\n" {
+			continue // Keys are unordered
+		}
+		if reply != tt.reply {
+			t.Fatalf("bad reply to %q: %q, want %q", tt.request, reply, tt.reply)
+		}
+	}
+}
+
+func TestRespTTLWithoutExpiry(t *testing.T) {
+	cache, _ := lruish.New(16)
+	cache.Add("k", "v")
+	conn, r := startResp(t, cache)
+	conn.Write([]byte("TTL k\r\n"))
+	if line, _ := r.ReadString('\n'); line != ":-1\r\n" {
+		t.Fatalf("expected no expiry, got %q", line)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	for _, tt := range []struct {
+		pattern, name string
+		match         bool
+	}{
+		{"*", "", true},
+		{"*", "anything", true},
+		{"a*c", "abbbc", true},
+		{"a*c", "abbb", false},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"abc", "abcd", false},
+		{"", "", true},
+		{"", "a", false},
+		{"**", "a", true},
+		{"*a*b", "xaybzb", true},
+		{"*a*b", "xaybza", false},
+		{"a*b*c", "abc", true},
+		{"?*", "", false},
+		{"*?", "a", true},
+	} {
+		if got := match(tt.pattern, tt.name); got != tt.match {
+			t.Errorf("match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.match)
+		}
+	}
+}
+
+func TestMatchStars(t *testing.T) {
+	// Backtracking over every star would take ages on this
+	pattern := strings.Repeat("*a", 20) + "b"
+	name := strings.Repeat("a", 40)
+	start := time.Now()
+	if match(pattern, name) {
+		t.Fatal("unexpected match")
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("match took %v", elapsed)
+	}
+}
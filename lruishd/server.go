@@ -0,0 +1,97 @@
+package lruishd
+
+import (
+	"net"
+	"sync"
+)
+
+// server tracks the listeners and connections of a protocol server, so they
+// can all be closed at once.
+type server struct {
+	serveConn func(conn net.Conn)
+
+	lock      sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+func (s *server) init(serveConn func(conn net.Conn)) {
+	s.serveConn = serveConn
+	s.listeners = make(map[net.Listener]struct{})
+	s.conns = make(map[net.Conn]struct{})
+}
+
+// Serve accepts connections on the listener and serves them, until the
+// listener fails or the server is closed. It always returns a non-nil error,
+// which is net.ErrClosed after Close.
+func (s *server) Serve(l net.Listener) error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return net.ErrClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.lock.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.lock.Lock()
+			delete(s.listeners, l)
+			closed := s.closed
+			s.lock.Unlock()
+			if closed {
+				return net.ErrClosed
+			}
+			return err
+		}
+		if !s.track(conn) {
+			conn.Close()
+			return net.ErrClosed
+		}
+		go s.handle(conn)
+	}
+}
+
+// track registers a connection, returning false if the server is closed.
+func (s *server) track(conn net.Conn) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+// Close stops the listeners and closes all connections, waiting for their
+// handlers to return. The cache is left open.
+func (s *server) Close() error {
+	s.lock.Lock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.lock.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// handle serves a tracked connection, and forgets it once done.
+func (s *server) handle(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		s.lock.Lock()
+		delete(s.conns, conn)
+		s.lock.Unlock()
+		s.wg.Done()
+	}()
+	s.serveConn(conn)
+}
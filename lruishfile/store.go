@@ -0,0 +1,108 @@
+// Package lruishfile provides an lruish.Store keeping values in files, so
+// that entries written through or back from a cache survive restarts
+// without a database.
+//
+// The package doesn't provide stores backed by embedded databases such as
+// bbolt or Badger, which would bring dependencies into the lruish module.
+// Such adapters belong in modules of their own, implementing lruish.Store
+// the way Store does.
+package lruishfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Store is an lruish.Store keeping each value in a file of its own within a
+// directory. Keys must be strings, and values byte slices or strings; values
+// are always returned as byte slices. Writes are atomic, so a crash leaves
+// either the old or the new value of a key.
+type Store struct {
+	dir string
+}
+
+// New creates a store in the given directory, creating it if needed.
+func New(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+// path returns the file holding the value of key. Keys are hashed, so that
+// any string makes a valid file name.
+func (s *Store) path(key interface{}) (string, error) {
+	k, ok := key.(string)
+	if !ok {
+		return "", fmt.Errorf("lruishfile: unsupported key type %T", key)
+	}
+	sum := sha256.Sum256([]byte(k))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])), nil
+}
+
+// Get returns the value stored for key, with ok false if there is none.
+func (s *Store) Get(key interface{}) (interface{}, bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Put stores the value for key.
+func (s *Store) Put(key, value interface{}) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("lruishfile: unsupported value type %T", value)
+	}
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete removes key from the store. Deleting a missing key is no error.
+func (s *Store) Delete(key interface{}) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
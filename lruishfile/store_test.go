@@ -0,0 +1,57 @@
+package lruishfile
+
+import (
+	"testing"
+
+	"github.com/holiman/lruish"
+)
+
+func TestStore(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, ok, err := s.Get("missing"); ok || err != nil {
+		t.Fatalf("missing key should not be found: %v %v", ok, err)
+	}
+	if err := s.Put("a/b", []byte("one")); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if err := s.Put("a/b", "two"); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	if v, ok, err := s.Get("a/b"); !ok || err != nil || string(v.([]byte)) != "two" {
+		t.Fatalf("bad value: %v %v %v", v, ok, err)
+	}
+	if err := s.Delete("a/b"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := s.Delete("a/b"); err != nil {
+		t.Fatalf("deleting a missing key should succeed: %v", err)
+	}
+	if err := s.Put(1, "x"); err == nil {
+		t.Fatal("non-string key should be rejected")
+	}
+	if err := s.Put("k", 1); err == nil {
+		t.Fatal("unsupported value should be rejected")
+	}
+}
+
+func TestStoreSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := New(dir)
+	wb, err := lruish.NewWriteBack(2, s)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	wb.Add("k", []byte("v"))
+	if err := wb.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	// A new cache over the same directory sees the flushed value
+	restarted, _ := New(dir)
+	wt, _ := lruish.NewWriteThrough(2, restarted)
+	if v, ok, err := wt.Get("k"); !ok || err != nil || string(v.([]byte)) != "v" {
+		t.Fatalf("value did not survive the restart: %v %v %v", v, ok, err)
+	}
+}
@@ -0,0 +1,144 @@
+// Package lruishgrpc caches the responses of unary gRPC calls in an lruish
+// cache on the client side.
+//
+// To keep the lruish module free of dependencies, the package doesn't import
+// gRPC itself. An Interceptor is hooked into a client connection through a
+// grpc.UnaryClientInterceptor like so:
+//
+//	cache, err := lruishgrpc.NewInterceptor(1024, proto.Marshal, proto.Unmarshal, ttls)
+//	...
+//	conn, err := grpc.NewClient(target, grpc.WithUnaryInterceptor(
+//		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+//			return cache.Intercept(ctx, method, req, reply, func(ctx context.Context, method string, req, reply any) error {
+//				return invoker(ctx, method, req, reply, cc, opts...)
+//			})
+//		}))
+//
+// The package thus provides no grpc.UnaryClientInterceptor of its own, and
+// call options are not part of the cache key: calls differing only in their
+// options share a cached response. A ready-made interceptor would need to
+// live in a module of its own, importing gRPC.
+package lruishgrpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/holiman/lruish"
+)
+
+// Invoker performs a unary call, storing the response in reply.
+type Invoker func(ctx context.Context, method string, req, reply interface{}) error
+
+// Interceptor caches the responses of unary calls, keyed by method and the
+// marshalled request. Identical calls in flight at the same time are
+// coalesced into one, through the single-flight loading of a
+// lruish.LoadingCache.
+type Interceptor struct {
+	cache     *lruish.LoadingCache
+	marshal   func(interface{}) ([]byte, error)
+	unmarshal func([]byte, interface{}) error
+	ttls      map[string]time.Duration
+	clock     lruish.Clock // Clock of the cache, which replies expire by
+
+	pending sync.Map // Cache key -> *pendingCall, for the loader to perform
+}
+
+// cachedReply is a marshalled response held in the cache.
+type cachedReply struct {
+	data    []byte
+	expires time.Time
+}
+
+// pendingCall is a call waiting to be performed by the loader.
+type pendingCall struct {
+	ctx     context.Context
+	method  string
+	req     interface{}
+	reply   interface{}
+	invoker Invoker
+}
+
+// errNotPending is returned by the loader if the call it's asked to perform
+// completed and was deregistered in the meantime.
+var errNotPending = errors.New("lruishgrpc: no pending call")
+
+// NewInterceptor creates an interceptor caching up to size responses. The
+// responses of each method are cached for the duration given in ttls, and
+// methods missing from ttls are not cached. Requests and responses are
+// converted with marshal and unmarshal, which should be deterministic for
+// identical requests to share a cache entry. Further features of the
+// underlying cache are configured through opts.
+func NewInterceptor(size int, marshal func(interface{}) ([]byte, error), unmarshal func([]byte, interface{}) error, ttls map[string]time.Duration, opts ...lruish.Option) (*Interceptor, error) {
+	i := &Interceptor{
+		marshal:   marshal,
+		unmarshal: unmarshal,
+		ttls:      ttls,
+	}
+	cache, err := lruish.NewLoading(size, i.load, opts...)
+	if err != nil {
+		return nil, err
+	}
+	i.cache = cache
+	i.clock = lruish.ClockOf(cache.Cache)
+	return i, nil
+}
+
+// Cache returns the cache holding the marshalled responses.
+func (i *Interceptor) Cache() lruish.Cache {
+	return i.cache
+}
+
+// Intercept serves a unary call from the cache if possible, and performs it
+// with invoker otherwise. Failed calls are not cached. Calls coalesced with
+// an identical one in flight share its outcome, including failures due to
+// the context of the caller which started it. Callers waiting on a call
+// started by another return ctx.Err() once their own context is done.
+func (i *Interceptor) Intercept(ctx context.Context, method string, req, reply interface{}, invoker Invoker) error {
+	if _, ok := i.ttls[method]; !ok {
+		return invoker(ctx, method, req, reply)
+	}
+	data, err := i.marshal(req)
+	if err != nil {
+		return err
+	}
+	key := method + "\x00" + string(data)
+
+	// Drop the cached response if it has outlived the method's TTL
+	if v, ok := i.cache.Cache.Peek(key); ok && i.clock.Now().After(v.(*cachedReply).expires) {
+		i.cache.Remove(key)
+	}
+	call := &pendingCall{ctx: ctx, method: method, req: req, reply: reply, invoker: invoker}
+	_, loaded := i.pending.LoadOrStore(key, call)
+	v, err := i.cache.LoadContext(ctx, key)
+	if !loaded {
+		i.pending.CompareAndDelete(key, call)
+	}
+	if err == errNotPending {
+		return invoker(ctx, method, req, reply)
+	}
+	if err != nil {
+		return err
+	}
+	return i.unmarshal(v.(*cachedReply).data, reply)
+}
+
+// load performs the pending call registered under the key, and marshals its
+// response for the cache.
+func (i *Interceptor) load(key interface{}) (interface{}, error) {
+	v, ok := i.pending.Load(key)
+	if !ok {
+		return nil, errNotPending
+	}
+	call := v.(*pendingCall)
+	if err := call.invoker(call.ctx, call.method, call.req, call.reply); err != nil {
+		return nil, err
+	}
+	data, err := i.marshal(call.reply)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedReply{data: data, expires: i.clock.Now().Add(i.ttls[call.method])}, nil
+}
@@ -0,0 +1,145 @@
+package lruishgrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type echoRequest struct {
+	Name string
+}
+
+type echoReply struct {
+	Greeting string
+}
+
+func newTestInterceptor(t *testing.T, ttls map[string]time.Duration) *Interceptor {
+	t.Helper()
+	i, err := NewInterceptor(16, json.Marshal, json.Unmarshal, ttls)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return i
+}
+
+func TestInterceptorCaches(t *testing.T) {
+	i := newTestInterceptor(t, map[string]time.Duration{"/Echo/Hello": time.Minute})
+	var calls int32
+	invoker := func(ctx context.Context, method string, req, reply interface{}) error {
+		atomic.AddInt32(&calls, 1)
+		reply.(*echoReply).Greeting = "hello " + req.(*echoRequest).Name
+		return nil
+	}
+	for _, name := range []string{"a", "b", "a", "a"} {
+		var reply echoReply
+		if err := i.Intercept(context.Background(), "/Echo/Hello", &echoRequest{Name: name}, &reply, invoker); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if reply.Greeting != "hello "+name {
+			t.Fatalf("bad reply for %s: %q", name, reply.Greeting)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected one call per distinct request, got %d", calls)
+	}
+	// Methods without a TTL are not cached
+	for n := 0; n < 2; n++ {
+		i.Intercept(context.Background(), "/Echo/Other", &echoRequest{Name: "a"}, &echoReply{}, invoker)
+	}
+	if calls != 4 {
+		t.Fatalf("uncached method should be called every time, got %d calls", calls)
+	}
+}
+
+func TestInterceptorTTL(t *testing.T) {
+	i := newTestInterceptor(t, map[string]time.Duration{"/Echo/Hello": time.Millisecond})
+	var calls int32
+	invoker := func(ctx context.Context, method string, req, reply interface{}) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}
+	i.Intercept(context.Background(), "/Echo/Hello", &echoRequest{}, &echoReply{}, invoker)
+	time.Sleep(2 * time.Millisecond)
+	i.Intercept(context.Background(), "/Echo/Hello", &echoRequest{}, &echoReply{}, invoker)
+	if calls != 2 {
+		t.Fatalf("expired response should be fetched again, got %d calls", calls)
+	}
+}
+
+func TestInterceptorCoalesces(t *testing.T) {
+	i := newTestInterceptor(t, map[string]time.Duration{"/Echo/Hello": time.Minute})
+	var calls int32
+	release := make(chan struct{})
+	invoker := func(ctx context.Context, method string, req, reply interface{}) error {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		reply.(*echoReply).Greeting = "hi"
+		return nil
+	}
+	var wg sync.WaitGroup
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var reply echoReply
+			if err := i.Intercept(context.Background(), "/Echo/Hello", &echoRequest{Name: "x"}, &reply, invoker); err != nil || reply.Greeting != "hi" {
+				t.Errorf("bad reply: %q %v", reply.Greeting, err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Fatalf("expected concurrent calls to be coalesced, got %d", calls)
+	}
+}
+
+func TestInterceptorError(t *testing.T) {
+	i := newTestInterceptor(t, map[string]time.Duration{"/Echo/Hello": time.Minute})
+	fail := errors.New("unavailable")
+	var calls int32
+	invoker := func(ctx context.Context, method string, req, reply interface{}) error {
+		atomic.AddInt32(&calls, 1)
+		return fail
+	}
+	for n := 0; n < 2; n++ {
+		if err := i.Intercept(context.Background(), "/Echo/Hello", &echoRequest{}, &echoReply{}, invoker); err != fail {
+			t.Fatalf("expected call error, got %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("failed calls should not be cached, got %d calls", calls)
+	}
+}
+
+func TestInterceptorCancel(t *testing.T) {
+	i := newTestInterceptor(t, map[string]time.Duration{"/Echo/Hello": time.Minute})
+	started, release := make(chan struct{}), make(chan struct{})
+	invoker := func(ctx context.Context, method string, req, reply interface{}) error {
+		close(started)
+		<-release
+		reply.(*echoReply).Greeting = "hi"
+		return nil
+	}
+	done := make(chan error)
+	go func() {
+		done <- i.Intercept(context.Background(), "/Echo/Hello", &echoRequest{}, &echoReply{}, invoker)
+	}()
+	<-started
+	// A caller waiting on the call in flight gives up when cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := i.Intercept(ctx, "/Echo/Hello", &echoRequest{}, &echoReply{}, invoker); err != context.DeadlineExceeded {
+		t.Errorf("have %v, want context.DeadlineExceeded", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("err: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package lruishhttp
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/holiman/lruish"
+)
+
+// renderedResponse is a response rendered by a handler, held in the cache.
+type renderedResponse struct {
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// Middleware returns a middleware which caches the responses rendered by the
+// wrapped handler for GET and HEAD requests, and serves them from the cache
+// for ttl. Requests are keyed by keyFn, or by method and URL if nil; requests
+// for which keyFn returns the empty string bypass the cache.
+//
+// The cache is shared between all clients, so requests carrying credentials,
+// in an Authorization or Cookie header, bypass it. Responses are not cached if
+// their status is 5xx, if they set cookies, if they vary by request headers,
+// or if they carry Cache-Control no-store or private. Only responses to GET
+// are stored, as those to HEAD have no body; HEAD requests are still served
+// from the cache. Responses served from the cache carry an X-From-Cache
+// header. Expiry follows the clock of the cache.
+func Middleware(cache lruish.Cache, keyFn func(*http.Request) string, ttl time.Duration) func(http.Handler) http.Handler {
+	if keyFn == nil {
+		keyFn = func(r *http.Request) string {
+			return r.Method + " " + r.URL.String()
+		}
+	}
+	clock := lruish.ClockOf(cache)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead || hasCredentials(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := keyFn(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if v, ok := cache.Get(key); ok {
+				if resp := v.(*renderedResponse); clock.Now().Before(resp.expires) {
+					resp.serve(w)
+					return
+				}
+			}
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+			if rec.header == nil {
+				rec.header = w.Header().Clone()
+			}
+			if r.Method == http.MethodGet && cacheable(rec.status, rec.header) {
+				cache.Add(key, &renderedResponse{
+					status:  rec.status,
+					header:  rec.header,
+					body:    rec.body.Bytes(),
+					expires: clock.Now().Add(ttl),
+				})
+			}
+		})
+	}
+}
+
+// hasCredentials reports whether the request identifies its user, so that the
+// response may be personal.
+func hasCredentials(r *http.Request) bool {
+	return r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != ""
+}
+
+// cacheable reports whether a rendered response may be cached.
+func cacheable(status int, header http.Header) bool {
+	if status >= 500 || header.Get("Set-Cookie") != "" || header.Get("Vary") != "" {
+		return false
+	}
+	control := parseCacheControl(header)
+	return !control.has("no-store") && !control.has("private")
+}
+
+// serve writes the cached response to w.
+func (resp *renderedResponse) serve(w http.ResponseWriter) {
+	header := w.Header()
+	for name, values := range resp.header {
+		header[name] = append([]string(nil), values...)
+	}
+	header.Set("X-From-Cache", "1")
+	w.WriteHeader(resp.status)
+	w.Write(resp.body)
+}
+
+// responseRecorder passes a response through to the client, while recording
+// it for the cache.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	header http.Header // Header as of WriteHeader
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.header != nil {
+		return
+	}
+	rec.status = status
+	rec.header = rec.ResponseWriter.Header().Clone()
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(p []byte) (int, error) {
+	if rec.header == nil {
+		rec.WriteHeader(http.StatusOK)
+	}
+	rec.body.Write(p)
+	return rec.ResponseWriter.Write(p)
+}
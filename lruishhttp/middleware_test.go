@@ -0,0 +1,141 @@
+package lruishhttp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/holiman/lruish"
+)
+
+func TestMiddleware(t *testing.T) {
+	cache, err := lruish.New(10)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var renders int
+	handler := Middleware(cache, nil, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		renders++
+		switch r.URL.Path {
+		case "/cookie":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/missing":
+			w.Header().Set("X-Reason", "gone")
+			w.WriteHeader(http.StatusNotFound)
+		}
+		fmt.Fprintf(w, "render %d", renders)
+	}))
+	serve := func(method, path string) *http.Response {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Result()
+	}
+	body := func(resp *http.Response) string {
+		b, _ := io.ReadAll(resp.Body)
+		return string(b)
+	}
+
+	first := serve("GET", "/page")
+	second := serve("GET", "/page")
+	if body(first) != "render 1" || body(second) != "render 1" || second.Header.Get("X-From-Cache") == "" {
+		t.Fatal("second GET should be served from cache")
+	}
+	serve("GET", "/missing")
+	if resp := serve("GET", "/missing"); resp.StatusCode != http.StatusNotFound || resp.Header.Get("X-Reason") != "gone" {
+		t.Fatalf("cached response lost status or headers: %d %v", resp.StatusCode, resp.Header)
+	}
+	for _, path := range []string{"/cookie", "/error"} {
+		serve("GET", path)
+		if resp := serve("GET", path); resp.Header.Get("X-From-Cache") != "" {
+			t.Fatalf("%s should not be cached", path)
+		}
+	}
+	serve("POST", "/form")
+	if resp := serve("POST", "/form"); resp.Header.Get("X-From-Cache") != "" {
+		t.Fatal("POST should not be cached")
+	}
+}
+
+// manualClock is a lruish.Clock which only moves when told to.
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+func (c *manualClock) After(d time.Duration) <-chan time.Time {
+	return nil
+}
+
+func TestMiddlewareTTL(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1000000, 0)}
+	cache, _ := lruish.New(10, lruish.WithClock(clock))
+	var renders int
+	handler := Middleware(cache, func(r *http.Request) string {
+		return r.URL.Query().Get("id")
+	}, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		renders++
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/?id=1", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/?id=1&x=2", nil))
+	if renders != 1 {
+		t.Fatalf("requests with the same key should share a response, rendered %d", renders)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/?id=1", nil))
+	if renders != 2 {
+		t.Fatalf("expired response should be rendered again, rendered %d", renders)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if renders != 4 {
+		t.Fatalf("requests with an empty key should bypass the cache, rendered %d", renders)
+	}
+}
+
+func TestMiddlewarePrivate(t *testing.T) {
+	cache, _ := lruish.New(10)
+	var renders int
+	handler := Middleware(cache, func(r *http.Request) string {
+		return r.URL.Path
+	}, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		renders++
+		if r.URL.Path == "/vary" {
+			w.Header().Set("Vary", "Accept-Language")
+		}
+		fmt.Fprintf(w, "render %d", renders)
+	}))
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	for _, header := range []string{"Authorization", "Cookie"} {
+		r := httptest.NewRequest("GET", "/private", nil)
+		r.Header.Set(header, "secret")
+		serve(r)
+		if w := serve(httptest.NewRequest("GET", "/private", nil)); w.Header().Get("X-From-Cache") != "" {
+			t.Errorf("response to a request with %s served from the cache", header)
+		}
+		cache.Purge()
+	}
+	serve(httptest.NewRequest("GET", "/vary", nil))
+	if w := serve(httptest.NewRequest("GET", "/vary", nil)); w.Header().Get("X-From-Cache") != "" {
+		t.Error("response with Vary served from the cache")
+	}
+	// HEAD responses have no body, so they mustn't be served for GET
+	serve(httptest.NewRequest("HEAD", "/page", nil))
+	if w := serve(httptest.NewRequest("GET", "/page", nil)); w.Header().Get("X-From-Cache") != "" || w.Body.Len() == 0 {
+		t.Error("HEAD response served for GET")
+	}
+	if w := serve(httptest.NewRequest("HEAD", "/page", nil)); w.Header().Get("X-From-Cache") == "" {
+		t.Error("HEAD not served from the cached GET response")
+	}
+}
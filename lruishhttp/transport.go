@@ -0,0 +1,323 @@
+// Package lruishhttp provides HTTP integrations of the lruish cache: a
+// caching http.RoundTripper for clients, and a middleware caching rendered
+// responses for servers.
+package lruishhttp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/holiman/lruish"
+)
+
+// Transport is an http.RoundTripper which caches the responses to GET
+// requests in an lruish cache, keyed by URL. It acts as a private cache:
+//
+//   - responses are only stored if they are 200 OK, and neither the request
+//     nor the response carries Cache-Control no-store;
+//   - responses are fresh for their Cache-Control max-age, or until their
+//     Expires header otherwise, and are served from the cache while fresh;
+//   - stale responses, and those marked no-cache, are revalidated with
+//     If-None-Match and If-Modified-Since if they carry an ETag or a
+//     Last-Modified header, and served from the cache on 304 Not Modified;
+//   - responses varying on request headers are not stored.
+//
+// Responses served from the cache carry an X-From-Cache header. Bodies are
+// passed through to the caller as they arrive, and the response is stored
+// once the caller has read its body to the end. Bodies which turn out larger
+// than the byte budget are passed through uncached.
+//
+// The capacity of the cache is bounded both by a number of responses, and by
+// the total size of their bodies and headers.
+type Transport struct {
+	next     http.RoundTripper
+	cache    lruish.Cache
+	maxBytes int64
+	bytes    int64 // Size of the cached responses, updated atomically
+
+	trim sync.Mutex       // Serializes trimming the cache to size
+	now  func() time.Time // Now of the clock of the cache
+}
+
+// cachedResponse is a response held in the cache.
+type cachedResponse struct {
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time // Time until which the response is fresh
+	size    int64
+}
+
+// NewTransport creates a caching transport which sends requests through next,
+// or http.DefaultTransport if nil. The cache holds up to maxEntries responses,
+// and if maxBytes is positive, up to maxBytes bytes of them. Further features
+// of the underlying cache are configured through opts, except for
+// lruish.WithOnEvictReason: the transport accounts for the size of the cache
+// through it, overriding any callback passed in opts. Use lruish.WithOnEvict
+// to observe evictions instead.
+func NewTransport(next http.RoundTripper, maxEntries int, maxBytes int64, opts ...lruish.Option) (*Transport, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	t := &Transport{next: next, maxBytes: maxBytes}
+	cache, err := lruish.New(maxEntries, append(opts, lruish.WithOnEvictReason(t.evicted))...)
+	if err != nil {
+		return nil, err
+	}
+	t.cache = cache
+	t.now = lruish.ClockOf(cache).Now
+	return t, nil
+}
+
+// Cache returns the cache holding the responses, keyed by URL.
+func (t *Transport) Cache() lruish.Cache {
+	return t.cache
+}
+
+// evicted accounts for a response leaving the cache.
+func (t *Transport) evicted(key, value interface{}, reason lruish.EvictionReason) {
+	atomic.AddInt64(&t.bytes, -value.(*cachedResponse).size)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqControl := parseCacheControl(req.Header)
+	if req.Method != http.MethodGet || reqControl.has("no-store") {
+		return t.next.RoundTrip(req)
+	}
+	key := req.URL.String()
+
+	var cached *cachedResponse
+	if v, ok := t.cache.Get(key); ok {
+		cached = v.(*cachedResponse)
+		if !reqControl.has("no-cache") && t.now().Before(cached.expires) {
+			return cached.response(req), nil
+		}
+	}
+	// Revalidate the cached response, unless the caller does so itself
+	outgoing, revalidating := req, false
+	if cached != nil && validatable(cached.header) && !conditional(req) {
+		outgoing, revalidating = revalidation(req, cached), true
+	}
+	resp, err := t.next.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	if revalidating && resp.StatusCode == http.StatusNotModified {
+		// Serve the cached body, with the headers and freshness of the new
+		// response
+		resp.Body.Close()
+		updated := *cached
+		updated.header = cached.header.Clone()
+		for name, values := range resp.Header {
+			if name != "Content-Length" {
+				updated.header[name] = values
+			}
+		}
+		updated.expires = t.expiry(updated.header)
+		updated.size = int64(len(updated.body)) + headerSize(updated.header)
+		t.store(key, &updated)
+		return updated.response(req), nil
+	}
+	if !t.storable(resp) {
+		return resp, nil
+	}
+	// Record the body as the caller reads it, and cache the response once it
+	// has been read completely
+	header, status := resp.Header.Clone(), resp.StatusCode
+	limit := int64(-1)
+	if t.maxBytes > 0 {
+		limit = max(0, t.maxBytes-headerSize(header))
+	}
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		limit:      limit,
+		done: func(body []byte) {
+			t.store(key, &cachedResponse{
+				status:  status,
+				header:  header,
+				body:    body,
+				expires: t.expiry(header),
+				size:    int64(len(body)) + headerSize(header),
+			})
+		},
+	}
+	return resp, nil
+}
+
+// recordingBody passes a response body through to the caller, while recording
+// it for the cache. The body is handed to done once read to the end, unless
+// it grew beyond the limit. A negative limit means no limit.
+type recordingBody struct {
+	io.ReadCloser
+	buf      bytes.Buffer
+	limit    int64
+	overflow bool
+	done     func(body []byte)
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if !b.overflow {
+		if b.limit >= 0 && int64(b.buf.Len()+n) > b.limit {
+			// Too large to cache, stop recording
+			b.overflow = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF && !b.overflow && b.done != nil {
+		b.done(b.buf.Bytes())
+		b.done = nil
+	}
+	return n, err
+}
+
+// storable reports whether the response may be cached.
+func (t *Transport) storable(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Vary") != "" {
+		return false
+	}
+	if parseCacheControl(resp.Header).has("no-store") {
+		return false
+	}
+	if t.maxBytes > 0 && resp.ContentLength > t.maxBytes {
+		return false
+	}
+	// Responses which are never fresh are only useful if they can be revalidated
+	return t.expiry(resp.Header).After(t.now()) || validatable(resp.Header)
+}
+
+// store adds the response to the cache, and trims the cache to size.
+func (t *Transport) store(key string, resp *cachedResponse) {
+	if t.maxBytes > 0 && resp.size > t.maxBytes {
+		return
+	}
+	atomic.AddInt64(&t.bytes, resp.size)
+	t.cache.Add(key, resp)
+	if t.maxBytes <= 0 || atomic.LoadInt64(&t.bytes) <= t.maxBytes {
+		return
+	}
+	t.trim.Lock()
+	defer t.trim.Unlock()
+
+	// Drop the least recently used responses until the cache fits
+	entries := t.cache.Entries()
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Position > entries[j].Position
+	})
+	for _, ent := range entries {
+		if atomic.LoadInt64(&t.bytes) <= t.maxBytes {
+			return
+		}
+		t.cache.Remove(ent.Key)
+	}
+}
+
+// expiry returns the time until which a response with the given headers is
+// fresh.
+func (t *Transport) expiry(header http.Header) time.Time {
+	now := t.now()
+	control := parseCacheControl(header)
+	if control.has("no-cache") {
+		return now
+	}
+	if age, ok := control["max-age"]; ok {
+		seconds, err := strconv.ParseInt(age, 10, 64)
+		if err != nil {
+			return now
+		}
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	expires, err := http.ParseTime(header.Get("Expires"))
+	if err != nil {
+		return now
+	}
+	// Account for clock skew with the server by measuring from its Date
+	if date, err := http.ParseTime(header.Get("Date")); err == nil {
+		return now.Add(expires.Sub(date))
+	}
+	return expires
+}
+
+// response builds the response to req from the cached one.
+func (c *cachedResponse) response(req *http.Request) *http.Response {
+	header := c.header.Clone()
+	header.Set("X-From-Cache", "1")
+	return &http.Response{
+		Status:        strconv.Itoa(c.status) + " " + http.StatusText(c.status),
+		StatusCode:    c.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(c.body)),
+		ContentLength: int64(len(c.body)),
+		Request:       req,
+	}
+}
+
+// revalidation returns a copy of req, made conditional on the cached
+// response having changed.
+func revalidation(req *http.Request, cached *cachedResponse) *http.Request {
+	req = req.Clone(req.Context())
+	if etag := cached.header.Get("ETag"); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if modified := cached.header.Get("Last-Modified"); modified != "" {
+		req.Header.Set("If-Modified-Since", modified)
+	}
+	return req
+}
+
+// conditional reports whether the request is a conditional one.
+func conditional(req *http.Request) bool {
+	return req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+}
+
+// validatable reports whether a response with the given headers can be
+// revalidated with a conditional request.
+func validatable(header http.Header) bool {
+	return header.Get("ETag") != "" || header.Get("Last-Modified") != ""
+}
+
+// headerSize approximates the memory taken by the header.
+func headerSize(header http.Header) int64 {
+	var size int64
+	for name, values := range header {
+		for _, value := range values {
+			size += int64(len(name) + len(value))
+		}
+	}
+	return size
+}
+
+// cacheControl holds the directives of a Cache-Control header, lowercased,
+// with the values of those which have one.
+type cacheControl map[string]string
+
+func parseCacheControl(header http.Header) cacheControl {
+	control := make(cacheControl)
+	for _, line := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(line, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name != "" {
+				control[strings.ToLower(name)] = strings.Trim(value, `"`)
+			}
+		}
+	}
+	return control
+}
+
+func (c cacheControl) has(directive string) bool {
+	_, ok := c[directive]
+	return ok
+}
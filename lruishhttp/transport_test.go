@@ -0,0 +1,172 @@
+package lruishhttp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, client *http.Client, url string) (string, bool) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("get %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read %s: %v", url, err)
+	}
+	return string(body), resp.Header.Get("X-From-Cache") != ""
+}
+
+func TestTransportMaxAge(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store, max-age=60")
+		}
+		fmt.Fprintf(w, "%s %d", r.URL.Path, n)
+	}))
+	defer srv.Close()
+
+	tr, err := NewTransport(nil, 10, 0)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	now := time.Now()
+	tr.now = func() time.Time { return now }
+	client := &http.Client{Transport: tr}
+
+	first, _ := get(t, client, srv.URL+"/fresh")
+	if body, cached := get(t, client, srv.URL+"/fresh"); !cached || body != first {
+		t.Fatalf("fresh response should be served from cache, got %q (cached %v)", body, cached)
+	}
+	now = now.Add(61 * time.Second)
+	if body, cached := get(t, client, srv.URL+"/fresh"); cached || body == first {
+		t.Fatalf("stale response should be refetched, got %q", body)
+	}
+	get(t, client, srv.URL+"/nostore")
+	if _, cached := get(t, client, srv.URL+"/nostore"); cached {
+		t.Fatal("no-store response should not be cached")
+	}
+	get(t, client, srv.URL+"/plain")
+	if _, cached := get(t, client, srv.URL+"/plain"); cached {
+		t.Fatal("response without freshness or validator should not be cached")
+	}
+	resp, _ := client.Post(srv.URL+"/fresh", "text/plain", strings.NewReader(""))
+	resp.Body.Close()
+	if resp.Header.Get("X-From-Cache") != "" {
+		t.Fatal("POST should not be served from cache")
+	}
+}
+
+func TestTransportETag(t *testing.T) {
+	var full, notModified int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		io.WriteString(w, "payload")
+	}))
+	defer srv.Close()
+
+	tr, _ := NewTransport(nil, 10, 0)
+	client := &http.Client{Transport: tr}
+	get(t, client, srv.URL)
+	for i := 0; i < 3; i++ {
+		if body, cached := get(t, client, srv.URL); !cached || body != "payload" {
+			t.Fatalf("revalidated response should be served from cache, got %q (cached %v)", body, cached)
+		}
+	}
+	if full != 1 || notModified != 3 {
+		t.Fatalf("expected 1 full response and 3 revalidations, got %d and %d", full, notModified)
+	}
+}
+
+func TestTransportMaxBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		io.WriteString(w, strings.Repeat("x", 1000))
+	}))
+	defer srv.Close()
+
+	tr, _ := NewTransport(nil, 100, 3500)
+	client := &http.Client{Transport: tr}
+	for i := 0; i < 10; i++ {
+		get(t, client, fmt.Sprintf("%s/%d", srv.URL, i))
+	}
+	if n := tr.Cache().Len(); n != 3 {
+		t.Fatalf("expected 3 responses to fit, got %d", n)
+	}
+	if tr.bytes > 3500 {
+		t.Fatalf("cache exceeds its byte budget: %d", tr.bytes)
+	}
+	if _, cached := get(t, client, srv.URL+"/9"); !cached {
+		t.Fatal("most recent response should have been kept")
+	}
+}
+
+func TestTransportLargeBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		// Flushing makes the body chunked, without a Content-Length
+		for i := 0; i < 10; i++ {
+			io.WriteString(w, strings.Repeat("x", 1000))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer srv.Close()
+
+	tr, _ := NewTransport(nil, 100, 5000)
+	client := &http.Client{Transport: tr}
+	if body, _ := get(t, client, srv.URL); len(body) != 10000 {
+		t.Fatalf("have %d bytes, want 10000", len(body))
+	}
+	if tr.Cache().Len() != 0 || tr.bytes != 0 {
+		t.Fatalf("response beyond the byte budget cached, %d bytes", tr.bytes)
+	}
+}
+
+func TestTransportNotModifiedHeaders(t *testing.T) {
+	var revalidations int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&revalidations, 1)
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "no-cache")
+		io.WriteString(w, "payload")
+	}))
+	defer srv.Close()
+
+	tr, _ := NewTransport(nil, 10, 0)
+	client := &http.Client{Transport: tr}
+	get(t, client, srv.URL)
+	for i := 0; i < 3; i++ {
+		if body, cached := get(t, client, srv.URL); !cached || body != "payload" {
+			t.Fatalf("have %q (cached %v), want the cached payload", body, cached)
+		}
+	}
+	// The 304 made the response fresh, so it isn't revalidated again
+	if revalidations != 1 {
+		t.Fatalf("have %d revalidations, want 1", revalidations)
+	}
+}
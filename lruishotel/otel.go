@@ -0,0 +1,118 @@
+// Package lruishotel exposes the behavior of lruish caches to OpenTelemetry:
+// the cache counters as metric instruments, and loads through a
+// lruish.LoadingCache as events on the caller's span.
+//
+// To keep the lruish module free of dependencies, the package doesn't import
+// OpenTelemetry itself. The instruments are registered with a meter and fed
+// by Observe from a callback, like so:
+//
+//	counters := make(map[string]metric.Int64Observable)
+//	for _, inst := range lruishotel.Instruments {
+//		if inst.Kind == lruishotel.Counter {
+//			counters[inst.Name], err = meter.Int64ObservableCounter(inst.Name,
+//				metric.WithDescription(inst.Description), metric.WithUnit(inst.Unit))
+//		} else {
+//			counters[inst.Name], err = meter.Int64ObservableGauge(inst.Name,
+//				metric.WithDescription(inst.Description), metric.WithUnit(inst.Unit))
+//		}
+//		...
+//	}
+//	attrs := metric.WithAttributes(attribute.String("cache", "users"))
+//	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+//		lruishotel.Observe(cache, func(name string, value int64) {
+//			o.ObserveInt64(counters[name], value, attrs)
+//		})
+//		return nil
+//	}, slices.Collect(maps.Values(counters))...)
+//
+// Loads are annotated by going through Load, with a function adding the
+// event to the span of the context:
+//
+//	value, err := lruishotel.Load(ctx, cache, key, func(ctx context.Context, ev lruishotel.Event) {
+//		trace.SpanFromContext(ctx).AddEvent(ev.Name(), trace.WithAttributes(
+//			attribute.Bool("cache.hit", ev.Hit),
+//			attribute.Int64("cache.load_ms", ev.Duration.Milliseconds())))
+//	})
+//
+// The package thus registers no instruments and creates no spans itself;
+// that is left to the application, as above. Only lookups going through
+// Load are annotated, as the caches have no other loading path to hook.
+// Instruments registered by the package itself would need to live in a
+// module of its own, importing OpenTelemetry.
+package lruishotel
+
+import (
+	"context"
+	"time"
+
+	"github.com/holiman/lruish"
+)
+
+// Kind is the kind of an instrument.
+type Kind int
+
+const (
+	Counter Kind = iota // Monotonic counter, observed as a running total
+	Gauge               // Current value
+)
+
+// Instrument describes a metric reported by Observe.
+type Instrument struct {
+	Name        string
+	Description string
+	Unit        string
+	Kind        Kind
+}
+
+// Instruments lists the metrics reported by Observe.
+var Instruments = []Instrument{
+	{"lruish.hits", "Lookups finding their key in the cache", "{lookup}", Counter},
+	{"lruish.misses", "Lookups not finding their key in the cache", "{lookup}", Counter},
+	{"lruish.evictions", "Entries evicted to make room", "{entry}", Counter},
+	{"lruish.ghost_hits", "Misses on recently evicted keys", "{lookup}", Counter},
+	{"lruish.entries", "Entries in the cache", "{entry}", Gauge},
+	{"lruish.capacity", "Maximum number of entries in the cache", "{entry}", Gauge},
+}
+
+// Observe reports the current value of each of the Instruments of the cache
+// to fn, typically from a meter callback.
+func Observe(c lruish.Cache, fn func(name string, value int64)) {
+	stats := c.Stats()
+	fn("lruish.hits", int64(stats.Hits))
+	fn("lruish.misses", int64(stats.Misses))
+	fn("lruish.evictions", int64(stats.Evictions))
+	fn("lruish.ghost_hits", int64(stats.GhostHits))
+	fn("lruish.entries", int64(c.Len()))
+	fn("lruish.capacity", int64(c.Cap()))
+}
+
+// Event describes a lookup through Load.
+type Event struct {
+	Key      interface{}
+	Hit      bool          // Whether the value was cached
+	Duration time.Duration // Time spent in the lookup, including any load
+	Err      error         // Error of the load, if it failed
+}
+
+// Name returns the name of the span event for the lookup.
+func (ev Event) Name() string {
+	if ev.Hit {
+		return "lruish.hit"
+	}
+	return "lruish.load"
+}
+
+// Load looks up a key's value from the cache like LoadingCache.LoadContext,
+// then reports the lookup to annotate along with ctx, so that it can be
+// recorded on the span of the context.
+func Load(ctx context.Context, c *lruish.LoadingCache, key interface{}, annotate func(ctx context.Context, ev Event)) (interface{}, error) {
+	clock := lruish.ClockOf(c.Cache)
+	start := clock.Now()
+	if value, ok := c.Cache.Get(key); ok {
+		annotate(ctx, Event{Key: key, Hit: true, Duration: clock.Now().Sub(start)})
+		return value, nil
+	}
+	value, err := c.LoadContext(ctx, key)
+	annotate(ctx, Event{Key: key, Duration: clock.Now().Sub(start), Err: err})
+	return value, err
+}
@@ -0,0 +1,73 @@
+package lruishotel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/holiman/lruish"
+)
+
+func TestObserve(t *testing.T) {
+	c, _ := lruish.New(2)
+	c.Add("a", 1)
+	c.Get("a")
+	c.Get("b")
+	c.Add("b", 2)
+	c.Add("c", 3)
+
+	have := make(map[string]int64)
+	Observe(c, func(name string, value int64) {
+		have[name] = value
+	})
+	if len(have) != len(Instruments) {
+		t.Errorf("have %d observations, want %d", len(have), len(Instruments))
+	}
+	for _, inst := range Instruments {
+		if _, ok := have[inst.Name]; !ok {
+			t.Errorf("instrument %s not observed", inst.Name)
+		}
+	}
+	want := map[string]int64{
+		"lruish.hits":      1,
+		"lruish.misses":    1,
+		"lruish.evictions": 1,
+		"lruish.entries":   2,
+		"lruish.capacity":  2,
+	}
+	for name, value := range want {
+		if have[name] != value {
+			t.Errorf("%s: have %d, want %d", name, have[name], value)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	failure := errors.New("failure")
+	c, _ := lruish.NewLoading(10, func(key interface{}) (interface{}, error) {
+		if key == "bad" {
+			return nil, failure
+		}
+		return key, nil
+	})
+	var events []Event
+	annotate := func(_ context.Context, ev Event) {
+		events = append(events, ev)
+	}
+	ctx := context.Background()
+	for _, key := range []string{"a", "a", "bad"} {
+		Load(ctx, c, key, annotate)
+	}
+	if len(events) != 3 {
+		t.Fatalf("have %d events, want 3", len(events))
+	}
+	if events[0].Hit || events[0].Name() != "lruish.load" {
+		t.Errorf("first lookup: have %+v, want load", events[0])
+	}
+	if !events[1].Hit || events[1].Name() != "lruish.hit" {
+		t.Errorf("second lookup: have %+v, want hit", events[1])
+	}
+	if events[2].Hit || !errors.Is(events[2].Err, failure) {
+		t.Errorf("failed lookup: have %+v", events[2])
+	}
+}
@@ -0,0 +1,168 @@
+// Package lruishpeer distributes a read-through lruish cache over a set of
+// peers, groupcache style. Each key is owned by one peer, chosen by
+// consistent hashing, which is the only one loading it; the other peers ask
+// the owner over HTTP on a miss. Values fetched from peers are replicated
+// into a small local cache now and then, so that hot keys are eventually
+// served locally by every peer.
+package lruishpeer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/holiman/lruish"
+)
+
+// replicateOneIn is the inverse of the probability of a value fetched from a
+// peer being replicated into the local hot cache. Keys fetched often are thus
+// likely to be replicated, while rarely fetched keys don't crowd the cache.
+const replicateOneIn = 10
+
+// Group is a cache of byte slices keyed by strings, spread over a set of
+// peers. Values must not be modified once returned by the loader or Get.
+type Group struct {
+	self     string // Base URL of this peer
+	basePath string
+	client   *http.Client
+
+	main *lruish.LoadingCache // Values of the keys owned by this peer
+	hot  lruish.Cache         // Values replicated from other peers
+
+	lock sync.RWMutex
+	ring *hashRing
+}
+
+// NewGroup creates a group, as seen by the peer reachable at the self URL,
+// which serves the group under basePath, such as "/_lruish/". The cache of
+// owned keys holds up to size values, loaded with loader on a miss, and the
+// cache of replicated hot keys up to an eighth of that. Further features of
+// the caches are configured through opts.
+//
+// The group only consults the other peers once SetPeers is called, and must
+// be mounted at basePath on the peer's HTTP server.
+func NewGroup(self, basePath string, size int, loader func(key string) ([]byte, error), opts ...lruish.Option) (*Group, error) {
+	main, err := lruish.NewLoading(size, func(key interface{}) (interface{}, error) {
+		return loader(key.(string))
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	hot, err := lruish.New(max(size/8, 1), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Group{
+		self:     strings.TrimSuffix(self, "/"),
+		basePath: "/" + strings.Trim(basePath, "/") + "/",
+		client:   http.DefaultClient,
+		main:     main,
+		hot:      hot,
+		ring:     newHashRing(nil),
+	}, nil
+}
+
+// SetPeers sets the base URLs of the peers of the group, which should include
+// the URL of this peer.
+func (g *Group) SetPeers(peers ...string) {
+	trimmed := make([]string, len(peers))
+	for i, peer := range peers {
+		trimmed[i] = strings.TrimSuffix(peer, "/")
+	}
+	ring := newHashRing(trimmed)
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.ring = ring
+}
+
+// Get returns the value of the key. Keys owned by this peer are loaded
+// locally on a miss, the others are fetched from their owner, falling back
+// to loading them locally if the owner can't be reached. Get returns
+// ctx.Err() once ctx is done, leaving a local load to complete in the
+// background.
+func (g *Group) Get(ctx context.Context, key string) ([]byte, error) {
+	if v, ok := g.main.Cache.Get(key); ok {
+		return v.([]byte), nil
+	}
+	if v, ok := g.hot.Get(key); ok {
+		return v.([]byte), nil
+	}
+	g.lock.RLock()
+	owner := g.ring.owner(key)
+	g.lock.RUnlock()
+
+	if owner != "" && owner != g.self {
+		value, err := g.fetch(ctx, owner, key)
+		if err == nil {
+			if rand.IntN(replicateOneIn) == 0 {
+				g.hot.Add(key, value)
+			}
+			return value, nil
+		}
+		if errors.Is(err, ctx.Err()) {
+			return nil, err
+		}
+	}
+	return g.load(ctx, key)
+}
+
+// load loads a key locally, sharing the load with concurrent lookups. It
+// stops waiting for the load once ctx is done.
+func (g *Group) load(ctx context.Context, key string) ([]byte, error) {
+	v, err := g.main.LoadContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return v.([]byte), nil
+}
+
+// fetch asks a peer for the value of a key.
+func (g *Group) fetch(ctx context.Context, peer, key string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, peer+g.basePath+url.PathEscape(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer %s: %s", peer, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ServeHTTP serves the values of keys to the other peers, loading them
+// locally on a miss.
+func (g *Group) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	escaped, ok := strings.CutPrefix(r.URL.EscapedPath(), g.basePath)
+	if !ok || r.Method != http.MethodGet {
+		http.NotFound(w, r)
+		return
+	}
+	key, err := url.PathUnescape(escaped)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	value, err := g.load(r.Context(), key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(value)
+}
+
+// Close closes the caches of the group.
+func (g *Group) Close() error {
+	return errors.Join(g.main.Close(), g.hot.Close())
+}
@@ -0,0 +1,129 @@
+package lruishpeer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// cluster starts n peers sharing a loader, which counts the loads of each
+// peer.
+func cluster(t *testing.T, n int) ([]*Group, []map[string]int) {
+	t.Helper()
+	var (
+		lock   sync.Mutex
+		groups = make([]*Group, n)
+		loads  = make([]map[string]int, n)
+		urls   = make([]string, n)
+	)
+	for i := range groups {
+		mux := http.NewServeMux()
+		srv := httptest.NewServer(mux)
+		t.Cleanup(srv.Close)
+		urls[i] = srv.URL
+
+		i := i
+		loads[i] = make(map[string]int)
+		g, err := NewGroup(srv.URL, "/_lruish/", 64, func(key string) ([]byte, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			loads[i][key]++
+			return []byte("value of " + key), nil
+		})
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		mux.Handle("/_lruish/", g)
+		groups[i] = g
+	}
+	for _, g := range groups {
+		g.SetPeers(urls...)
+	}
+	return groups, loads
+}
+
+func TestGroupOwnership(t *testing.T) {
+	groups, loads := cluster(t, 3)
+	ctx := context.Background()
+	for _, g := range groups {
+		for k := 0; k < 30; k++ {
+			key := fmt.Sprintf("key/%d", k)
+			value, err := g.Get(ctx, key)
+			if err != nil || string(value) != "value of "+key {
+				t.Fatalf("bad value for %s: %q %v", key, value, err)
+			}
+		}
+	}
+	// Every key is loaded once, by its owner
+	owners := make(map[int]bool)
+	for k := 0; k < 30; k++ {
+		key := fmt.Sprintf("key/%d", k)
+		var total int
+		for i := range loads {
+			total += loads[i][key]
+			if loads[i][key] > 0 {
+				owners[i] = true
+			}
+		}
+		if total != 1 {
+			t.Fatalf("expected %s to be loaded once, got %d", key, total)
+		}
+	}
+	if len(owners) != 3 {
+		t.Fatalf("expected keys to be spread over all peers, owners %v", owners)
+	}
+}
+
+func TestGroupPeerDown(t *testing.T) {
+	groups, loads := cluster(t, 2)
+	groups[0].SetPeers(groups[0].self, "http://127.0.0.1:1")
+	for k := 0; k < 10; k++ {
+		key := fmt.Sprint(k)
+		if _, err := groups[0].Get(context.Background(), key); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if loads[0][key] != 1 {
+			t.Fatalf("keys of an unreachable peer should be loaded locally")
+		}
+	}
+}
+
+func TestGroupReplication(t *testing.T) {
+	groups, _ := cluster(t, 2)
+	var remote string
+	for k := 0; remote == ""; k++ {
+		if key := fmt.Sprint(k); groups[0].ring.owner(key) != groups[0].self {
+			remote = key
+		}
+	}
+	for i := 0; i < 200; i++ {
+		groups[0].Get(context.Background(), remote)
+	}
+	if !groups[0].hot.Contains(remote) {
+		t.Fatal("frequently fetched key should have been replicated")
+	}
+}
+
+func TestHashRing(t *testing.T) {
+	before := newHashRing([]string{"a", "b", "c"})
+	after := newHashRing([]string{"a", "b", "c", "d"})
+	var moved int
+	for k := 0; k < 1000; k++ {
+		key := fmt.Sprint(k)
+		if o := after.owner(key); o != before.owner(key) {
+			if o != "d" {
+				t.Fatalf("key %s moved between existing peers", key)
+			}
+			moved++
+		}
+	}
+	if moved < 100 || moved > 400 {
+		t.Fatalf("expected about a quarter of the keys to move, got %d", moved)
+	}
+	if newHashRing(nil).owner("x") != "" {
+		t.Fatal("empty ring should have no owner")
+	}
+}
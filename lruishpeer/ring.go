@@ -0,0 +1,65 @@
+package lruishpeer
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// virtualNodes is the number of points each peer takes on the hash ring, to
+// spread the keys evenly.
+const virtualNodes = 64
+
+// hashRing assigns keys to peers by consistent hashing, so that adding or
+// removing a peer only moves the keys of that peer.
+type hashRing struct {
+	points []uint64          // Sorted hashes of the virtual nodes
+	owners map[uint64]string // Peer of each virtual node
+}
+
+func newHashRing(peers []string) *hashRing {
+	r := &hashRing{owners: make(map[uint64]string, len(peers)*virtualNodes)}
+	for _, peer := range peers {
+		for i := 0; i < virtualNodes; i++ {
+			h := hash(strconv.Itoa(i) + peer)
+			r.points = append(r.points, h)
+			r.owners[h] = peer
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		return r.points[i] < r.points[j]
+	})
+	return r
+}
+
+// owner returns the peer owning the key, or the empty string if there are no
+// peers.
+func (r *hashRing) owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := hash(key)
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i] >= h
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+// hash is a hash function which is stable across processes, as all peers
+// have to agree on the owners of the keys.
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	// FNV barely mixes the last bytes into the high bits, so similar keys
+	// would land next to each other on the ring without a finalizer
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,70 @@
+package lruish
+
+import "sort"
+
+// Merge folds the entries of another cache into this one, for instance to
+// combine per-goroutine unsynchronized caches. The entries are added from the
+// least to the most recently used, so that the most recent ones of other end
+// up most recent here, and are the ones kept if they don't all fit. Keys
+// present in both caches get the value returned by conflict, called with the
+// value of this cache and that of other, or the value of other if conflict
+// is nil. Pinning, priorities and write times carry over.
+func (c *SynchedLRU) Merge(other Cache, conflict func(a, b interface{}) interface{}) {
+	// Read the other cache first, as it may share locks with this one
+	entries := other.Entries()
+
+	c.lock.Lock()
+	defer c.unlock()
+	merge(c.lru, entries, conflict)
+}
+
+// Merge folds the entries of another cache into this one. The entries are
+// added from the least to the most recently used, so that the most recent
+// ones of other end up most recent here, and are the ones kept if they don't
+// all fit. Keys present in both caches get the value returned by conflict,
+// called with the value of this cache and that of other, or the value of
+// other if conflict is nil. Pinning, priorities and write times carry over.
+func (c *lruish) Merge(other Cache, conflict func(a, b interface{}) interface{}) {
+	merge(c, other.Entries(), conflict)
+}
+
+// merge adds the entries, as returned by Entries, to the cache.
+func merge(c Cache, entries []EntryInfo, conflict func(a, b interface{}) interface{}) {
+	// Interleave the entries of striped caches by their recency
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Position < entries[j].Position
+	})
+	// Older entries would only be evicted by the newer ones
+	if len(entries) > c.Cap() {
+		entries = entries[:c.Cap()]
+	}
+	restorer, _ := c.(writtenRestorer)
+	for i := len(entries) - 1; i >= 0; i-- {
+		info := entries[i]
+		value := info.Value
+		if conflict != nil {
+			if existing, ok := c.Peek(info.Key); ok {
+				value = conflict(existing, value)
+			}
+		}
+		c.AddWithPriority(info.Key, value, info.Priority)
+		if info.Pinned {
+			c.Pin(info.Key)
+		}
+		if restorer != nil && !info.Written.IsZero() {
+			restorer.restoreWritten(info.Key, info.Written.UnixNano())
+		}
+	}
+}
+
+// Merge folds the entries of another cache into the namespace.
+func (n *namespace) Merge(other Cache, conflict func(a, b interface{}) interface{}) {
+	merge(n, other.Entries(), conflict)
+}
+
+// Merge folds the entries of another cache into the stripes. Unlike with an
+// unstriped cache, the merge is not atomic: concurrent lookups may observe
+// it half done.
+func (c *stripedLRU) Merge(other Cache, conflict func(a, b interface{}) interface{}) {
+	merge(c, other.Entries(), conflict)
+}
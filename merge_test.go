@@ -0,0 +1,69 @@
+package lruish
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	a, _ := NewUnsynched(4)
+	b, _ := NewUnsynched(4)
+	a.Add("x", 1)
+	a.Add("shared", 10)
+	b.Add("shared", 20)
+	b.Add("y", 2)
+	b.Pin("y")
+
+	dst, _ := New(8)
+	dst.Merge(a, nil)
+	dst.Merge(b, func(old, new interface{}) interface{} {
+		return old.(int) + new.(int)
+	})
+	want := map[interface{}]interface{}{"x": 1, "shared": 30, "y": 2}
+	if dst.Len() != len(want) {
+		t.Errorf("have %d entries, want %d", dst.Len(), len(want))
+	}
+	for k, v := range want {
+		if have, _ := dst.Peek(k); have != v {
+			t.Errorf("key %v: have %v, want %v", k, have, v)
+		}
+	}
+	// The most recent entries of the last merge are the most recent
+	if e := dst.Entries()[0]; e.Key != "y" || !e.Pinned {
+		t.Errorf("have head %+v, want pinned y", e)
+	}
+}
+
+func TestMergeCapacity(t *testing.T) {
+	src, _ := NewUnsynched(8)
+	for i := 0; i < 8; i++ {
+		src.Add(i, i)
+	}
+	dst, _ := New(3)
+	dst.Merge(src, nil)
+	var keys []interface{}
+	for _, e := range dst.Entries() {
+		keys = append(keys, e.Key)
+	}
+	if want := []interface{}{7, 6, 5}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("have %v, want %v", keys, want)
+	}
+}
+
+func TestMergeNamespaceAndStriped(t *testing.T) {
+	src, _ := New(16, WithStripes(2))
+	for i := 0; i < 4; i++ {
+		src.Add(i, i)
+	}
+	dst, _ := New(16, WithStripes(4))
+	ns := dst.Namespace("ns")
+	ns.Merge(src, nil)
+	for i := 0; i < 4; i++ {
+		if v, ok := ns.Get(i); !ok || v != i {
+			t.Errorf("key %d: have %v", i, v)
+		}
+		if dst.Contains(i) {
+			t.Errorf("key %d merged outside the namespace", i)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package lruish
+
+import (
+	"iter"
+	"strings"
+	"time"
+)
+
+// nsSep separates the prefixes of nested namespaces.
+const nsSep = "\x00"
+
+// nsKey is the key under which a namespaced entry is stored.
+type nsKey struct {
+	ns  string
+	key interface{}
+}
+
+// NamespaceStats holds the occupancy, quota and usage counters of a namespace.
+// Entries of nested namespaces are accounted to the nested namespace only.
+type NamespaceStats struct {
+	Len       int    // Number of entries, including expired ones not yet removed
+	Quota     int    // Maximum number of entries, or zero if unlimited
+	Hits      uint64 // Successful Gets
+	Misses    uint64 // Failed Gets
+	Evictions uint64 // Entries evicted to make room, due to capacity or quota
+}
+
+// namespace is a view on a cache, which stores its entries under keys scoped
+// to the namespace. It shares the capacity of the underlying cache.
+type namespace struct {
+	root Cache
+	ns   string
+}
+
+// Namespace returns a view on the cache which isolates its keys and tags from
+// those of the cache itself and of other namespaces, while sharing the cache's
+// capacity. All entries of a namespace can be dropped with PurgeNamespace.
+func (c *SynchedLRU) Namespace(prefix string) Cache {
+	return &namespace{root: c, ns: prefix}
+}
+
+// PurgeNamespace removes all entries of the given namespace, including those
+// of namespaces nested within it, returning how many were removed.
+func (c *SynchedLRU) PurgeNamespace(prefix string) int {
+	c.lock.Lock()
+	purged := c.lru.PurgeNamespace(prefix)
+	c.unlock()
+	c.publish(Invalidation{Namespace: prefix, All: true})
+	return purged
+}
+
+// Namespace returns a view on the cache which isolates its keys and tags from
+// those of the cache itself and of other namespaces, while sharing the cache's
+// capacity. All entries of a namespace can be dropped with PurgeNamespace.
+func (c *lruish) Namespace(prefix string) Cache {
+	return &namespace{root: c, ns: prefix}
+}
+
+// PurgeNamespace removes all entries of the given namespace, including those
+// of namespaces nested within it, returning how many were removed.
+func (c *lruish) PurgeNamespace(prefix string) int {
+	if c.frozen {
+		return 0
+	}
+	var purged int
+	for key, ent := range c.items {
+		if k, ok := key.(nsKey); ok && (k.ns == prefix || strings.HasPrefix(k.ns, prefix+nsSep)) {
+			c.removeElem(ent, ReasonPurged)
+			purged++
+		}
+	}
+	c.log(c.logLevels.Purge, "lruish: purged namespace", "namespace", prefix, "entries", purged)
+	return purged
+}
+
+// SetNamespaceQuota limits the namespace to the given fraction of the cache
+// capacity. Once a namespace reaches its quota, adding to it evicts its own
+// oldest entry instead of the cache's. A fraction of zero or one removes the
+// limit.
+func (c *SynchedLRU) SetNamespaceQuota(prefix string, fraction float64) {
+	c.lock.Lock()
+	defer c.unlock()
+	c.lru.SetNamespaceQuota(prefix, fraction)
+}
+
+// NamespaceStats returns the statistics of the given namespace.
+func (c *SynchedLRU) NamespaceStats(prefix string) NamespaceStats {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.NamespaceStats(prefix)
+}
+
+// SetNamespaceQuota limits the namespace to the given fraction of the cache
+// capacity. Once a namespace reaches its quota, adding to it evicts its own
+// oldest entry instead of the cache's. A fraction of zero or one removes the
+// limit.
+func (c *lruish) SetNamespaceQuota(prefix string, fraction float64) {
+	if c.frozen {
+		return
+	}
+	quota := 0
+	if fraction > 0 && fraction < 1 {
+		quota = max(1, int(fraction*float64(c.size)))
+	}
+	c.namespaceStats(prefix).Quota = quota
+}
+
+// NamespaceStats returns the statistics of the given namespace.
+func (c *lruish) NamespaceStats(prefix string) NamespaceStats {
+	if stats, ok := c.namespaces[prefix]; ok {
+		return *stats
+	}
+	return NamespaceStats{}
+}
+
+func (c *lruish) namespaceStats(ns string) *NamespaceStats {
+	if c.namespaces == nil {
+		c.namespaces = make(map[string]*NamespaceStats)
+	}
+	stats, ok := c.namespaces[ns]
+	if !ok {
+		stats = new(NamespaceStats)
+		c.namespaces[ns] = stats
+	}
+	return stats
+}
+
+func (c *lruish) overQuota(ns string) bool {
+	stats, ok := c.namespaces[ns]
+	return ok && stats.Quota > 0 && stats.Len >= stats.Quota
+}
+
+// evictFromNamespace evicts the oldest unpinned entry of the namespace, and
+// moves the entry at the tail index into the hole it leaves, so that adding
+// at the tail doesn't evict anything else. Returns false if no entry could
+// be evicted.
+func (c *lruish) evictFromNamespace(ns string, tail int) bool {
+	for pos := c.size - 1; pos >= 0; pos-- {
+		ent := c.ring[(c.head+pos)%c.size]
+		if ent == nil || ent.pinned {
+			continue
+		}
+		if k, ok := ent.key.(nsKey); !ok || k.ns != ns {
+			continue
+		}
+		hole := ent.index
+		c.ring[hole] = nil
+		c.evictElem(ent, ReasonCapacity)
+		c.countEviction(ent)
+		if moved := c.ring[tail]; moved != nil {
+			c.ring[tail], c.ring[hole] = nil, moved
+			moved.index = hole
+		}
+		return true
+	}
+	return false
+}
+
+func (c *lruish) countEviction(ent *lruElem) {
+	if k, ok := ent.key.(nsKey); ok {
+		c.namespaces[k.ns].Evictions++
+	}
+}
+
+func (c *lruish) countLookup(ns string, hit bool) {
+	stats := c.namespaceStats(ns)
+	if hit {
+		stats.Hits++
+	} else {
+		stats.Misses++
+	}
+}
+
+func (n *namespace) wrap(key interface{}) interface{} {
+	return nsKey{ns: n.ns, key: key}
+}
+
+func (n *namespace) wrapAll(keys []interface{}) []interface{} {
+	wrapped := make([]interface{}, len(keys))
+	for i, key := range keys {
+		wrapped[i] = n.wrap(key)
+	}
+	return wrapped
+}
+
+func (n *namespace) wrapTag(tag string) string {
+	return n.ns + nsSep + tag
+}
+
+// unwrap returns the key within the namespace, or false if the key belongs
+// to another namespace.
+func (n *namespace) unwrap(key interface{}) (interface{}, bool) {
+	if k, ok := key.(nsKey); ok && k.ns == n.ns {
+		return k.key, true
+	}
+	return nil, false
+}
+
+func (n *namespace) Add(key, value interface{}) bool {
+	return n.root.Add(n.wrap(key), value)
+}
+
+func (n *namespace) Get(key interface{}) (interface{}, bool) {
+	return n.root.Get(n.wrap(key))
+}
+
+func (n *namespace) GetStale(key interface{}) (interface{}, bool, bool) {
+	return n.root.GetStale(n.wrap(key))
+}
+
+func (n *namespace) GetWithExpiry(key interface{}) (interface{}, time.Time, bool) {
+	return n.root.GetWithExpiry(n.wrap(key))
+}
+
+func (n *namespace) Contains(key interface{}) bool {
+	return n.root.Contains(n.wrap(key))
+}
+
+func (n *namespace) Peek(key interface{}) (interface{}, bool) {
+	return n.root.Peek(n.wrap(key))
+}
+
+func (n *namespace) ContainsOrAdd(key, value interface{}) (bool, bool) {
+	return n.root.ContainsOrAdd(n.wrap(key), value)
+}
+
+func (n *namespace) Remove(key interface{}) bool {
+	return n.root.Remove(n.wrap(key))
+}
+
+func (n *namespace) GetAndRemove(key interface{}) (interface{}, bool) {
+	return n.root.GetAndRemove(n.wrap(key))
+}
+
+func (n *namespace) Swap(key, value interface{}) (interface{}, bool) {
+	return n.root.Swap(n.wrap(key), value)
+}
+
+func (n *namespace) AddTagged(key, value interface{}, tags ...string) bool {
+	wrapped := make([]string, len(tags))
+	for i, tag := range tags {
+		wrapped[i] = n.wrapTag(tag)
+	}
+	return n.root.AddTagged(n.wrap(key), value, wrapped...)
+}
+
+func (n *namespace) InvalidateTag(tag string) int {
+	return n.root.InvalidateTag(n.wrapTag(tag))
+}
+
+func (n *namespace) Pin(key interface{}) bool {
+	return n.root.Pin(n.wrap(key))
+}
+
+func (n *namespace) Unpin(key interface{}) bool {
+	return n.root.Unpin(n.wrap(key))
+}
+
+func (n *namespace) CompareAndSwap(key, old, new interface{}) bool {
+	return n.root.CompareAndSwap(n.wrap(key), old, new)
+}
+
+func (n *namespace) CompareAndDelete(key, old interface{}) bool {
+	return n.root.CompareAndDelete(n.wrap(key), old)
+}
+
+func (n *namespace) AddMany(keys, values []interface{}) []bool {
+	return n.root.AddMany(n.wrapAll(keys), values)
+}
+
+func (n *namespace) ContainsMany(keys []interface{}) []bool {
+	return n.root.ContainsMany(n.wrapAll(keys))
+}
+
+func (n *namespace) GetMany(keys []interface{}) ([]interface{}, []bool) {
+	return n.root.GetMany(n.wrapAll(keys))
+}
+
+func (n *namespace) RemoveMany(keys []interface{}) []bool {
+	return n.root.RemoveMany(n.wrapAll(keys))
+}
+
+func (n *namespace) Keys() []interface{} {
+	var keys []interface{}
+	for _, key := range n.root.Keys() {
+		if k, ok := n.unwrap(key); ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func (n *namespace) Range(fn func(key, value interface{}) bool) {
+	n.root.Range(func(key, value interface{}) bool {
+		if k, ok := n.unwrap(key); ok {
+			return fn(k, value)
+		}
+		return true
+	})
+}
+
+func (n *namespace) All() iter.Seq2[interface{}, interface{}] {
+	return n.Range
+}
+
+func (n *namespace) AllKeys() iter.Seq[interface{}] {
+	return keysOf(n.Range)
+}
+
+func (n *namespace) AllValues() iter.Seq[interface{}] {
+	return valuesOf(n.Range)
+}
+
+func (n *namespace) Len() int {
+	return n.root.NamespaceStats(n.ns).Len
+}
+
+// Clone returns the same namespace on a copy of the underlying cache.
+func (n *namespace) Clone() Cache {
+	return &namespace{root: n.root.Clone(), ns: n.ns}
+}
+
+// Close is a no-op, the underlying cache has to be closed by its owner.
+func (n *namespace) Close() error {
+	return nil
+}
+
+func (n *namespace) Namespace(prefix string) Cache {
+	return &namespace{root: n.root, ns: n.ns + nsSep + prefix}
+}
+
+func (n *namespace) PurgeNamespace(prefix string) int {
+	return n.root.PurgeNamespace(n.ns + nsSep + prefix)
+}
+
+func (n *namespace) SetNamespaceQuota(prefix string, fraction float64) {
+	n.root.SetNamespaceQuota(n.ns+nsSep+prefix, fraction)
+}
+
+func (n *namespace) NamespaceStats(prefix string) NamespaceStats {
+	return n.root.NamespaceStats(n.ns + nsSep + prefix)
+}
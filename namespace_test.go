@@ -0,0 +1,81 @@
+package lruish
+
+import "testing"
+
+func TestNamespace(t *testing.T) {
+	l, err := NewSynched(8)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	a, b := l.Namespace("a"), l.Namespace("b")
+	sub := a.Namespace("sub")
+	l.Add(1, "root")
+	a.Add(1, "a")
+	b.Add(1, "b")
+	sub.Add(1, "sub")
+	for _, tc := range []struct {
+		c    Cache
+		want string
+	}{{l, "root"}, {a, "a"}, {b, "b"}, {sub, "sub"}} {
+		if v, ok := tc.c.Get(1); !ok || v != tc.want {
+			t.Errorf("expected %v, got %v", tc.want, v)
+		}
+	}
+	if a.Len() != 1 || l.Len() != 4 {
+		t.Errorf("bad lengths: %d %d", a.Len(), l.Len())
+	}
+	if keys := a.Keys(); len(keys) != 1 || keys[0] != 1 {
+		t.Errorf("bad keys: %v", keys)
+	}
+	if n := l.PurgeNamespace("a"); n != 2 {
+		t.Errorf("expected namespace and sub-namespace to be purged, purged %d", n)
+	}
+	if a.Contains(1) || sub.Contains(1) || !b.Contains(1) || !l.Contains(1) {
+		t.Errorf("purge should only affect namespace a")
+	}
+}
+
+func TestNamespaceTags(t *testing.T) {
+	l, err := NewSynched(8)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	a, b := l.Namespace("a"), l.Namespace("b")
+	a.AddTagged(1, 1, "tag")
+	b.AddTagged(1, 1, "tag")
+	if n := a.InvalidateTag("tag"); n != 1 || !b.Contains(1) {
+		t.Errorf("tags should be scoped to the namespace")
+	}
+}
+
+func TestNamespaceQuota(t *testing.T) {
+	l, err := NewSynched(10)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.SetNamespaceQuota("noisy", 0.3)
+	quiet, noisy := l.Namespace("quiet"), l.Namespace("noisy")
+	for i := 0; i < 5; i++ {
+		quiet.Add(i, i)
+	}
+	for i := 0; i < 100; i++ {
+		noisy.Add(i, i)
+	}
+	if quiet.Len() != 5 {
+		t.Errorf("noisy namespace evicted quiet entries, %d left", quiet.Len())
+	}
+	stats := l.NamespaceStats("noisy")
+	if stats.Len != 3 || stats.Quota != 3 || stats.Evictions != 97 {
+		t.Errorf("bad stats: %+v", stats)
+	}
+	for i := 97; i < 100; i++ {
+		if !noisy.Contains(i) {
+			t.Errorf("newest noisy entry %d should be kept", i)
+		}
+	}
+	noisy.Get(99)
+	noisy.Get(0)
+	if stats := l.NamespaceStats("noisy"); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("bad lookup stats: %+v", stats)
+	}
+}
@@ -0,0 +1,45 @@
+package lruish
+
+import "errors"
+
+// ErrCacheFull is returned by TryAdd when a cache configured with
+// WithNoEviction has no room for a new entry.
+var ErrCacheFull = errors.New("lruish: cache full")
+
+// WithNoEviction turns the cache into a bounded registry: adding a new entry
+// to a full cache is rejected instead of evicting an existing one. Add reports
+// no eviction in that case, use TryAdd to find out whether the entry was
+// stored. Removing entries makes room again.
+func WithNoEviction(enabled bool) Option {
+	return func(c *config) {
+		c.noEviction = enabled
+	}
+}
+
+// TryAdd adds a value to the cache, returning ErrCacheFull if the cache has no
+// room for it, or ErrClosed if the cache is closed.
+func (c *SynchedLRU) TryAdd(key, value interface{}) error {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.TryAdd(key, value)
+}
+
+// TryAdd adds a value to the cache, returning ErrCacheFull if the cache has no
+// room for it, or ErrClosed if the cache is closed.
+func (c *lruish) TryAdd(key, value interface{}) error {
+	if c.closed {
+		return ErrClosed
+	}
+	if c.frozen {
+		return ErrFrozen
+	}
+	c.Add(key, value)
+	if _, ok := c.entry(key); !ok {
+		return ErrCacheFull
+	}
+	return nil
+}
+
+func (n *namespace) TryAdd(key, value interface{}) error {
+	return n.root.TryAdd(n.wrap(key), value)
+}
@@ -0,0 +1,31 @@
+package lruish
+
+import "testing"
+
+func TestNoEviction(t *testing.T) {
+	l, err := NewSynched(2, WithNoEviction(true))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := l.TryAdd(1, 1); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(2, 2)
+	if err := l.TryAdd(3, 3); err != ErrCacheFull {
+		t.Errorf("expected ErrCacheFull, got %v", err)
+	}
+	if err := l.TryAdd(1, 10); err != nil {
+		t.Errorf("updating an existing entry should succeed: %v", err)
+	}
+	if !l.Contains(1) || !l.Contains(2) || l.Contains(3) {
+		t.Errorf("no entry should have been evicted")
+	}
+	// Removing from the middle of the ring makes room again
+	l.Remove(2)
+	if err := l.TryAdd(3, 3); err != nil {
+		t.Errorf("err: %v", err)
+	}
+	if !l.Contains(1) || !l.Contains(3) {
+		t.Errorf("no entry should have been evicted")
+	}
+}
@@ -0,0 +1,143 @@
+package lruish
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Option configures optional behaviour of a cache at construction time.
+type Option func(*config)
+
+type config struct {
+	onEvict       func(key, value interface{})
+	onEvictReason func(key, value interface{}, reason EvictionReason)
+	closeOnEvict  bool
+	closeAsync    bool
+
+	clock Clock
+
+	expireAfterWrite  time.Duration
+	expireAfterAccess time.Duration
+	ttlJitter         float64
+	earlyRefreshBeta  float64
+
+	refreshAfter  time.Duration
+	refreshLoader func(key interface{}) (interface{}, error)
+	batchLoader   func(keys []interface{}) (map[interface{}]interface{}, error)
+	flushProgress func(written, remaining int)
+
+	purgeOnClose bool
+
+	hasher     Hasher
+	promotion  Promotion
+	costWindow int
+	noEviction bool
+
+	compressor    Compressor
+	compressAbove int
+	weakValues    bool
+	copyValues    bool
+	codec         Codec
+
+	watermark  float64
+	onPressure func(len, cap int)
+
+	evictQueue  int
+	eventBuffer int
+
+	stripes    int
+	readBuffer int
+
+	snapshotInterval time.Duration
+
+	ghosts  int
+	hotKeys int
+
+	invalidator Invalidator
+
+	sampleEvery    int
+	sampleCapacity int
+
+	versionCompare func(a, b interface{}) int
+
+	indexes        []indexConfig
+	trackValueKeys bool
+
+	logger     *slog.Logger
+	logLevels  LogLevels
+	leakWindow time.Duration
+	bloomBits  int
+
+	doorkeeperWindow time.Duration
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{clock: systemClock{}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithOnEvict sets a callback which receives the key and value of every entry
+// leaving the cache, through eviction, Remove or Purge. In synchronized caches
+// the callback is invoked while the cache lock is held.
+func WithOnEvict(onEvict func(key, value interface{})) Option {
+	return func(c *config) {
+		c.onEvict = onEvict
+	}
+}
+
+// WithCloseOnEvict makes the cache call Close on every value implementing
+// io.Closer which leaves the cache through eviction, Remove or Purge.
+func WithCloseOnEvict(enabled bool) Option {
+	return func(c *config) {
+		c.closeOnEvict = enabled
+	}
+}
+
+// WithCloseOnEvictAsync is like WithCloseOnEvict, but calls Close on a new
+// goroutine so that slow closers don't block the cache.
+func WithCloseOnEvictAsync(enabled bool) Option {
+	return func(c *config) {
+		c.closeOnEvict = enabled
+		c.closeAsync = enabled
+	}
+}
+
+// evictCallback assembles the callback to invoke when an entry leaves the
+// cache or its value is replaced, or nil if nothing needs to happen. Replaced
+// values are only reported to callbacks which receive the reason.
+func (c *config) evictCallback(background *sync.WaitGroup) func(key, value interface{}, reason EvictionReason) {
+	if c.onEvict == nil && c.onEvictReason == nil && !c.closeOnEvict {
+		return nil
+	}
+	onEvict, onEvictReason, closeOnEvict, async := c.onEvict, c.onEvictReason, c.closeOnEvict, c.closeAsync
+	return func(key, value interface{}, reason EvictionReason) {
+		if onEvictReason != nil {
+			onEvictReason(key, value, reason)
+		}
+		if reason == ReasonReplaced {
+			return
+		}
+		if onEvict != nil {
+			onEvict(key, value)
+		}
+		if !closeOnEvict {
+			return
+		}
+		if closer, ok := value.(io.Closer); ok {
+			if async {
+				background.Add(1)
+				go func() {
+					defer background.Done()
+					closer.Close()
+				}()
+			} else {
+				closer.Close()
+			}
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package lruish
+
+import "testing"
+
+func TestCloseOnEvict(t *testing.T) {
+	l, err := NewSynched(1, WithCloseOnEvict(true))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	a, b := new(testCloser), new(testCloser)
+	l.Add(1, a)
+	l.Add(2, b)
+	if a.closed != 1 {
+		t.Errorf("evicted value should have been closed")
+	}
+	l.Remove(2)
+	if b.closed != 1 {
+		t.Errorf("removed value should have been closed")
+	}
+}
@@ -0,0 +1,303 @@
+package lruish
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// persistVersion is the version of the snapshot format written by SaveTo.
+const persistVersion = 1
+
+// persistHeader starts a snapshot.
+type persistHeader struct {
+	Version int
+	Entries int
+}
+
+// persistEntry is an entry of a snapshot. Keys and values are encoded on
+// their own, so that a snapshot can be read without knowing their types.
+type persistEntry struct {
+	Namespace string
+	Key       []byte
+	KeyType   string
+	Value     []byte
+	ValueType string
+	Pinned    bool
+	Priority  Priority
+	Written   int64 // Unix nanoseconds, zero if not tracked
+}
+
+// SnapshotEntry is an entry read from a snapshot by ReadSnapshot.
+type SnapshotEntry struct {
+	Namespace string      // Namespace of the entry, empty if none
+	Key       interface{} // Nil if the key type isn't registered with gob
+	KeyType   string      // Type of the key, as formatted by %T
+	Value     interface{} // Nil if the value type isn't registered with gob
+	ValueType string      // Type of the value, as formatted by %T
+	Size      int         // Size of the encoded value, in bytes
+	Pinned    bool
+	Priority  Priority
+	Written   time.Time // Time of the last write, zero if not tracked
+}
+
+// SaveTo writes the entries of the cache to w, along with their priority,
+// pinning and write time, in a format LoadFrom reads back. Keys and values
+// are encoded with encoding/gob, so types other than the basic ones must be
+// registered with gob.Register. Saving a closed cache fails with ErrClosed.
+func SaveTo(c Cache, w io.Writer) error {
+	if isClosed(c) {
+		return ErrClosed
+	}
+	entries := c.Entries()
+	// Oldest first, so that loading them in order restores their recency
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Position > entries[j].Position
+	})
+	bw := bufio.NewWriter(w)
+	enc := gob.NewEncoder(bw)
+	if err := enc.Encode(persistHeader{Version: persistVersion, Entries: len(entries)}); err != nil {
+		return err
+	}
+	for _, info := range entries {
+		key := info.Key
+		ent := persistEntry{
+			Pinned:   info.Pinned,
+			Priority: info.Priority,
+		}
+		if k, ok := key.(nsKey); ok {
+			ent.Namespace, key = k.ns, k.key
+		}
+		var err error
+		if ent.Key, err = encodeInterface(key); err != nil {
+			return fmt.Errorf("lruish: encoding key %v: %w", key, err)
+		}
+		if ent.Value, err = encodeInterface(info.Value); err != nil {
+			return fmt.Errorf("lruish: encoding value of %v: %w", key, err)
+		}
+		ent.KeyType, ent.ValueType = fmt.Sprintf("%T", key), fmt.Sprintf("%T", info.Value)
+		if !info.Written.IsZero() {
+			ent.Written = info.Written.UnixNano()
+		}
+		if err := enc.Encode(&ent); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadSnapshot calls fn for each entry of a snapshot written by SaveTo, oldest
+// first, stopping at the first error returned by fn. Keys and values of types
+// not registered with gob are left nil, rather than failing the read.
+func ReadSnapshot(r io.Reader, fn func(SnapshotEntry) error) error {
+	dec := gob.NewDecoder(bufio.NewReader(r))
+	var header persistHeader
+	if err := dec.Decode(&header); err != nil {
+		return err
+	}
+	if header.Version != persistVersion {
+		return fmt.Errorf("lruish: unsupported snapshot version %d", header.Version)
+	}
+	for i := 0; i < header.Entries; i++ {
+		var ent persistEntry
+		if err := dec.Decode(&ent); err != nil {
+			return err
+		}
+		entry := SnapshotEntry{
+			Namespace: ent.Namespace,
+			KeyType:   ent.KeyType,
+			ValueType: ent.ValueType,
+			Size:      len(ent.Value),
+			Pinned:    ent.Pinned,
+			Priority:  ent.Priority,
+		}
+		entry.Key, _ = decodeInterface(ent.Key)
+		entry.Value, _ = decodeInterface(ent.Value)
+		if ent.Written != 0 {
+			entry.Written = time.Unix(0, ent.Written)
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFrom adds the entries written by SaveTo to the cache, most recently used
+// last. Entries keep the write time they had when saved, so that they expire
+// as if they had never left the cache. Loading fails on keys or values of
+// types not registered with gob, and with ErrClosed on a closed cache.
+func LoadFrom(c Cache, r io.Reader) error {
+	if isClosed(c) {
+		return ErrClosed
+	}
+	return ReadSnapshot(r, func(ent SnapshotEntry) error {
+		if ent.Key == nil || ent.Value == nil {
+			return fmt.Errorf("lruish: entry of type %s => %s not decodable", ent.KeyType, ent.ValueType)
+		}
+		key := ent.Key
+		if ent.Namespace != "" {
+			key = nsKey{ns: ent.Namespace, key: ent.Key}
+		}
+		c.AddWithPriority(key, ent.Value, ent.Priority)
+		if ent.Pinned {
+			c.Pin(key)
+		}
+		if !ent.Written.IsZero() {
+			if r, ok := c.(writtenRestorer); ok {
+				r.restoreWritten(key, ent.Written.UnixNano())
+			}
+		}
+		return nil
+	})
+}
+
+// encodeInterface encodes v on its own, along with its type.
+func encodeInterface(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeInterface(data []byte) (interface{}, error) {
+	var v interface{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// writtenRestorer is implemented by caches which can backdate the write time
+// of an entry restored from a snapshot.
+type writtenRestorer interface {
+	restoreWritten(key interface{}, written int64)
+}
+
+func (c *SynchedLRU) restoreWritten(key interface{}, written int64) {
+	c.lock.Lock()
+	defer c.unlock()
+	c.lru.restoreWritten(key, written)
+}
+
+func (c *lruish) restoreWritten(key interface{}, written int64) {
+	if ent, ok := c.entry(key); ok && c.timed() && !c.frozen {
+		ent.written = written
+	}
+}
+
+func (c *stripedLRU) restoreWritten(key interface{}, written int64) {
+	c.stripe(key).restoreWritten(key, written)
+}
+
+func (n *namespace) restoreWritten(key interface{}, written int64) {
+	if r, ok := n.root.(writtenRestorer); ok {
+		r.restoreWritten(n.wrap(key), written)
+	}
+}
+
+// SaveSnapshot writes the cache to the file at path with SaveTo. The file is
+// written to a temporary file first, which then replaces it atomically, so a
+// crash never leaves a partial snapshot behind.
+func SaveSnapshot(c Cache, path string, opts ...SnapshotOption) error {
+	cfg := newSnapshotConfig(opts)
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if cfg.key == nil {
+		err = SaveTo(c, tmp)
+	} else {
+		var buf bytes.Buffer
+		if err = SaveTo(c, &buf); err == nil {
+			err = EncryptSnapshot(tmp, &buf, cfg.key)
+		}
+	}
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// RestoreSnapshot loads the snapshot at path into the cache with LoadFrom. If
+// there is no snapshot, the returned error satisfies errors.Is(err,
+// fs.ErrNotExist).
+func RestoreSnapshot(c Cache, path string, opts ...SnapshotOption) error {
+	cfg := newSnapshotConfig(opts)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if cfg.key == nil {
+		return LoadFrom(c, f)
+	}
+	r, err := DecryptSnapshot(f, cfg.key)
+	if err != nil {
+		return err
+	}
+	return LoadFrom(c, r)
+}
+
+// StartSnapshotting saves the cache to the file at path with SaveSnapshot
+// every interval, as measured by the clock of the cache, until stop is
+// called. Stop saves a final snapshot, and returns the first error
+// encountered by any of the saves. It must be called before the cache is
+// closed, or the final save fails with ErrClosed.
+func StartSnapshotting(c Cache, path string, interval time.Duration, opts ...SnapshotOption) (stop func() error) {
+	var (
+		quit = make(chan struct{})
+		done = make(chan struct{})
+		lock sync.Mutex
+		err  error
+	)
+	save := func() {
+		e := SaveSnapshot(c, path, opts...)
+		lock.Lock()
+		defer lock.Unlock()
+		if err == nil {
+			err = e
+		}
+	}
+	go func() {
+		defer close(done)
+		clock := ClockOf(c)
+		for {
+			select {
+			case <-clock.After(interval):
+				save()
+			case <-quit:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() error {
+		once.Do(func() {
+			close(quit)
+			<-done
+			save()
+		})
+		lock.Lock()
+		defer lock.Unlock()
+		return err
+	}
+}
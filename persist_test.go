@@ -0,0 +1,141 @@
+package lruish
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveLoad(t *testing.T) {
+	src, _ := New(10)
+	src.Add("a", 1)
+	src.Add("b", "two")
+	src.AddWithPriority("c", []byte("three"), PriorityHigh)
+	src.Pin("a")
+	src.Namespace("ns").Add("d", 4)
+
+	var buf bytes.Buffer
+	if err := SaveTo(src, &buf); err != nil {
+		t.Fatal(err)
+	}
+	dst, _ := New(10)
+	if err := LoadFrom(dst, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := dst.Keys(), src.Keys(); len(have) != len(want) {
+		t.Fatalf("have keys %v, want %v", have, want)
+	}
+	for _, tc := range []struct {
+		key  interface{}
+		want interface{}
+	}{{"b", "two"}, {"a", 1}} {
+		if v, ok := dst.Peek(tc.key); !ok || v != tc.want {
+			t.Errorf("key %v: have %v, want %v", tc.key, v, tc.want)
+		}
+	}
+	if v, ok := dst.Peek("c"); !ok || string(v.([]byte)) != "three" {
+		t.Errorf("have %v, want three", v)
+	}
+	if v, ok := dst.Namespace("ns").Peek("d"); !ok || v != 4 {
+		t.Errorf("have %v, want 4", v)
+	}
+	have, want := dst.Entries(), src.Entries()
+	for i := range want {
+		if have[i].Key != want[i].Key || have[i].Pinned != want[i].Pinned || have[i].Priority != want[i].Priority {
+			t.Errorf("entry %d: have %+v, want %+v", i, have[i], want[i])
+		}
+	}
+}
+
+func TestLoadKeepsWriteTime(t *testing.T) {
+	clock := newFakeClock()
+	src, _ := New(10, WithExpireAfterWrite(time.Minute), WithClock(clock))
+	src.Add("a", 1)
+	clock.Advance(40 * time.Second)
+	src.Add("b", 2)
+
+	var buf bytes.Buffer
+	if err := SaveTo(src, &buf); err != nil {
+		t.Fatal(err)
+	}
+	dst, _ := New(10, WithExpireAfterWrite(time.Minute), WithClock(clock))
+	if err := LoadFrom(dst, &buf); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(30 * time.Second)
+	if _, ok := dst.Get("a"); ok {
+		t.Error("have a, want expired")
+	}
+	if _, ok := dst.Get("b"); !ok {
+		t.Error("missing b")
+	}
+}
+
+func TestSaveLoadStriped(t *testing.T) {
+	// Every stripe has room for all keys, however they are spread
+	src, _ := New(128, WithStripes(4))
+	for i := 0; i < 32; i++ {
+		src.Add(i, i*i)
+	}
+	var buf bytes.Buffer
+	if err := SaveTo(src, &buf); err != nil {
+		t.Fatal(err)
+	}
+	dst, _ := New(128, WithStripes(4))
+	if err := LoadFrom(dst, &buf); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 32; i++ {
+		if v, ok := dst.Peek(i); !ok || v != i*i {
+			t.Errorf("key %d: have %v, want %d", i, v, i*i)
+		}
+	}
+}
+
+func TestSnapshotFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	c, _ := New(10)
+	if err := RestoreSnapshot(c, path); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("have %v, want not exist", err)
+	}
+	stop := StartSnapshotting(c, path, time.Hour)
+	c.Add("a", 1)
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := os.ReadDir(filepath.Dir(path))
+	if len(files) != 1 {
+		t.Errorf("have %d files, want only the snapshot", len(files))
+	}
+
+	restored, _ := New(10)
+	if err := RestoreSnapshot(restored, path); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := restored.Get("a"); !ok || v != 1 {
+		t.Errorf("have %v, want 1", v)
+	}
+}
+
+func TestSnapshottingPeriodically(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	c, _ := New(10)
+	c.Add("a", 1)
+	stop := StartSnapshotting(c, path, time.Millisecond)
+	defer stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no snapshot written")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
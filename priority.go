@@ -0,0 +1,83 @@
+package lruish
+
+// Priority determines the order in which entries are evicted: as long as the
+// cache holds entries of a lower priority, entries of higher priority are not
+// evicted. Within a priority, eviction follows the ring as usual. A new entry
+// is not added to a cache full of entries of higher priority, as it would be
+// the first to go.
+type Priority int8
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0 // Priority of entries added with Add
+	PriorityHigh   Priority = 1
+
+	numPriorities = 3
+)
+
+// band returns the index of the priority in per-priority tables.
+func (p Priority) band() int {
+	return int(p - PriorityLow)
+}
+
+// AddWithPriority adds a value to the cache with the given priority, which
+// must be one of PriorityLow, PriorityNormal or PriorityHigh. If the key is
+// already cached, its priority is updated. A new entry gets its priority
+// before the victim is chosen. Returns true if an eviction occurred.
+func (c *SynchedLRU) AddWithPriority(key, value interface{}, priority Priority) bool {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.AddWithPriority(key, value, priority)
+}
+
+// AddWithPriority adds a value to the cache with the given priority, which
+// must be one of PriorityLow, PriorityNormal or PriorityHigh. If the key is
+// already cached, its priority is updated. A new entry gets its priority
+// before the victim is chosen. Returns true if an eviction occurred.
+func (c *lruish) AddWithPriority(key, value interface{}, priority Priority) bool {
+	if priority < PriorityLow || priority > PriorityHigh {
+		panic("lruish: invalid priority")
+	}
+	evicted := c.add(key, value, priority)
+	if ent, ok := c.entry(key); ok && !c.frozen && ent.priority != priority {
+		// The key was already cached, which involves no eviction
+		c.bands[ent.priority.band()]--
+		ent.priority = priority
+		c.bands[ent.priority.band()]++
+	}
+	return evicted
+}
+
+// lowestPriority returns the lowest priority of any entry in the cache.
+func (c *lruish) lowestPriority() Priority {
+	for band, count := range c.bands {
+		if count > 0 {
+			return Priority(band) + PriorityLow
+		}
+	}
+	return PriorityHigh
+}
+
+// skipPriority swaps the entry at the given tail index with the oldest entry
+// (or hole) of the lowest priority, so that one gets evicted instead.
+func (c *lruish) skipPriority(tail int) {
+	lowest := c.lowestPriority()
+	for i := 1; i < c.size; i++ {
+		index := tail - i
+		if index < 0 {
+			index += c.size
+		}
+		if ent := c.ring[index]; ent == nil || (ent.priority == lowest && !ent.pinned) {
+			if ent != nil {
+				ent.index = tail
+			}
+			c.ring[tail].index = index
+			c.ring[tail], c.ring[index] = c.ring[index], c.ring[tail]
+			return
+		}
+	}
+}
+
+func (n *namespace) AddWithPriority(key, value interface{}, priority Priority) bool {
+	return n.root.AddWithPriority(n.wrap(key), value, priority)
+}
@@ -0,0 +1,48 @@
+package lruish
+
+import "testing"
+
+func TestPriority(t *testing.T) {
+	l, err := NewSynched(4)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.AddWithPriority("high", 0, PriorityHigh)
+	l.Add("normal", 0)
+	l.AddWithPriority("low1", 0, PriorityLow)
+	l.AddWithPriority("low2", 0, PriorityLow)
+
+	// The low priority entries go first, oldest first, then normal ones
+	for i, want := range []string{"low1", "low2", "normal"} {
+		l.Add(i, i)
+		if l.Contains(want) {
+			t.Fatalf("%s should have been evicted", want)
+		}
+	}
+	if !l.Contains("high") {
+		t.Errorf("high priority entry should be kept")
+	}
+	l.Add(3, 3)
+	if !l.Contains("high") || l.Contains(0) {
+		t.Errorf("normal priority entries should keep being evicted first")
+	}
+}
+
+func TestPriorityAdmission(t *testing.T) {
+	l, _ := NewSynched(2)
+	l.Add("a", 0)
+	l.Add("b", 0)
+	// A low priority entry would be the first to go, so it doesn't displace
+	// the normal ones
+	if l.AddWithPriority("low", 0, PriorityLow) || l.Contains("low") || l.Len() != 2 {
+		t.Fatalf("low priority entry displaced a normal one: %v", l.Keys())
+	}
+	// A high priority one evicts the oldest normal one
+	if !l.AddWithPriority("high", 0, PriorityHigh) || l.Contains("a") || !l.Contains("high") {
+		t.Fatalf("high priority entry not added: %v", l.Keys())
+	}
+	l.AddWithPriority("b", 0, PriorityHigh)
+	if l.Add("c", 0) || l.Contains("c") {
+		t.Errorf("normal entry displaced a high priority one: %v", l.Keys())
+	}
+}
@@ -0,0 +1,134 @@
+package lruish
+
+import (
+	"encoding/json"
+	"hash/maphash"
+	"io"
+	"math/bits"
+	"sync"
+)
+
+// AccessProfile is an anonymized summary of the access pattern observed by a
+// Profiler. It contains no keys or values, only aggregate counts, and is
+// encoded as JSON like so:
+//
+//	{
+//	  "version": 1,          // schema version, bumped on incompatible change
+//	  "accesses": 1000,      // total number of Get and Add calls
+//	  "uniqueKeys": 300,     // number of distinct keys accessed
+//	  "scanFraction": 0.25,  // share of distinct keys accessed exactly once
+//	  "reuseDistance": [     // accesses elapsed between two uses of a key,
+//	    {"le": 1, "count": 10},  // bucketed by powers of two; "le" is the
+//	    {"le": 2, "count": 4},   // inclusive upper bound of the bucket
+//	    ...
+//	  ]
+//	}
+type AccessProfile struct {
+	Version       int           `json:"version"`
+	Accesses      uint64        `json:"accesses"`
+	UniqueKeys    int           `json:"uniqueKeys"`
+	ScanFraction  float64       `json:"scanFraction"`
+	ReuseDistance []ReuseBucket `json:"reuseDistance"`
+}
+
+// ReuseBucket is one bucket of the reuse-distance histogram.
+type ReuseBucket struct {
+	Le    uint64 `json:"le"`
+	Count uint64 `json:"count"`
+}
+
+// Profiler wraps a Cache and records a hashed trace summary of all Get and
+// Add calls. Keys are hashed with a random per-profiler seed, so the summary
+// cannot be correlated with the keys themselves.
+//
+// The profiler keeps one small record per distinct key ever seen, so it is
+// meant to be enabled for a limited period rather than left running.
+type Profiler struct {
+	Cache
+
+	lock      sync.Mutex
+	seed      maphash.Seed
+	accesses  uint64
+	keys      map[uint64]keyStat // hashed key -> usage record
+	seenOnce  int                // keys accessed exactly once so far
+	histogram [64]uint64         // reuse distances, bucketed by reuseBucket
+}
+
+type keyStat struct {
+	last   uint64 // access number of the last use
+	reused bool   // whether the key was accessed more than once
+}
+
+// NewProfiler wraps the given cache in a Profiler.
+func NewProfiler(c Cache) *Profiler {
+	return &Profiler{
+		Cache: c,
+		seed:  maphash.MakeSeed(),
+		keys:  make(map[uint64]keyStat),
+	}
+}
+
+// Add adds a value to the cache.  Returns true if an eviction occurred.
+func (p *Profiler) Add(key, value interface{}) bool {
+	p.record(key)
+	return p.Cache.Add(key, value)
+}
+
+// Get looks up a key's value from the cache.
+func (p *Profiler) Get(key interface{}) (value interface{}, ok bool) {
+	p.record(key)
+	return p.Cache.Get(key)
+}
+
+func (p *Profiler) record(key interface{}) {
+	h := maphash.Comparable(p.seed, key)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.accesses++
+	stat, ok := p.keys[h]
+	p.keys[h] = keyStat{last: p.accesses, reused: ok}
+	switch {
+	case !ok:
+		p.seenOnce++
+		return
+	case !stat.reused:
+		p.seenOnce--
+	}
+	p.histogram[reuseBucket(p.accesses-stat.last)]++
+}
+
+// reuseBucket returns the histogram bucket of a reuse distance, which is at
+// least 1. Bucket i holds the distances in (2^(i-1), 2^i], so its inclusive
+// upper bound is reported as its Le label: 1, 2, 3-4, 5-8 and so on.
+func reuseBucket(distance uint64) int {
+	return bits.Len64(distance - 1)
+}
+
+// Summary returns the access profile recorded so far.
+func (p *Profiler) Summary() *AccessProfile {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	profile := &AccessProfile{
+		Version:       1,
+		Accesses:      p.accesses,
+		UniqueKeys:    len(p.keys),
+		ReuseDistance: []ReuseBucket{},
+	}
+	if len(p.keys) > 0 {
+		profile.ScanFraction = float64(p.seenOnce) / float64(len(p.keys))
+	}
+	for i, count := range p.histogram {
+		if count > 0 {
+			profile.ReuseDistance = append(profile.ReuseDistance, ReuseBucket{Le: 1 << uint(i), Count: count})
+		}
+	}
+	return profile
+}
+
+// WriteJSON writes the JSON encoded access profile to w.
+func (p *Profiler) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(p.Summary())
+}
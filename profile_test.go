@@ -0,0 +1,76 @@
+package lruish
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestProfiler(t *testing.T) {
+	l, err := NewSynched(8)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	p := NewProfiler(l)
+	p.Add(1, 1)
+	p.Get(1) // distance 1
+	p.Add(2, 2)
+	p.Add(3, 3)
+	p.Get(1) // distance 3
+	p.Get(4)
+
+	var buf bytes.Buffer
+	if err := p.WriteJSON(&buf); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var profile AccessProfile
+	if err := json.Unmarshal(buf.Bytes(), &profile); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if profile.Accesses != 6 || profile.UniqueKeys != 4 {
+		t.Errorf("bad counts: %+v", profile)
+	}
+	if profile.ScanFraction != 0.75 {
+		t.Errorf("bad scan fraction: %v", profile.ScanFraction)
+	}
+	want := []ReuseBucket{{Le: 1, Count: 1}, {Le: 4, Count: 1}}
+	if len(profile.ReuseDistance) != len(want) {
+		t.Fatalf("bad histogram: %+v", profile.ReuseDistance)
+	}
+	for i := range want {
+		if profile.ReuseDistance[i] != want[i] {
+			t.Errorf("bucket %d: have %+v, want %+v", i, profile.ReuseDistance[i], want[i])
+		}
+	}
+}
+
+func TestProfilerBuckets(t *testing.T) {
+	tests := []struct {
+		distance uint64
+		bucket   int
+		le       uint64
+	}{
+		{1, 0, 1},
+		{2, 1, 2},
+		{3, 2, 4},
+		{4, 2, 4},
+		{5, 3, 8},
+	}
+	for _, tt := range tests {
+		if have := reuseBucket(tt.distance); have != tt.bucket {
+			t.Errorf("distance %d: have bucket %d, want %d", tt.distance, have, tt.bucket)
+		}
+		// Reuse a key after distance-1 other accesses
+		l, _ := NewSynched(8)
+		p := NewProfiler(l)
+		p.Get("key")
+		for i := uint64(1); i < tt.distance; i++ {
+			p.Get(i)
+		}
+		p.Get("key")
+		want := []ReuseBucket{{Le: tt.le, Count: 1}}
+		if have := p.Summary().ReuseDistance; len(have) != 1 || have[0] != want[0] {
+			t.Errorf("distance %d: have %+v, want %+v", tt.distance, have, want)
+		}
+	}
+}
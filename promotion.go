@@ -0,0 +1,51 @@
+package lruish
+
+import "math/rand/v2"
+
+// Promotion decides how far an accessed entry moves towards the head of the
+// ring. It is given the current position of the entry, counted from the head,
+// and returns the position to swap it with. Results outside [0, position] are
+// clamped.
+//
+// Moving an entry further makes the cache track recency more precisely, but
+// also demotes the entry it is swapped with further.
+type Promotion func(position int) int
+
+// PromoteHalfway moves an entry halfway to the head. This is the default.
+func PromoteHalfway(position int) int {
+	return position / 2
+}
+
+// PromoteToFront moves an entry all the way to the head, which is the closest
+// to strict LRU the ring can get.
+func PromoteToFront(position int) int {
+	return 0
+}
+
+// PromoteByFraction moves an entry the given fraction of the way to the head.
+// A fraction of 0.5 is the same as PromoteHalfway.
+func PromoteByFraction(fraction float64) Promotion {
+	return func(position int) int {
+		return position - int(fraction*float64(position))
+	}
+}
+
+// PromoteWithProbability applies the given promotion only with probability p,
+// and leaves the entry in place otherwise. This reduces the amount of swapping
+// for frequently accessed entries.
+func PromoteWithProbability(p float64, promotion Promotion) Promotion {
+	return func(position int) int {
+		if rand.Float64() < p {
+			return promotion(position)
+		}
+		return position
+	}
+}
+
+// WithPromotion sets how accessed entries move towards the head of the ring.
+// Defaults to PromoteHalfway.
+func WithPromotion(promotion Promotion) Option {
+	return func(c *config) {
+		c.promotion = promotion
+	}
+}
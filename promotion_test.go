@@ -0,0 +1,41 @@
+package lruish
+
+import "testing"
+
+// ringOrder returns the keys of the cache, most recently used first.
+func ringOrder(c Cache) []interface{} {
+	var keys []interface{}
+	c.Range(func(k, v interface{}) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+func TestPromotion(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		promotion Promotion
+		head      interface{} // key at the head after accessing the tail
+		tail      interface{} // key at the tail after accessing the tail
+	}{
+		{"halfway", PromoteHalfway, 7, 4},
+		{"front", PromoteToFront, 0, 7},
+		{"fraction", PromoteByFraction(0.75), 7, 5},
+		{"never", PromoteWithProbability(0, PromoteToFront), 7, 0},
+		{"always", PromoteWithProbability(1, PromoteToFront), 0, 7},
+	} {
+		l, err := NewUnsynched(8, WithPromotion(tc.promotion))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		for i := 0; i < 8; i++ {
+			l.Add(i, i)
+		}
+		l.Get(0)
+		order := ringOrder(l)
+		if order[0] != tc.head || order[7] != tc.tail {
+			t.Errorf("%s: bad order after promotion: %v", tc.name, order)
+		}
+	}
+}
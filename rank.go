@@ -0,0 +1,42 @@
+package lruish
+
+// PeekWithRank returns the key's value like Peek, along with its rank: the
+// distance of the entry from the head of the ring, 0 being the most recently
+// used. Entries are evicted from the tail, at rank Cap()-1, so the rank tells
+// how close an entry is to eviction. Holes left by removals count towards
+// the rank.
+func (c *SynchedLRU) PeekWithRank(key interface{}) (value interface{}, rank int, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.PeekWithRank(key)
+}
+
+// PeekWithRank returns the key's value like Peek, along with its rank: the
+// distance of the entry from the head of the ring, 0 being the most recently
+// used. Entries are evicted from the tail, at rank Cap()-1, so the rank tells
+// how close an entry is to eviction. Holes left by removals count towards
+// the rank.
+func (c *lruish) PeekWithRank(key interface{}) (value interface{}, rank int, ok bool) {
+	ent, ok := c.lookup(key)
+	if !ok {
+		return nil, 0, false
+	}
+	return c.unpack(ent.value), c.rank(ent), true
+}
+
+// rank returns the distance of the entry from the head of the ring.
+func (c *lruish) rank(ent *lruElem) int {
+	return (ent.index - c.head + c.size) % c.size
+}
+
+// PeekWithRank returns the key's value along with its rank in the ring of the
+// underlying cache.
+func (n *namespace) PeekWithRank(key interface{}) (value interface{}, rank int, ok bool) {
+	return n.root.PeekWithRank(n.wrap(key))
+}
+
+// PeekWithRank returns the key's value along with its rank within its stripe,
+// which is evicted from independently of the others.
+func (c *stripedLRU) PeekWithRank(key interface{}) (value interface{}, rank int, ok bool) {
+	return c.stripe(key).PeekWithRank(key)
+}
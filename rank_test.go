@@ -0,0 +1,41 @@
+package lruish
+
+import "testing"
+
+func TestPeekWithRank(t *testing.T) {
+	l, _ := New(4)
+	for i := 1; i <= 4; i++ {
+		l.Add(i, i*10)
+	}
+	// The last added is at the head, the first at the tail
+	for key, want := range map[int]int{4: 0, 3: 1, 2: 2, 1: 3} {
+		v, rank, ok := l.PeekWithRank(key)
+		if !ok || v != key*10 || rank != want {
+			t.Errorf("key %d: have %v, rank %d, %v; want rank %d", key, v, rank, ok, want)
+		}
+	}
+	// Peeking doesn't promote
+	if _, rank, _ := l.PeekWithRank(1); rank != 3 {
+		t.Errorf("have rank %d after peek, want 3", rank)
+	}
+	if _, _, ok := l.PeekWithRank(5); ok {
+		t.Error("5 is not cached")
+	}
+	// Ranks agree with the positions of Entries
+	l.Get(1)
+	for _, e := range l.Entries() {
+		if _, rank, _ := l.PeekWithRank(e.Key); rank != e.Position {
+			t.Errorf("key %v: have rank %d, position %d", e.Key, rank, e.Position)
+		}
+	}
+}
+
+func TestPeekWithRankNamespace(t *testing.T) {
+	l, _ := New(4)
+	ns := l.Namespace("ns")
+	ns.Add("a", 1)
+	l.Add("a", 2)
+	if v, rank, ok := ns.PeekWithRank("a"); !ok || v != 1 || rank != 1 {
+		t.Errorf("have %v, rank %d, %v; want 1 at rank 1", v, rank, ok)
+	}
+}
@@ -0,0 +1,70 @@
+package lruish
+
+import "sync/atomic"
+
+// WithBufferedPromotion makes Get on a synchronized cache take the read lock
+// only, recording the promotion of the entry in a buffer of the given size
+// instead of applying it right away. The buffer is applied once full, if the
+// write lock is free at that moment; otherwise the promotions are dropped.
+// This trades slightly stale recency for read throughput.
+//
+// Lookups within namespaces, and caches with a time-to-live or refreshing,
+// keep promoting under the write lock, as they update the entries.
+func WithBufferedPromotion(size int) Option {
+	return func(c *config) {
+		c.readBuffer = size
+	}
+}
+
+// readBuffer is a lossy buffer of entries to promote. Slots are claimed
+// round-robin, so concurrent readers overwrite each other's entries rather
+// than wait.
+type readBuffer struct {
+	slots []atomic.Pointer[lruElem]
+	next  atomic.Uint64
+}
+
+func newReadBuffer(size int) *readBuffer {
+	return &readBuffer{slots: make([]atomic.Pointer[lruElem], size)}
+}
+
+// record stores the entry in the buffer, returning true if it filled the
+// buffer up.
+func (b *readBuffer) record(ent *lruElem) bool {
+	n := b.next.Add(1)
+	b.slots[(n-1)%uint64(len(b.slots))].Store(ent)
+	return n%uint64(len(b.slots)) == 0
+}
+
+// getBuffered is Get under the read lock, with the promotion buffered.
+func (c *SynchedLRU) getBuffered(key interface{}) (interface{}, bool) {
+	c.lock.RLock()
+	ent, ok := c.lru.lookup(key)
+	c.lru.recordLookup(key, ok)
+	var value interface{}
+	if ok {
+		value = c.lru.unpack(ent.value)
+		atomic.AddUint64(&ent.hits, 1)
+		c.lru.emit(EventHit, key, value, 0)
+	} else {
+		c.lru.emit(EventMiss, key, nil, 0)
+	}
+	c.lock.RUnlock()
+
+	if ok && c.reads.record(ent) && c.lock.TryLock() {
+		c.applyReads()
+		c.unlock()
+	}
+	return value, ok
+}
+
+// applyReads promotes the buffered entries which are still cached. It must
+// be called with the write lock held.
+func (c *SynchedLRU) applyReads() {
+	for i := range c.reads.slots {
+		ent := c.reads.slots[i].Swap(nil)
+		if ent != nil && c.lru.items[ent.key] == ent {
+			c.lru.promote(ent)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package lruish
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestBufferedPromotion(t *testing.T) {
+	l, err := New(4, WithBufferedPromotion(2))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		l.Add(i, i)
+	}
+	// The oldest entry is saved by the promotions, once they are applied
+	l.Get(0)
+	if ringOrder(l)[3] != 0 {
+		t.Fatalf("promotion should be buffered, have %v", ringOrder(l))
+	}
+	l.Get(0)
+	if ringOrder(l)[3] == 0 {
+		t.Fatalf("promotions should be applied, have %v", ringOrder(l))
+	}
+	l.Add(4, 4)
+	if !l.Contains(0) {
+		t.Fatal("0 should have survived the eviction")
+	}
+}
+
+func TestBufferedPromotionConcurrent(t *testing.T) {
+	l, err := New(64, WithBufferedPromotion(16))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if i%10 == 0 {
+					l.Add(g*1000+i, i)
+				}
+				l.Get(g*1000 + i - i%10)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if l.Len() != 64 {
+		t.Fatalf("bad length %d", l.Len())
+	}
+}
@@ -0,0 +1,43 @@
+package lruish
+
+import "fmt"
+
+// EvictionReason tells why a value left the cache.
+type EvictionReason int
+
+const (
+	ReasonCapacity  EvictionReason = iota // Evicted to make room for another entry
+	ReasonExpired                         // Dropped after its expiry time passed
+	ReasonRemoved                         // Removed explicitly, or invalidated by tag
+	ReasonPurged                          // Dropped by Purge or PurgeNamespace
+	ReasonReplaced                        // Overwritten by a new value for the same key
+	ReasonCollected                       // Reclaimed by the garbage collector, see WithWeakValues
+)
+
+func (r EvictionReason) String() string {
+	switch r {
+	case ReasonCapacity:
+		return "capacity"
+	case ReasonExpired:
+		return "expired"
+	case ReasonRemoved:
+		return "removed"
+	case ReasonPurged:
+		return "purged"
+	case ReasonReplaced:
+		return "replaced"
+	case ReasonCollected:
+		return "collected"
+	}
+	return fmt.Sprintf("EvictionReason(%d)", int(r))
+}
+
+// WithOnEvictReason sets a callback which receives the key and value of every
+// entry leaving the cache along with the reason why. Unlike the WithOnEvict
+// callback, it is also invoked with ReasonReplaced for values overwritten by
+// Add, Swap or CompareAndSwap. It can be combined with WithOnEvict.
+func WithOnEvictReason(onEvict func(key, value interface{}, reason EvictionReason)) Option {
+	return func(c *config) {
+		c.onEvictReason = onEvict
+	}
+}
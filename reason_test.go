@@ -0,0 +1,65 @@
+package lruish
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvictionReason(t *testing.T) {
+	reasons := make(map[interface{}]EvictionReason)
+	var legacy []interface{}
+	l, err := NewUnsynched(2,
+		WithOnEvictReason(func(key, value interface{}, reason EvictionReason) {
+			reasons[value] = reason
+		}),
+		WithOnEvict(func(key, value interface{}) {
+			legacy = append(legacy, value)
+		}),
+		WithExpireAfterWrite(time.Hour),
+	)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, "a")
+	l.Add(1, "b") // replaces "a"
+	l.Add(2, "c")
+	l.Add(3, "d")       // evicts "b"
+	l.Remove(2)         // removes "c"
+	l.(*lruish).Purge() // purges "d"
+
+	want := map[interface{}]EvictionReason{
+		"a": ReasonReplaced,
+		"b": ReasonCapacity,
+		"c": ReasonRemoved,
+		"d": ReasonPurged,
+	}
+	for value, reason := range want {
+		if have := reasons[value]; have != reason {
+			t.Errorf("value %v: have reason %v, want %v", value, have, reason)
+		}
+	}
+	if len(legacy) != 3 {
+		t.Errorf("expected 3 legacy callbacks, got %v", legacy)
+	}
+}
+
+func TestEvictionReasonExpired(t *testing.T) {
+	var reason EvictionReason = -1
+	l, err := NewUnsynched(2,
+		WithOnEvictReason(func(key, value interface{}, r EvictionReason) {
+			reason = r
+		}),
+		WithExpireAfterWrite(time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := l.Get(1); ok {
+		t.Fatal("expected entry to expire")
+	}
+	if reason != ReasonExpired {
+		t.Errorf("have reason %v, want %v", reason, ReasonExpired)
+	}
+}
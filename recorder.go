@@ -0,0 +1,75 @@
+package lruish
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/maphash"
+	"io"
+	"sync"
+)
+
+// Trace operations, as recorded by a Recorder.
+const (
+	traceGet byte = 'g'
+	traceAdd byte = 'a'
+)
+
+// Recorder wraps a Cache and logs the sequence of Get and Add calls to a
+// writer, for offline simulation with Replay. Keys are recorded as hashes
+// with a random per-recorder seed, so the trace reveals no keys or values.
+//
+// Each call is encoded as an operation byte, 'g' or 'a', followed by the
+// 64-bit big-endian hash of the key. Writes are buffered; Flush must be
+// called to complete the trace.
+type Recorder struct {
+	Cache
+
+	lock sync.Mutex
+	seed maphash.Seed
+	w    *bufio.Writer
+	err  error // First write error, after which recording stops
+}
+
+// NewRecorder wraps the given cache in a Recorder writing to w.
+func NewRecorder(c Cache, w io.Writer) *Recorder {
+	return &Recorder{
+		Cache: c,
+		seed:  maphash.MakeSeed(),
+		w:     bufio.NewWriter(w),
+	}
+}
+
+// Add adds a value to the cache.  Returns true if an eviction occurred.
+func (r *Recorder) Add(key, value interface{}) bool {
+	r.record(traceAdd, key)
+	return r.Cache.Add(key, value)
+}
+
+// Get looks up a key's value from the cache.
+func (r *Recorder) Get(key interface{}) (value interface{}, ok bool) {
+	r.record(traceGet, key)
+	return r.Cache.Get(key)
+}
+
+func (r *Recorder) record(op byte, key interface{}) {
+	var buf [9]byte
+	buf[0] = op
+	binary.BigEndian.PutUint64(buf[1:], maphash.Comparable(r.seed, key))
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.err == nil {
+		_, r.err = r.w.Write(buf[:])
+	}
+}
+
+// Flush writes any buffered operations to the underlying writer, returning
+// the first error encountered while recording.
+func (r *Recorder) Flush() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	return r.err
+}
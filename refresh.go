@@ -0,0 +1,62 @@
+package lruish
+
+import "time"
+
+// WithRefreshAfterWrite makes the cache reload entries in the background once
+// the given duration has passed since they were written. The reload happens on
+// the first Get after the threshold, and the stale value keeps being served
+// until the loader returns. If the loader fails, the stale value is kept and
+// the next Get tries again.
+//
+// Refreshing is only supported by synchronized caches.
+func WithRefreshAfterWrite(after time.Duration, loader func(key interface{}) (interface{}, error)) Option {
+	return func(c *config) {
+		c.refreshAfter = after
+		c.refreshLoader = loader
+	}
+}
+
+// maybeRefresh starts a background reload of the entry if it is due.
+func (c *lruish) maybeRefresh(ent *lruElem) {
+	if c.refresh == nil || ent.refreshing {
+		return
+	}
+	if c.now()-ent.written < int64(c.refreshAfter) {
+		return
+	}
+	ent.refreshing = true
+	c.refresh(ent)
+}
+
+// refreshed stores the result of a background reload, unless the entry was
+// removed or replaced in the meantime.
+func (c *lruish) refreshed(ent *lruElem, value interface{}, err error) {
+	ent.refreshing = false
+	if err != nil || c.frozen || c.items[ent.key] != ent {
+		return
+	}
+	ent.value = c.pack(value)
+	if c.secondaries != nil {
+		c.unindexValue(ent)
+		c.indexValue(ent, value)
+	}
+	if c.valueKeys != nil {
+		c.untrackValue(ent)
+		c.trackValue(ent, value)
+	}
+	c.touch(ent, true)
+}
+
+// refresh reloads the entry on a new goroutine. It is called with the lock
+// held, and takes the lock again once the loader returns.
+func (c *SynchedLRU) refresh(ent *lruElem) {
+	c.lru.background.Add(1)
+	go func() {
+		defer c.lru.background.Done()
+		value, err := c.refreshLoader(ent.key)
+
+		c.lock.Lock()
+		defer c.unlock()
+		c.lru.refreshed(ent, value, err)
+	}()
+}
@@ -0,0 +1,39 @@
+package lruish
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRefreshAfterWrite(t *testing.T) {
+	loaded := make(chan struct{})
+	loader := func(key interface{}) (interface{}, error) {
+		<-loaded
+		return "fresh", nil
+	}
+	l, err := NewSynched(2, WithRefreshAfterWrite(10*time.Millisecond, loader))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, "stale")
+	time.Sleep(20 * time.Millisecond)
+	// The first Get triggers a refresh, but keeps serving the stale value
+	if v, _ := l.Get(1); v != "stale" {
+		t.Fatalf("expected stale value, got %v", v)
+	}
+	close(loaded)
+	for i := 0; i < 100; i++ {
+		if v, _ := l.Peek(1); v == "fresh" {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("value was not refreshed")
+}
+
+func TestRefreshUnsynched(t *testing.T) {
+	loader := func(key interface{}) (interface{}, error) { return nil, nil }
+	if _, err := NewUnsynched(2, WithRefreshAfterWrite(time.Second, loader)); err == nil {
+		t.Fatalf("unsynched cache should reject refreshing")
+	}
+}
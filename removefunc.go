@@ -0,0 +1,94 @@
+package lruish
+
+import "strings"
+
+// RemoveFunc removes all entries for which pred returns true, under a single
+// lock acquisition, returning how many were removed. The lock is held while
+// pred is called, so pred must not access the cache.
+func (c *SynchedLRU) RemoveFunc(pred func(key, value interface{}) bool) int {
+	removed := c.removeFunc(pred)
+	c.publishKeys(removed)
+	return len(removed)
+}
+
+// removeFunc is RemoveFunc, returning the removed keys without publishing
+// their invalidations.
+func (c *SynchedLRU) removeFunc(pred func(key, value interface{}) bool) []interface{} {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.removeFunc(pred)
+}
+
+// RemovePrefix removes all entries with a string key starting with prefix,
+// returning how many were removed.
+func (c *SynchedLRU) RemovePrefix(prefix string) int {
+	removed := c.removePrefix(prefix)
+	c.publishKeys(removed)
+	return len(removed)
+}
+
+// removePrefix is RemovePrefix, returning the removed keys without
+// publishing their invalidations.
+func (c *SynchedLRU) removePrefix(prefix string) []interface{} {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.removePrefix(prefix)
+}
+
+// RemoveFunc removes all entries for which pred returns true, returning how
+// many were removed.
+func (c *lruish) RemoveFunc(pred func(key, value interface{}) bool) int {
+	return len(c.removeFunc(pred))
+}
+
+// removeFunc removes all entries for which pred returns true, returning their
+// keys.
+func (c *lruish) removeFunc(pred func(key, value interface{}) bool) []interface{} {
+	if c.frozen {
+		return nil
+	}
+	var removed []interface{}
+	for key, ent := range c.items {
+		if !c.expired(ent) && pred(key, c.unpack(ent.value)) {
+			c.removeElem(ent, ReasonRemoved)
+			removed = append(removed, key)
+		}
+	}
+	return removed
+}
+
+// RemovePrefix removes all entries with a string key starting with prefix,
+// returning how many were removed.
+func (c *lruish) RemovePrefix(prefix string) int {
+	return len(c.removePrefix(prefix))
+}
+
+// removePrefix removes all entries with a string key starting with prefix,
+// returning their keys.
+func (c *lruish) removePrefix(prefix string) []interface{} {
+	if c.frozen {
+		return nil
+	}
+	var removed []interface{}
+	for key, ent := range c.items {
+		if s, ok := key.(string); ok && strings.HasPrefix(s, prefix) && !c.expired(ent) {
+			c.removeElem(ent, ReasonRemoved)
+			removed = append(removed, key)
+		}
+	}
+	return removed
+}
+
+func (n *namespace) RemoveFunc(pred func(key, value interface{}) bool) int {
+	return n.root.RemoveFunc(func(key, value interface{}) bool {
+		k, ok := n.unwrap(key)
+		return ok && pred(k, value)
+	})
+}
+
+func (n *namespace) RemovePrefix(prefix string) int {
+	return n.RemoveFunc(func(key, value interface{}) bool {
+		s, ok := key.(string)
+		return ok && strings.HasPrefix(s, prefix)
+	})
+}
@@ -0,0 +1,41 @@
+package lruish
+
+import "testing"
+
+func TestRemoveFunc(t *testing.T) {
+	l, err := NewSynched(8)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 0; i < 8; i++ {
+		l.Add(i, i)
+	}
+	if n := l.RemoveFunc(func(k, v interface{}) bool { return v.(int)%2 == 0 }); n != 4 {
+		t.Errorf("expected 4 removals, got %d", n)
+	}
+	if l.Len() != 4 || l.Contains(2) || !l.Contains(3) {
+		t.Errorf("wrong entries removed")
+	}
+}
+
+func TestRemovePrefix(t *testing.T) {
+	l, err := NewSynched(8)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add("user/1", 1)
+	l.Add("user/2", 2)
+	l.Add("group/1", 3)
+	l.Add(1, 4)
+	ns := l.Namespace("ns")
+	ns.Add("user/1", 5)
+	if n := l.RemovePrefix("user/"); n != 2 {
+		t.Errorf("expected 2 removals, got %d", n)
+	}
+	if !l.Contains("group/1") || !l.Contains(1) || !ns.Contains("user/1") {
+		t.Errorf("wrong entries removed")
+	}
+	if n := ns.RemovePrefix("user/"); n != 1 || ns.Contains("user/1") {
+		t.Errorf("namespaced entry should have been removed")
+	}
+}
@@ -0,0 +1,313 @@
+package lruish
+
+import (
+	"bufio"
+	"container/list"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Policy is a cache simulated by Replay. It only tracks which keys are
+// cached, not their values.
+type Policy interface {
+	// Name identifies the policy and its size in the results.
+	Name() string
+	// Get looks up the key, returning whether it's cached.
+	Get(key uint64) bool
+	// Add caches the key.
+	Add(key uint64)
+}
+
+// ReplayResult holds the outcome of replaying a trace against a policy.
+type ReplayResult struct {
+	Name    string
+	Gets    uint64
+	Hits    uint64
+	HitRate float64
+}
+
+// Replay feeds a trace written by a Recorder to each of the policies, and
+// reports their hit rates.
+func Replay(r io.Reader, policies ...Policy) ([]ReplayResult, error) {
+	results := make([]ReplayResult, len(policies))
+	for i, p := range policies {
+		results[i].Name = p.Name()
+	}
+	br := bufio.NewReader(r)
+	var buf [9]byte
+	for {
+		if _, err := io.ReadFull(br, buf[:]); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("truncated trace: %w", err)
+		}
+		key := binary.BigEndian.Uint64(buf[1:])
+		switch buf[0] {
+		case traceGet:
+			for i, p := range policies {
+				results[i].Gets++
+				if p.Get(key) {
+					results[i].Hits++
+				}
+			}
+		case traceAdd:
+			for _, p := range policies {
+				p.Add(key)
+			}
+		default:
+			return nil, errors.New("invalid trace operation")
+		}
+	}
+	for i := range results {
+		if results[i].Gets > 0 {
+			results[i].HitRate = float64(results[i].Hits) / float64(results[i].Gets)
+		}
+	}
+	return results, nil
+}
+
+// lruishPolicy simulates the caches of this package.
+type lruishPolicy struct {
+	name string
+	lru  Cache
+}
+
+// LruishPolicy simulates a cache of this package of the given size, with
+// optional features configured through opts.
+func LruishPolicy(size int, opts ...Option) (Policy, error) {
+	lru, err := NewUnsynched(size, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &lruishPolicy{name: fmt.Sprintf("lruish(%d)", size), lru: lru}, nil
+}
+
+func (p *lruishPolicy) Name() string {
+	return p.name
+}
+
+func (p *lruishPolicy) Get(key uint64) bool {
+	_, ok := p.lru.Get(key)
+	return ok
+}
+
+func (p *lruishPolicy) Add(key uint64) {
+	p.lru.Add(key, nil)
+}
+
+// lruList is an exact LRU list of keys, used by the reference policies.
+type lruList struct {
+	order *list.List // most recent first
+	items map[uint64]*list.Element
+}
+
+func newLRUList() *lruList {
+	return &lruList{order: list.New(), items: make(map[uint64]*list.Element)}
+}
+
+func (l *lruList) contains(key uint64) bool {
+	_, ok := l.items[key]
+	return ok
+}
+
+func (l *lruList) len() int {
+	return l.order.Len()
+}
+
+// push adds the key as the most recent one, or moves it there.
+func (l *lruList) push(key uint64) {
+	if e, ok := l.items[key]; ok {
+		l.order.MoveToFront(e)
+		return
+	}
+	l.items[key] = l.order.PushFront(key)
+}
+
+func (l *lruList) remove(key uint64) bool {
+	e, ok := l.items[key]
+	if ok {
+		l.order.Remove(e)
+		delete(l.items, key)
+	}
+	return ok
+}
+
+// pop removes and returns the least recent key.
+func (l *lruList) pop() uint64 {
+	e := l.order.Back()
+	key := l.order.Remove(e).(uint64)
+	delete(l.items, key)
+	return key
+}
+
+// lruPolicy simulates an exact LRU cache.
+type lruPolicy struct {
+	size int
+	keys *lruList
+}
+
+// LRUPolicy simulates an exact LRU cache of the given size.
+func LRUPolicy(size int) Policy {
+	return &lruPolicy{size: size, keys: newLRUList()}
+}
+
+func (p *lruPolicy) Name() string {
+	return fmt.Sprintf("lru(%d)", p.size)
+}
+
+func (p *lruPolicy) Get(key uint64) bool {
+	if !p.keys.contains(key) {
+		return false
+	}
+	p.keys.push(key)
+	return true
+}
+
+func (p *lruPolicy) Add(key uint64) {
+	p.keys.push(key)
+	if p.keys.len() > p.size {
+		p.keys.pop()
+	}
+}
+
+// twoQPolicy simulates the full 2Q algorithm: new keys enter a FIFO, keys
+// evicted from it are remembered in a ghost queue, and keys seen again while
+// remembered go to the main LRU queue.
+type twoQPolicy struct {
+	size          int
+	recent        *lruList // A1in, used as a FIFO
+	ghosts        *lruList // A1out
+	frequent      *lruList // Am
+	recentSize    int
+	ghostCapacity int
+}
+
+// TwoQPolicy simulates a 2Q cache of the given size, with the recommended
+// quarter of the capacity for new keys, and ghosts for half the capacity.
+func TwoQPolicy(size int) Policy {
+	return &twoQPolicy{
+		size:          size,
+		recent:        newLRUList(),
+		ghosts:        newLRUList(),
+		frequent:      newLRUList(),
+		recentSize:    max(1, size/4),
+		ghostCapacity: max(1, size/2),
+	}
+}
+
+func (p *twoQPolicy) Name() string {
+	return fmt.Sprintf("2q(%d)", p.size)
+}
+
+func (p *twoQPolicy) Get(key uint64) bool {
+	if p.frequent.contains(key) {
+		p.frequent.push(key)
+		return true
+	}
+	// Hits in the FIFO don't change its order
+	return p.recent.contains(key)
+}
+
+func (p *twoQPolicy) Add(key uint64) {
+	switch {
+	case p.frequent.contains(key):
+		p.frequent.push(key)
+		return
+	case p.recent.contains(key):
+		return
+	case p.ghosts.remove(key):
+		p.makeRoom()
+		p.frequent.push(key)
+	default:
+		p.makeRoom()
+		p.recent.push(key)
+	}
+}
+
+func (p *twoQPolicy) makeRoom() {
+	if p.recent.len()+p.frequent.len() < p.size {
+		return
+	}
+	if p.recent.len() >= p.recentSize || p.frequent.len() == 0 {
+		p.ghosts.push(p.recent.pop())
+		if p.ghosts.len() > p.ghostCapacity {
+			p.ghosts.pop()
+		}
+		return
+	}
+	p.frequent.pop()
+}
+
+// arcPolicy simulates the Adaptive Replacement Cache.
+type arcPolicy struct {
+	size           int
+	target         int // Adaptive target size of t1
+	t1, t2, b1, b2 *lruList
+}
+
+// ARCPolicy simulates an ARC cache of the given size.
+func ARCPolicy(size int) Policy {
+	return &arcPolicy{
+		size: size,
+		t1:   newLRUList(),
+		t2:   newLRUList(),
+		b1:   newLRUList(),
+		b2:   newLRUList(),
+	}
+}
+
+func (p *arcPolicy) Name() string {
+	return fmt.Sprintf("arc(%d)", p.size)
+}
+
+func (p *arcPolicy) Get(key uint64) bool {
+	if p.t1.remove(key) || p.t2.contains(key) {
+		p.t2.push(key)
+		return true
+	}
+	return false
+}
+
+func (p *arcPolicy) Add(key uint64) {
+	switch {
+	case p.t1.remove(key) || p.t2.contains(key):
+		p.t2.push(key)
+	case p.b1.contains(key):
+		p.target = min(p.size, p.target+max(1, p.b2.len()/p.b1.len()))
+		p.replace(false)
+		p.b1.remove(key)
+		p.t2.push(key)
+	case p.b2.contains(key):
+		p.target = max(0, p.target-max(1, p.b1.len()/p.b2.len()))
+		p.replace(true)
+		p.b2.remove(key)
+		p.t2.push(key)
+	default:
+		if p.t1.len()+p.b1.len() == p.size {
+			if p.t1.len() < p.size {
+				p.b1.pop()
+				p.replace(false)
+			} else {
+				p.t1.pop()
+			}
+		} else if total := p.t1.len() + p.t2.len() + p.b1.len() + p.b2.len(); total >= p.size {
+			if total == 2*p.size {
+				p.b2.pop()
+			}
+			p.replace(false)
+		}
+		p.t1.push(key)
+	}
+}
+
+// replace evicts from t1 or t2 into their ghost lists, depending on the
+// target size of t1.
+func (p *arcPolicy) replace(inB2 bool) {
+	if p.t1.len() > 0 && (p.t1.len() > p.target || (inB2 && p.t1.len() == p.target)) {
+		p.b1.push(p.t1.pop())
+	} else if p.t2.len() > 0 {
+		p.b2.push(p.t2.pop())
+	}
+}
@@ -0,0 +1,57 @@
+package lruish
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	var trace bytes.Buffer
+	l, _ := New(64)
+	r := NewRecorder(l, &trace)
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		// A hot set of 32 keys, mixed with a scan of cold ones
+		key := i
+		if i%2 == 0 {
+			key = rng.Intn(32)
+		}
+		if _, ok := r.Get(key); !ok {
+			r.Add(key, nil)
+		}
+	}
+	if err := r.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if trace.Len() == 0 || trace.Len()%9 != 0 {
+		t.Fatalf("bad trace length %d", trace.Len())
+	}
+	lruish, _ := LruishPolicy(64)
+	results, err := Replay(&trace, lruish, LRUPolicy(64), TwoQPolicy(64), ARCPolicy(64), LRUPolicy(1))
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if results[0].Name != "lruish(64)" || results[0].Gets != 10000 {
+		t.Fatalf("bad result %+v", results[0])
+	}
+	// The replayed lruish policy sees the same hits as the recorded cache
+	if want := l.Stats().Hits; results[0].Hits != want {
+		t.Fatalf("replayed %d hits, recorded %d", results[0].Hits, want)
+	}
+	for _, res := range results[:4] {
+		if res.HitRate < 0.3 || res.HitRate > 0.5 {
+			t.Errorf("%s: unexpected hit rate %v", res.Name, res.HitRate)
+		}
+	}
+	if results[4].HitRate > 0.1 {
+		t.Errorf("tiny cache should rarely hit, got %v", results[4].HitRate)
+	}
+}
+
+func TestReplayTruncated(t *testing.T) {
+	if _, err := Replay(bytes.NewReader([]byte{'g', 1, 2})); err == nil {
+		t.Fatal("expected error for truncated trace")
+	}
+}
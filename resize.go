@@ -0,0 +1,68 @@
+package lruish
+
+// Resize changes the capacity of the cache, evicting the oldest entries if
+// they no longer fit. Pinned entries are only evicted if they alone exceed
+// the new capacity. Quotas and watermarks scale with the capacity. Returns
+// the number of evicted entries.
+func (c *SynchedLRU) Resize(size int) (evicted int) {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.Resize(size)
+}
+
+// Resize changes the capacity of the cache, evicting the oldest entries if
+// they no longer fit. Pinned entries are only evicted if they alone exceed
+// the new capacity. Quotas and watermarks scale with the capacity. Returns
+// the number of evicted entries.
+func (c *lruish) Resize(size int) (evicted int) {
+	if size <= 0 {
+		panic("lruish: non-positive size")
+	}
+	if c.closed || c.frozen || size == c.size {
+		return 0
+	}
+	c.Compact()
+	// Entries now occupy the first len(c.items) positions after the head
+	for _, keepPinned := range []bool{true, false} {
+		for pos := len(c.items) - 1; pos >= 0 && len(c.items) > size; pos-- {
+			ent := c.ring[(c.head+pos)%c.size]
+			if ent == nil || (ent.pinned && keepPinned) {
+				continue
+			}
+			c.removeElem(ent, ReasonCapacity)
+			c.countEviction(ent)
+			evicted++
+		}
+	}
+	ring := make([]*lruElem, size)
+	next := 0
+	for pos := 0; pos < c.size; pos++ {
+		if ent := c.ring[(c.head+pos)%c.size]; ent != nil {
+			ring[next] = ent
+			ent.index = next
+			next++
+		}
+	}
+	scale := func(n int) int {
+		if n == 0 {
+			return 0
+		}
+		return max(1, n*size/c.size)
+	}
+	for _, stats := range c.namespaces {
+		stats.Quota = scale(stats.Quota)
+	}
+	c.watermark = scale(c.watermark)
+	c.pressured = c.watermark > 0 && len(c.items) >= c.watermark
+	c.log(c.logLevels.Resize, "lruish: resized", "from", c.size, "to", size, "evicted", evicted)
+	c.ring, c.size, c.head = ring, size, 0
+	if c.bloom != nil {
+		c.rebuildBloom()
+	}
+	return evicted
+}
+
+// Resize resizes the underlying cache, which the namespace shares.
+func (n *namespace) Resize(size int) int {
+	return n.root.Resize(size)
+}
@@ -0,0 +1,81 @@
+package lruish
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestResize(t *testing.T) {
+	l, err := New(4)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		l.Add(i, i)
+	}
+	l.Pin(0)
+	if n := l.Resize(2); n != 2 {
+		t.Fatalf("expected 2 evictions, got %d", n)
+	}
+	if l.Cap() != 2 || l.Len() != 2 {
+		t.Fatalf("bad capacity %d or length %d", l.Cap(), l.Len())
+	}
+	// The oldest unpinned entries go first
+	if !l.Contains(0) || !l.Contains(3) {
+		t.Fatalf("wrong entries evicted, have %v", l.Keys())
+	}
+	if n := l.Resize(8); n != 0 {
+		t.Fatalf("growing should not evict, got %d", n)
+	}
+	for i := 10; i < 16; i++ {
+		if l.Add(i, i) {
+			t.Fatalf("add %d should fill a free slot", i)
+		}
+	}
+	if l.Len() != 8 {
+		t.Fatalf("expected a full cache, have %d", l.Len())
+	}
+}
+
+func TestResizeConcurrentCap(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithStripes(4)}} {
+		l, _ := New(64, opts...)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				l.Resize(32 + i%32)
+			}
+		}()
+		for i := 0; i < 100; i++ {
+			if n := l.Cap(); n < 32 || n > 64 {
+				t.Errorf("bad capacity %d", n)
+			}
+		}
+		wg.Wait()
+	}
+}
+
+func TestTuner(t *testing.T) {
+	l, err := New(100)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		l.Add(i, make([]byte, 1000))
+	}
+	tuner := NewTuner(l, FixedBudget(50*1000), 10, 1000, 0)
+	defer tuner.Stop()
+
+	if size := tuner.Tune(); size >= 50 || size < 10 {
+		t.Fatalf("expected to shrink below 50, got %d", size)
+	}
+	if l.SizeBytes() > 50*1000 {
+		t.Fatalf("cache exceeds its budget: %d", l.SizeBytes())
+	}
+	tuner.budget = FixedBudget(0)
+	if size := tuner.Tune(); size != 1000 {
+		t.Fatalf("expected to grow to the maximum, got %d", size)
+	}
+}
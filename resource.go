@@ -0,0 +1,145 @@
+package lruish
+
+import (
+	"io"
+	"sync"
+)
+
+// ResourceCache is a thread-safe cache for values which hold resources, such
+// as connections or open files. Values are handed out through reference
+// counted handles, and a value which leaves the cache is only released once
+// the last handle to it has been released.
+type ResourceCache struct {
+	lru         *lruish
+	lock        sync.Mutex
+	closeValues bool
+	released    []*resource // resources to finalize once the lock is dropped
+}
+
+// resource is the reference counted wrapper around a cached value.
+type resource struct {
+	value   interface{}
+	refs    int
+	evicted bool
+}
+
+// Handle gives access to a value in a ResourceCache. The value is guaranteed
+// to stay alive until Release is called.
+type Handle struct {
+	cache    *ResourceCache
+	res      *resource
+	released bool
+}
+
+// NewResourceCache creates a thread-safe resource cache of the given size. If
+// closeValues is set, values implementing io.Closer are closed once they have
+// left the cache and are no longer referenced.
+func NewResourceCache(size int, closeValues bool) (*ResourceCache, error) {
+	c := &ResourceCache{closeValues: closeValues}
+	lru, err := newLruish(size, newConfig([]Option{WithOnEvict(c.onEvict)}))
+	if err != nil {
+		return nil, err
+	}
+	c.lru = lru
+	return c, nil
+}
+
+// onEvict marks the resource as no longer cached, and schedules it to be
+// released if nobody holds a reference to it.
+func (c *ResourceCache) onEvict(key, value interface{}) {
+	c.evict(value.(*resource))
+}
+
+func (c *ResourceCache) evict(res *resource) {
+	res.evicted = true
+	if res.refs == 0 {
+		c.released = append(c.released, res)
+	}
+}
+
+// unlock drops the lock and finalizes any resources released while it was
+// held, so slow Close calls don't stall other users of the cache.
+func (c *ResourceCache) unlock() {
+	released := c.released
+	c.released = nil
+	c.lock.Unlock()
+
+	if !c.closeValues {
+		return
+	}
+	for _, res := range released {
+		if closer, ok := res.value.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+// Add adds a value to the cache.  Returns true if an eviction occurred. A
+// value replaced by Add is released like an evicted one.
+func (c *ResourceCache) Add(key, value interface{}) bool {
+	c.lock.Lock()
+	defer c.unlock()
+
+	if ent, ok := c.lru.items[key]; ok {
+		c.evict(ent.value.(*resource))
+	}
+	return c.lru.Add(key, &resource{value: value})
+}
+
+// Get looks up a key's value from the cache, returning a handle to it. The
+// handle must be released once the caller is done with the value.
+func (c *ResourceCache) Get(key interface{}) (*Handle, bool) {
+	c.lock.Lock()
+	defer c.unlock()
+
+	v, ok := c.lru.Get(key)
+	if !ok {
+		return nil, false
+	}
+	res := v.(*resource)
+	res.refs++
+	return &Handle{cache: c, res: res}, true
+}
+
+// Remove removes the provided key from the cache, returning if the
+// key was contained.
+func (c *ResourceCache) Remove(key interface{}) bool {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.Remove(key)
+}
+
+// Purge is used to completely clear the cache
+func (c *ResourceCache) Purge() {
+	c.lock.Lock()
+	defer c.unlock()
+	c.lru.Purge()
+}
+
+// Len returns the number of items in the cache.
+func (c *ResourceCache) Len() int {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.Len()
+}
+
+// Value returns the value the handle refers to.
+func (h *Handle) Value() interface{} {
+	return h.res.value
+}
+
+// Release drops the reference held by the handle. Releasing a handle more
+// than once has no effect.
+func (h *Handle) Release() {
+	c := h.cache
+	c.lock.Lock()
+	defer c.unlock()
+
+	if h.released {
+		return
+	}
+	h.released = true
+	if h.res.refs--; h.res.refs == 0 && h.res.evicted {
+		c.released = append(c.released, h.res)
+	}
+}
@@ -0,0 +1,38 @@
+package lruish
+
+import "testing"
+
+type testCloser struct {
+	closed int
+}
+
+func (c *testCloser) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestResourceCache(t *testing.T) {
+	c, err := NewResourceCache(1, true)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	a, b := new(testCloser), new(testCloser)
+	c.Add("a", a)
+	h, ok := c.Get("a")
+	if !ok || h.Value() != a {
+		t.Fatalf("a should be contained")
+	}
+	c.Add("b", b)
+	if a.closed != 0 {
+		t.Fatalf("a closed while still referenced")
+	}
+	h.Release()
+	h.Release()
+	if a.closed != 1 {
+		t.Fatalf("a should be closed once after release, closed %d times", a.closed)
+	}
+	c.Remove("b")
+	if b.closed != 1 {
+		t.Fatalf("b should be closed after removal")
+	}
+}
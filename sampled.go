@@ -0,0 +1,177 @@
+package lruish
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultSamples is the number of entries SampledCache compares to pick the
+// one to evict, unless told otherwise.
+const DefaultSamples = 5
+
+// SampledCache is a thread-safe fixed size cache which approximates LRU the
+// way Redis does: every entry only carries the time of its last access, and
+// an eviction removes the least recently used of a few randomly sampled
+// entries. Get does no promotion work, it only stores the access time of the
+// entry, so lookups share the read lock and never contend with each other.
+// The price is accuracy: the evicted entry is only the oldest of the sample,
+// not of the whole cache.
+//
+// Access times are counted in writes rather than wall time: entries read
+// between the same two writes are considered equally recent.
+type SampledCache struct {
+	lock    sync.RWMutex
+	size    int
+	samples int
+	items   map[interface{}]*sampledElem
+	elems   []*sampledElem // The entries, densely packed for sampling
+	tick    atomic.Int64   // Number of writes so far
+}
+
+type sampledElem struct {
+	key      interface{}
+	value    interface{}
+	index    int          // Position in elems
+	accessed atomic.Int64 // Tick of the last access
+}
+
+// NewSampledCache creates a multi-thread safe cache of the given size, which
+// evicts the least recently used of the given number of sampled entries. A
+// non-positive number of samples selects DefaultSamples. More samples make
+// eviction more accurate, at the cost of slower writes.
+func NewSampledCache(size, samples int) (*SampledCache, error) {
+	if size <= 0 {
+		return nil, errors.New("must provide a positive size")
+	}
+	if samples <= 0 {
+		samples = DefaultSamples
+	}
+	return &SampledCache{
+		size:    size,
+		samples: samples,
+		items:   make(map[interface{}]*sampledElem),
+		elems:   make([]*sampledElem, 0, size),
+	}, nil
+}
+
+// Add adds a value to the cache.  Returns true if an eviction occurred.
+func (c *SampledCache) Add(key, value interface{}) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := c.tick.Add(1)
+	if ent, ok := c.items[key]; ok {
+		ent.value = value
+		ent.accessed.Store(now)
+		return false
+	}
+	evicted := false
+	if len(c.elems) >= c.size {
+		c.removeElem(c.victim())
+		evicted = true
+	}
+	ent := &sampledElem{key: key, value: value, index: len(c.elems)}
+	ent.accessed.Store(now)
+	c.items[key] = ent
+	c.elems = append(c.elems, ent)
+	return evicted
+}
+
+// victim returns the least recently used of the sampled entries. Entries are
+// sampled with replacement, so the same entry may be drawn more than once.
+func (c *SampledCache) victim() *sampledElem {
+	oldest := c.elems[rand.IntN(len(c.elems))]
+	for i := 1; i < c.samples; i++ {
+		ent := c.elems[rand.IntN(len(c.elems))]
+		if ent.accessed.Load() < oldest.accessed.Load() {
+			oldest = ent
+		}
+	}
+	return oldest
+}
+
+// removeElem removes the entry, moving the last entry into its place.
+func (c *SampledCache) removeElem(ent *sampledElem) {
+	last := c.elems[len(c.elems)-1]
+	last.index = ent.index
+	c.elems[ent.index] = last
+	c.elems[len(c.elems)-1] = nil
+	c.elems = c.elems[:len(c.elems)-1]
+	delete(c.items, ent.key)
+}
+
+// Get looks up a key's value from the cache, marking it as accessed. It only
+// takes the read lock.
+func (c *SampledCache) Get(key interface{}) (value interface{}, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	ent, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	// Skip the store if it wouldn't change anything, to keep the cache line
+	// of hot entries shared between readers
+	if now := c.tick.Load(); ent.accessed.Load() != now {
+		ent.accessed.Store(now)
+	}
+	return ent.value, true
+}
+
+// Contains checks if a key is in the cache, without updating the
+// recent-ness.
+func (c *SampledCache) Contains(key interface{}) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	_, ok := c.items[key]
+	return ok
+}
+
+// Peek returns the key value (or undefined if not found) without updating
+// the "recently used"-ness of the key.
+func (c *SampledCache) Peek(key interface{}) (value interface{}, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if ent, ok := c.items[key]; ok {
+		return ent.value, true
+	}
+	return nil, false
+}
+
+// Remove removes the provided key from the cache.
+func (c *SampledCache) Remove(key interface{}) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ent, ok := c.items[key]
+	if ok {
+		c.removeElem(ent)
+	}
+	return ok
+}
+
+// Keys returns the keys, unordered
+func (c *SampledCache) Keys() []interface{} {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	keys := make([]interface{}, 0, len(c.elems))
+	for _, ent := range c.elems {
+		keys = append(keys, ent.key)
+	}
+	return keys
+}
+
+// Len returns the number of items in the cache.
+func (c *SampledCache) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.elems)
+}
+
+// Purge is used to completely clear the cache
+func (c *SampledCache) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	clear(c.items)
+	clear(c.elems)
+	c.elems = c.elems[:0]
+}
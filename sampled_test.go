@@ -0,0 +1,96 @@
+package lruish
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSampledCache(t *testing.T) {
+	l, err := NewSampledCache(128, 0)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 0; i < 256; i++ {
+		l.Add(i, i)
+	}
+	if l.Len() != 128 {
+		t.Fatalf("bad len: %v", l.Len())
+	}
+	if v, ok := l.Get(255); !ok || v != 255 {
+		t.Errorf("255 should be set to 255: %v, %v", v, ok)
+	}
+	if l.Add(255, 0) {
+		t.Error("update evicted")
+	}
+	if v, _ := l.Peek(255); v != 0 {
+		t.Errorf("have %v, want 0", v)
+	}
+	if !l.Remove(255) || l.Contains(255) || l.Len() != 127 {
+		t.Error("remove failed")
+	}
+	for _, key := range l.Keys() {
+		if !l.Contains(key) {
+			t.Errorf("key %v listed but not cached", key)
+		}
+	}
+	l.Purge()
+	if l.Len() != 0 || len(l.Keys()) != 0 {
+		t.Errorf("purge left %d entries", l.Len())
+	}
+	if _, err := NewSampledCache(0, 5); err == nil {
+		t.Error("expected error for zero size")
+	}
+}
+
+func TestSampledCacheRecency(t *testing.T) {
+	l, _ := NewSampledCache(100, 10)
+	for i := 0; i < 100; i++ {
+		l.Add(i, i)
+	}
+	// Keep reading a hot set while streaming new keys through
+	for i := 100; i < 1000; i++ {
+		for j := 0; j < 10; j++ {
+			l.Get(j)
+		}
+		l.Add(i, i)
+	}
+	for j := 0; j < 10; j++ {
+		if !l.Contains(j) {
+			t.Errorf("hot key %d evicted", j)
+		}
+	}
+}
+
+func TestSampledCacheConcurrent(t *testing.T) {
+	l, _ := NewSampledCache(64, 0)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				l.Add(g*1000+i%100, i)
+				l.Get(g*1000 + i%50)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if l.Len() != 64 {
+		t.Errorf("have %d entries, want 64", l.Len())
+	}
+}
+
+func BenchmarkSampledCache_Get(b *testing.B) {
+	l, _ := NewSampledCache(8192, 0)
+	for i := 0; i < 8192; i++ {
+		l.Add(i, i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			l.Get(i % 8192)
+			i++
+		}
+	})
+}
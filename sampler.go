@@ -0,0 +1,110 @@
+package lruish
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// KeySample is an access to a key, as recorded by WithKeySampling.
+type KeySample struct {
+	Key  interface{}
+	Time time.Time
+}
+
+// WithKeySampling makes the cache record every Nth access, with the key and
+// the time of the access, into a buffer holding the given number of most
+// recent samples, retrievable with Sample. Gets and Adds count as accesses.
+func WithKeySampling(every, capacity int) Option {
+	return func(c *config) {
+		c.sampleEvery = every
+		c.sampleCapacity = capacity
+	}
+}
+
+// Sample returns the recorded key samples, oldest first, or nil if sampling
+// is not enabled.
+func (c *SynchedLRU) Sample() []KeySample {
+	// The sampler has its own lock, as buffered reads record samples too
+	return c.lru.Sample()
+}
+
+// Sample returns the recorded key samples, oldest first, or nil if sampling
+// is not enabled.
+func (c *lruish) Sample() []KeySample {
+	if c.sampler == nil {
+		return nil
+	}
+	return c.sampler.samples()
+}
+
+// Sample returns the samples of keys in the namespace, out of those recorded
+// for the whole cache.
+func (n *namespace) Sample() []KeySample {
+	var samples []KeySample
+	for _, s := range n.root.Sample() {
+		if k, ok := n.unwrap(s.Key); ok {
+			s.Key = k
+			samples = append(samples, s)
+		}
+	}
+	return samples
+}
+
+// Sample merges the key samples of the stripes, oldest first.
+func (c *stripedLRU) Sample() []KeySample {
+	var samples []KeySample
+	for _, s := range c.stripes {
+		samples = append(samples, s.Sample()...)
+	}
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].Time.Before(samples[j].Time)
+	})
+	return samples
+}
+
+// sampler records one in every so many accesses into a ring buffer.
+type sampler struct {
+	every uint64
+	count uint64 // Accesses seen, updated atomically
+
+	lock sync.Mutex
+	buf  []KeySample
+	next int  // Position of the next sample in buf
+	full bool // Whether buf has wrapped around
+}
+
+func newSampler(every, capacity int) *sampler {
+	return &sampler{
+		every: uint64(max(every, 1)),
+		buf:   make([]KeySample, capacity),
+	}
+}
+
+// record counts an access to the key, and samples it if it's due.
+func (s *sampler) record(key interface{}, clock Clock) {
+	if atomic.AddUint64(&s.count, 1)%s.every != 0 {
+		return
+	}
+	now := clock.Now()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.buf[s.next] = KeySample{Key: key, Time: now}
+	if s.next++; s.next == len(s.buf) {
+		s.next, s.full = 0, true
+	}
+}
+
+// samples returns the buffered samples, oldest first.
+func (s *sampler) samples() []KeySample {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.full {
+		return append([]KeySample{}, s.buf[:s.next]...)
+	}
+	return append(append([]KeySample{}, s.buf[s.next:]...), s.buf[:s.next]...)
+}
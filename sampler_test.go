@@ -0,0 +1,49 @@
+package lruish
+
+import "testing"
+
+func TestSampleKeys(t *testing.T) {
+	l, err := New(16, WithKeySampling(3, 4))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(l.Sample()) != 0 {
+		t.Fatal("expected no samples before any access")
+	}
+	for i := 1; i <= 9; i++ {
+		l.Get(i)
+	}
+	samples := l.Sample()
+	if len(samples) != 3 || samples[0].Key != 3 || samples[1].Key != 6 || samples[2].Key != 9 {
+		t.Fatalf("bad samples %+v", samples)
+	}
+	// Overflow the buffer, keeping the most recent samples only
+	for i := 10; i <= 15; i++ {
+		l.Add(i, i)
+	}
+	samples = l.Sample()
+	if len(samples) != 4 || samples[0].Key != 6 || samples[3].Key != 15 {
+		t.Fatalf("bad samples after overflow %+v", samples)
+	}
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Time.Before(samples[i-1].Time) {
+			t.Fatalf("samples out of order: %+v", samples)
+		}
+	}
+	unsampled, _ := New(16)
+	unsampled.Get(1)
+	if unsampled.Sample() != nil {
+		t.Fatal("unsampled cache should report no samples")
+	}
+}
+
+func TestSampleKeysNamespace(t *testing.T) {
+	l, _ := New(16, WithKeySampling(1, 8))
+	ns := l.Namespace("ns")
+	ns.Get("a")
+	l.Get("b")
+	samples := ns.Sample()
+	if len(samples) != 1 || samples[0].Key != "a" {
+		t.Fatalf("bad namespace samples %+v", samples)
+	}
+}
@@ -0,0 +1,156 @@
+package lruish
+
+// WithIndex registers a secondary index of the cached values, under the given
+// name. The index maps each value to the index key computed by fn, such as
+// the account a value belongs to, and GetByIndex then returns all entries
+// with a given index key. Values for which fn returns an empty string are
+// left out of the index. Several indexes can be registered under different
+// names.
+//
+// The index function is called whenever a value is stored, with the cache
+// lock held in synchronized caches.
+func WithIndex(name string, fn func(value interface{}) string) Option {
+	return func(c *config) {
+		for i, idx := range c.indexes {
+			if idx.name == name {
+				c.indexes[i].fn = fn
+				return
+			}
+		}
+		c.indexes = append(c.indexes, indexConfig{name: name, fn: fn})
+	}
+}
+
+// indexConfig is a secondary index registered with WithIndex.
+type indexConfig struct {
+	name string
+	fn   func(value interface{}) string
+}
+
+// secondaryIndex maps index keys to the entries whose values they were
+// computed from.
+type secondaryIndex struct {
+	indexConfig
+	entries map[string]map[*lruElem]struct{}
+}
+
+// newSecondaryIndexes creates empty indexes for the given configurations.
+func newSecondaryIndexes(configs []indexConfig) []*secondaryIndex {
+	if len(configs) == 0 {
+		return nil
+	}
+	indexes := make([]*secondaryIndex, len(configs))
+	for i, cfg := range configs {
+		indexes[i] = &secondaryIndex{indexConfig: cfg, entries: make(map[string]map[*lruElem]struct{})}
+	}
+	return indexes
+}
+
+// GetByIndex returns the entries whose values have the given key in the named
+// secondary index, without updating their recent-ness. Returns nil if there
+// is no such index.
+func (c *SynchedLRU) GetByIndex(name, indexKey string) []Entry {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.GetByIndex(name, indexKey)
+}
+
+// GetByIndex returns the entries whose values have the given key in the named
+// secondary index, without updating their recent-ness. Returns nil if there
+// is no such index.
+func (c *lruish) GetByIndex(name, indexKey string) []Entry {
+	for _, idx := range c.secondaries {
+		if idx.name != name {
+			continue
+		}
+		var entries []Entry
+		for ent := range idx.entries[indexKey] {
+			if !c.expired(ent) {
+				entries = append(entries, Entry{Key: ent.key, Value: c.unpack(ent.value)})
+			}
+		}
+		return entries
+	}
+	return nil
+}
+
+// indexValue adds the entry to the secondary indexes, under the index keys of
+// its new value.
+func (c *lruish) indexValue(ent *lruElem, value interface{}) {
+	if c.secondaries == nil {
+		return
+	}
+	ent.indexKeys = make([]string, len(c.secondaries))
+	for i, idx := range c.secondaries {
+		ent.indexKeys[i] = idx.fn(value)
+	}
+	c.addToIndexes(ent)
+}
+
+// addToIndexes adds the entry to the secondary indexes, under its index keys.
+func (c *lruish) addToIndexes(ent *lruElem) {
+	for i, key := range ent.indexKeys {
+		if key == "" {
+			continue
+		}
+		idx := c.secondaries[i]
+		if idx.entries[key] == nil {
+			idx.entries[key] = make(map[*lruElem]struct{})
+		}
+		idx.entries[key][ent] = struct{}{}
+	}
+}
+
+// unindexValue removes the entry from the secondary indexes.
+func (c *lruish) unindexValue(ent *lruElem) {
+	for i, key := range ent.indexKeys {
+		if key == "" {
+			continue
+		}
+		idx := c.secondaries[i]
+		delete(idx.entries[key], ent)
+		if len(idx.entries[key]) == 0 {
+			delete(idx.entries, key)
+		}
+	}
+	ent.indexKeys = nil
+}
+
+// clearIndexes empties the secondary indexes.
+func (c *lruish) clearIndexes() {
+	for _, idx := range c.secondaries {
+		idx.entries = make(map[string]map[*lruElem]struct{})
+	}
+}
+
+// copyIndexes returns empty secondary indexes with the same configuration, to
+// be filled by a clone.
+func (c *lruish) copyIndexes() []*secondaryIndex {
+	configs := make([]indexConfig, len(c.secondaries))
+	for i, idx := range c.secondaries {
+		configs[i] = idx.indexConfig
+	}
+	return newSecondaryIndexes(configs)
+}
+
+// GetByIndex returns the entries of the namespace whose values have the given
+// key in the named secondary index.
+func (n *namespace) GetByIndex(name, indexKey string) []Entry {
+	var entries []Entry
+	for _, e := range n.root.GetByIndex(name, indexKey) {
+		if k, ok := n.unwrap(e.Key); ok {
+			entries = append(entries, Entry{Key: k, Value: e.Value})
+		}
+	}
+	return entries
+}
+
+// GetByIndex returns the entries of all stripes whose values have the given
+// key in the named secondary index.
+func (c *stripedLRU) GetByIndex(name, indexKey string) []Entry {
+	var entries []Entry
+	for _, s := range c.stripes {
+		entries = append(entries, s.GetByIndex(name, indexKey)...)
+	}
+	return entries
+}
@@ -0,0 +1,82 @@
+package lruish
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type account struct {
+	owner string
+	id    int
+}
+
+func byOwner(value interface{}) string {
+	if a, ok := value.(account); ok {
+		return a.owner
+	}
+	return ""
+}
+
+// indexedKeys returns the sorted integer keys of the entries.
+func indexedKeys(entries []Entry) []int {
+	keys := make([]int, 0, len(entries))
+	for _, e := range entries {
+		keys = append(keys, e.Key.(int))
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func TestGetByIndex(t *testing.T) {
+	l, _ := New(3, WithIndex("owner", byOwner))
+	l.Add(1, account{"alice", 1})
+	l.Add(2, account{"bob", 2})
+	l.Add(3, account{"alice", 3})
+	if have, want := indexedKeys(l.GetByIndex("owner", "alice")), []int{1, 3}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %v, want %v", have, want)
+	}
+	if have := l.GetByIndex("owner", "carol"); len(have) != 0 {
+		t.Errorf("have %v, want none", have)
+	}
+	if have := l.GetByIndex("missing", "alice"); have != nil {
+		t.Errorf("unknown index: have %v, want nil", have)
+	}
+	// Replacing a value moves it in the index
+	l.Add(3, account{"bob", 3})
+	if have, want := indexedKeys(l.GetByIndex("owner", "bob")), []int{2, 3}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	// Removed and evicted entries leave the index
+	l.Remove(2)
+	l.Add(4, "unindexed")
+	l.Add(5, account{"alice", 5})
+	if have, want := indexedKeys(l.GetByIndex("owner", "alice")), []int{5}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := indexedKeys(l.GetByIndex("owner", "bob")), []int{3}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	l.Purge()
+	if have := l.GetByIndex("owner", "alice"); len(have) != 0 {
+		t.Errorf("purged: have %v, want none", have)
+	}
+}
+
+func TestGetByIndexNamespaceAndStriped(t *testing.T) {
+	l, _ := New(4, WithIndex("owner", byOwner))
+	ns := l.Namespace("ns")
+	ns.Add(1, account{"alice", 1})
+	l.Add(2, account{"alice", 2})
+	if have, want := ns.GetByIndex("owner", "alice"), []Entry{{1, account{"alice", 1}}}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	s, _ := New(64, WithStripes(4), WithIndex("owner", byOwner))
+	for i := 0; i < 16; i++ {
+		s.Add(i, account{[]string{"alice", "bob"}[i%2], i})
+	}
+	if have := s.GetByIndex("owner", "bob"); len(have) != 8 {
+		t.Errorf("have %d entries, want 8", len(have))
+	}
+}
@@ -0,0 +1,72 @@
+package lruish
+
+import "unsafe"
+
+// Sizer is implemented by keys and values which know how much memory they
+// retain, for SizeBytes to account for.
+type Sizer interface {
+	SizeBytes() int64
+}
+
+const (
+	ptrSize   = int64(unsafe.Sizeof(uintptr(0)))
+	ifaceSize = int64(unsafe.Sizeof(interface{}(nil)))
+	elemSize  = int64(unsafe.Sizeof(lruElem{}))
+
+	// mapEntrySize approximates the memory taken by an entry of the index,
+	// accounting for the key, the value and the load factor of the map.
+	mapEntrySize = (ifaceSize + ptrSize) * 3 / 2
+)
+
+// SizeBytes returns an estimate of the memory retained by the cache. It walks
+// every entry, so it's meant for occasional inspection rather than frequent
+// polling.
+func (c *SynchedLRU) SizeBytes() int64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.SizeBytes()
+}
+
+// SizeBytes returns an estimate of the memory retained by the cache: the
+// ring, the index and the entries, plus the keys and values. Keys and values
+// are only accounted for beyond their interface header if they implement
+// Sizer, or are strings or byte slices.
+func (c *lruish) SizeBytes() int64 {
+	size := int64(unsafe.Sizeof(*c)) + int64(len(c.ring))*ptrSize
+	for key, ent := range c.items {
+		size += mapEntrySize + elemSize + sizeOf(key) + sizeOf(ent.value)
+		size += int64(len(ent.tags)) * int64(unsafe.Sizeof(""))
+	}
+	return size
+}
+
+// sizeOf estimates the memory referenced by a key or value, beyond the
+// interface header holding it.
+func sizeOf(v interface{}) int64 {
+	switch v := v.(type) {
+	case Sizer:
+		return v.SizeBytes()
+	case []byte:
+		return int64(cap(v))
+	case string:
+		return int64(len(v))
+	case *compressed:
+		return int64(cap(v.data))
+	case *encoded:
+		return int64(cap(v.data))
+	case nsKey:
+		return int64(len(v.ns)) + sizeOf(v.key)
+	}
+	return 0
+}
+
+// SizeBytes returns an estimate of the memory retained by the entries of the
+// namespace. Compressed values are accounted for at their decompressed size.
+func (n *namespace) SizeBytes() int64 {
+	var size int64
+	n.Range(func(key, value interface{}) bool {
+		size += mapEntrySize + elemSize + int64(len(n.ns)) + sizeOf(key) + sizeOf(value)
+		return true
+	})
+	return size
+}
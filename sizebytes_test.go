@@ -0,0 +1,34 @@
+package lruish
+
+import "testing"
+
+type sizedValue int64
+
+func (v sizedValue) SizeBytes() int64 {
+	return int64(v)
+}
+
+func TestSizeBytes(t *testing.T) {
+	l, err := New(10)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	empty := l.SizeBytes()
+	if empty <= 0 {
+		t.Fatalf("empty cache should retain its ring, got %d", empty)
+	}
+	l.Add("key", make([]byte, 1000))
+	one := l.SizeBytes()
+	if one < empty+1003 {
+		t.Fatalf("entry not accounted for: %d -> %d", empty, one)
+	}
+	l.Add(1, sizedValue(5000))
+	if two := l.SizeBytes(); two < one+5000 {
+		t.Fatalf("Sizer not accounted for: %d -> %d", one, two)
+	}
+	ns := l.Namespace("ns")
+	ns.Add("a", "hello")
+	if size := ns.SizeBytes(); size < 6 || size >= l.SizeBytes() {
+		t.Fatalf("bad namespace size %d", size)
+	}
+}
@@ -0,0 +1,46 @@
+package lruish
+
+import "time"
+
+// WithSnapshotReads makes Contains and Peek on a synchronized cache consult an
+// immutable copy of the index, which is rebuilt every interval, instead of
+// taking the lock. Membership checks then never wait for writers, but may
+// report entries added, removed or expired up to an interval ago. Rebuilding
+// copies the whole index, so the interval should grow with the capacity.
+func WithSnapshotReads(interval time.Duration) Option {
+	return func(c *config) {
+		c.snapshotInterval = interval
+	}
+}
+
+// startSnapshots publishes an initial snapshot, and starts rebuilding it
+// every interval until the cache is closed.
+func (c *SynchedLRU) startSnapshots(interval time.Duration) {
+	c.snapshotQuit = make(chan struct{})
+	c.takeSnapshot()
+
+	c.lru.background.Add(1)
+	go func() {
+		defer c.lru.background.Done()
+
+		for {
+			select {
+			case <-c.lru.clock.After(interval):
+				c.takeSnapshot()
+			case <-c.snapshotQuit:
+				// Closed caches are empty
+				c.takeSnapshot()
+				return
+			}
+		}
+	}()
+}
+
+// takeSnapshot publishes a copy of the index, leaving out expired entries.
+func (c *SynchedLRU) takeSnapshot() {
+	c.lock.RLock()
+	snap := c.lru.index()
+	c.lock.RUnlock()
+
+	c.snapshot.Store(&snap)
+}
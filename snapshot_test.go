@@ -0,0 +1,50 @@
+package lruish
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshotReads(t *testing.T) {
+	l, err := New(4, WithSnapshotReads(time.Hour))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	if l.Contains(1) {
+		t.Fatal("snapshot should not see the addition yet")
+	}
+	c := l.(*SynchedLRU)
+	c.takeSnapshot()
+	if !l.Contains(1) {
+		t.Fatal("snapshot should see the addition")
+	}
+	if v, ok := l.Peek(1); !ok || v != 1 {
+		t.Fatalf("bad peek: %v %v", v, ok)
+	}
+	// Peeking doesn't take the lock
+	c.lock.Lock()
+	l.Peek(1)
+	c.lock.Unlock()
+
+	l.Close()
+	if l.Contains(1) {
+		t.Fatal("closed cache should be empty")
+	}
+}
+
+func TestSnapshotReadsRefresh(t *testing.T) {
+	l, err := New(4, WithSnapshotReads(time.Millisecond))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer l.Close()
+	l.Add(1, 1)
+	deadline := time.Now().Add(time.Second)
+	for !l.Contains(1) {
+		if time.Now().After(deadline) {
+			t.Fatal("snapshot not refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,87 @@
+package lruish
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+)
+
+// SnapshotOption configures how SaveSnapshot, RestoreSnapshot and
+// StartSnapshotting store snapshot files.
+type SnapshotOption func(*snapshotConfig)
+
+type snapshotConfig struct {
+	key []byte
+}
+
+func newSnapshotConfig(opts []SnapshotOption) *snapshotConfig {
+	cfg := new(snapshotConfig)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithSnapshotKey encrypts snapshot files with AES-GCM under the given key,
+// which must be 16, 24 or 32 bytes long to select AES-128, AES-192 or
+// AES-256. Files are authenticated as well, so restoring fails if the file
+// was modified or the key is wrong. The snapshot is encrypted as a whole, and
+// is thus held in memory while written or read.
+func WithSnapshotKey(key []byte) SnapshotOption {
+	return func(c *snapshotConfig) {
+		c.key = key
+	}
+}
+
+// snapshotMagic starts encrypted snapshots, and is authenticated along with
+// them.
+var snapshotMagic = []byte("lruish-gcm1\n")
+
+// snapshotAEAD returns the AES-GCM cipher for the key, which draws a random
+// nonce for every snapshot.
+func snapshotAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCMWithRandomNonce(block)
+}
+
+// EncryptSnapshot writes the snapshot read from r to w, encrypted as by
+// WithSnapshotKey.
+func EncryptSnapshot(w io.Writer, r io.Reader, key []byte) error {
+	aead, err := snapshotAEAD(key)
+	if err != nil {
+		return err
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	sealed := aead.Seal(append([]byte(nil), snapshotMagic...), nil, plain, snapshotMagic)
+	_, err = w.Write(sealed)
+	return err
+}
+
+// DecryptSnapshot reads a snapshot encrypted with WithSnapshotKey from r,
+// returning a reader of the plain snapshot for LoadFrom or ReadSnapshot.
+func DecryptSnapshot(r io.Reader, key []byte) (io.Reader, error) {
+	aead, err := snapshotAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(sealed, snapshotMagic) {
+		return nil, errors.New("lruish: snapshot not encrypted")
+	}
+	plain, err := aead.Open(nil, nil, sealed[len(snapshotMagic):], snapshotMagic)
+	if err != nil {
+		return nil, errors.New("lruish: snapshot corrupt, or encrypted with another key")
+	}
+	return bytes.NewReader(plain), nil
+}
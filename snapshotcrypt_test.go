@@ -0,0 +1,58 @@
+package lruish
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptedSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	key := bytes.Repeat([]byte{1}, 32)
+	c, _ := New(10)
+	c.Add("secret", "sensitive value")
+	if err := SaveSnapshot(c, path, WithSnapshotKey(key)); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if bytes.Contains(data, []byte("sensitive value")) {
+		t.Fatal("snapshot stored in the clear")
+	}
+
+	restored, _ := New(10)
+	if err := RestoreSnapshot(restored, path, WithSnapshotKey(key)); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := restored.Get("secret"); !ok || v != "sensitive value" {
+		t.Errorf("have %v, want the sensitive value", v)
+	}
+	// Wrong keys, missing keys and tampering are detected
+	if err := RestoreSnapshot(restored, path, WithSnapshotKey(bytes.Repeat([]byte{2}, 32))); err == nil {
+		t.Error("restored with the wrong key")
+	}
+	if err := RestoreSnapshot(restored, path); err == nil {
+		t.Error("restored without a key")
+	}
+	data[len(data)-1] ^= 1
+	os.WriteFile(path, data, 0o600)
+	if err := RestoreSnapshot(restored, path, WithSnapshotKey(key)); err == nil {
+		t.Error("restored a tampered snapshot")
+	}
+	// Plain snapshots aren't mistaken for encrypted ones
+	SaveSnapshot(c, path)
+	if err := RestoreSnapshot(restored, path, WithSnapshotKey(key)); err == nil {
+		t.Error("decrypted a plain snapshot")
+	}
+}
+
+func TestEncryptedSnapshotBadKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	c, _ := New(10)
+	if err := SaveSnapshot(c, path, WithSnapshotKey([]byte("short"))); err == nil {
+		t.Error("saved with an invalid key")
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("snapshot written despite the error")
+	}
+}
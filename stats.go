@@ -0,0 +1,107 @@
+package lruish
+
+import "sync/atomic"
+
+// Stats holds the lookup and eviction counters of a cache.
+type Stats struct {
+	Hits      uint64 // Successful Gets
+	Misses    uint64 // Failed Gets
+	Evictions uint64 // Entries evicted to make room
+	GhostHits uint64 // Misses on recently evicted keys, if tracked by WithGhosts
+}
+
+// WithGhosts makes the cache remember the keys of the given number of most
+// recently evicted entries, without their values. A miss on one of these keys
+// is counted in Stats.GhostHits: it would have been a hit if the cache had
+// been larger by the number of tracked keys. Tracking as many keys as the
+// capacity tells how many more hits doubling the capacity would yield.
+func WithGhosts(keys int) Option {
+	return func(c *config) {
+		c.ghosts = keys
+	}
+}
+
+// Stats returns the lookup and eviction counters of the cache.
+func (c *SynchedLRU) Stats() Stats {
+	// The counters are atomic, as buffered reads update them under the read lock
+	return c.lru.Stats()
+}
+
+// Stats returns the lookup and eviction counters of the cache.
+func (c *lruish) Stats() Stats {
+	return Stats{
+		Hits:      atomic.LoadUint64(&c.stats.Hits),
+		Misses:    atomic.LoadUint64(&c.stats.Misses),
+		Evictions: atomic.LoadUint64(&c.stats.Evictions),
+		GhostHits: atomic.LoadUint64(&c.stats.GhostHits),
+	}
+}
+
+// Stats returns the counters of the namespace. Ghost hits are only tracked
+// for the cache as a whole.
+func (n *namespace) Stats() Stats {
+	stats := n.root.NamespaceStats(n.ns)
+	return Stats{Hits: stats.Hits, Misses: stats.Misses, Evictions: stats.Evictions}
+}
+
+// recordLookup counts a Get. It's safe to call under the read lock.
+func (c *lruish) recordLookup(key interface{}, hit bool) {
+	c.recordAccess(key)
+	if hit {
+		atomic.AddUint64(&c.stats.Hits, 1)
+		return
+	}
+	atomic.AddUint64(&c.stats.Misses, 1)
+	if c.ghosts != nil && c.ghosts.contains(key) {
+		atomic.AddUint64(&c.stats.GhostHits, 1)
+	}
+}
+
+// ghostList is a fixed size FIFO of evicted keys.
+type ghostList struct {
+	keys map[interface{}]int // key -> position in ring
+	ring []interface{}
+	next int
+}
+
+func newGhostList(size int) *ghostList {
+	return &ghostList{
+		keys: make(map[interface{}]int, size),
+		ring: make([]interface{}, size),
+	}
+}
+
+func (g *ghostList) contains(key interface{}) bool {
+	_, ok := g.keys[key]
+	return ok
+}
+
+// add remembers the key, forgetting the oldest one if the list is full.
+func (g *ghostList) add(key interface{}) {
+	g.remove(key)
+	if old := g.ring[g.next]; old != nil {
+		delete(g.keys, old)
+	}
+	g.ring[g.next] = key
+	g.keys[key] = g.next
+	g.next = (g.next + 1) % len(g.ring)
+}
+
+// remove forgets the key, which has been added back to the cache.
+func (g *ghostList) remove(key interface{}) {
+	if pos, ok := g.keys[key]; ok {
+		delete(g.keys, key)
+		g.ring[pos] = nil
+	}
+}
+
+// recordAccess feeds a Get or Add to the hot keys sketch and the sampler. It's
+// safe to call under the read lock.
+func (c *lruish) recordAccess(key interface{}) {
+	if c.hot != nil {
+		c.hot.touch(key)
+	}
+	if c.sampler != nil {
+		c.sampler.record(key, c.clock)
+	}
+}
@@ -0,0 +1,39 @@
+package lruish
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	l, err := New(2, WithGhosts(2))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		l.Add(i, i)
+	}
+	l.Get(3)
+	l.Get(0) // ghost hit
+	l.Get(1) // ghost hit
+	l.Get(9) // plain miss
+	l.Add(0, 0)
+	l.Get(0)
+
+	want := Stats{Hits: 2, Misses: 3, Evictions: 3, GhostHits: 2}
+	if have := l.Stats(); have != want {
+		t.Fatalf("have %+v, want %+v", have, want)
+	}
+}
+
+func TestGhostList(t *testing.T) {
+	g := newGhostList(2)
+	g.add(1)
+	g.add(2)
+	g.add(3)
+	if g.contains(1) || !g.contains(2) || !g.contains(3) {
+		t.Fatalf("oldest key should be forgotten: %v", g.keys)
+	}
+	g.remove(2)
+	g.add(4)
+	if g.contains(2) || !g.contains(3) || !g.contains(4) {
+		t.Fatalf("bad ghost keys: %v", g.keys)
+	}
+}
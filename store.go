@@ -0,0 +1,88 @@
+package lruish
+
+import "sync"
+
+// Store is a backing key-value store, such as a database, which a cache sits
+// in front of.
+type Store interface {
+	// Get returns the value stored for key, with ok false if there is none.
+	Get(key interface{}) (value interface{}, ok bool, err error)
+	// Put stores the value for key.
+	Put(key, value interface{}) error
+	// Delete removes key from the store. Deleting a missing key is no error.
+	Delete(key interface{}) error
+}
+
+// WriteThrough is a thread-safe cache in front of a Store. Writes go to the
+// store before the cache, and lookups missing the cache fall through to the
+// store, populating the cache with the result.
+type WriteThrough struct {
+	cache Cache
+	store Store
+
+	// lock orders store writes against lookups falling through to the store,
+	// so a stale value read from the store never overwrites a newer write.
+	lock sync.RWMutex
+}
+
+// NewWriteThrough creates a write-through cache of the given size in front of
+// store, with optional features configured through opts.
+func NewWriteThrough(size int, store Store, opts ...Option) (*WriteThrough, error) {
+	cache, err := New(size, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &WriteThrough{cache: cache, store: store}, nil
+}
+
+// Add writes the value to the store, and then to the cache. If the store
+// fails, the cache is left unchanged.
+func (c *WriteThrough) Add(key, value interface{}) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.store.Put(key, value); err != nil {
+		return err
+	}
+	c.cache.Add(key, value)
+	return nil
+}
+
+// Get looks up a key's value from the cache, or from the store if the cache
+// doesn't hold it.
+func (c *WriteThrough) Get(key interface{}) (value interface{}, ok bool, err error) {
+	if value, ok := c.cache.Get(key); ok {
+		return value, true, nil
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if value, ok, err = c.store.Get(key); err != nil || !ok {
+		return nil, false, err
+	}
+	c.cache.Add(key, value)
+	return value, true, nil
+}
+
+// Remove deletes the key from the store, and then from the cache.
+func (c *WriteThrough) Remove(key interface{}) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.store.Delete(key); err != nil {
+		return err
+	}
+	c.cache.Remove(key)
+	return nil
+}
+
+// Cache returns the underlying cache. Modifying it directly bypasses the
+// store.
+func (c *WriteThrough) Cache() Cache {
+	return c.cache
+}
+
+// Close closes the underlying cache. The store is left open.
+func (c *WriteThrough) Close() error {
+	return c.cache.Close()
+}
@@ -0,0 +1,78 @@
+package lruish
+
+import (
+	"errors"
+	"testing"
+)
+
+// mapStore is a Store backed by a map, counting the calls made to it.
+type mapStore struct {
+	data       map[interface{}]interface{}
+	gets, puts int
+	err        error
+}
+
+func newMapStore() *mapStore {
+	return &mapStore{data: make(map[interface{}]interface{})}
+}
+
+func (s *mapStore) Get(key interface{}) (interface{}, bool, error) {
+	s.gets++
+	if s.err != nil {
+		return nil, false, s.err
+	}
+	value, ok := s.data[key]
+	return value, ok, nil
+}
+
+func (s *mapStore) Put(key, value interface{}) error {
+	s.puts++
+	if s.err != nil {
+		return s.err
+	}
+	s.data[key] = value
+	return nil
+}
+
+func (s *mapStore) Delete(key interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.data, key)
+	return nil
+}
+
+func TestWriteThrough(t *testing.T) {
+	store := newMapStore()
+	c, err := NewWriteThrough(1, store)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	c.Add(1, "a")
+	c.Add(2, "b")
+	if store.data[1] != "a" || store.data[2] != "b" {
+		t.Fatalf("store not written: %v", store.data)
+	}
+	// 1 was evicted from the cache, and is loaded from the store
+	if v, ok, err := c.Get(1); err != nil || !ok || v != "a" {
+		t.Fatalf("bad lookup: %v %v %v", v, ok, err)
+	}
+	if v, ok, _ := c.Get(1); !ok || v != "a" || store.gets != 1 {
+		t.Fatalf("expected cache hit, store lookups %d", store.gets)
+	}
+	if _, ok, _ := c.Get(3); ok {
+		t.Fatal("3 should not be found")
+	}
+	c.Remove(1)
+	if _, ok, _ := c.Get(1); ok {
+		t.Fatal("1 should be removed")
+	}
+
+	store.err = errors.New("boom")
+	if err := c.Add(4, "d"); err != store.err {
+		t.Fatalf("expected store error, got %v", err)
+	}
+	if c.Cache().Contains(4) {
+		t.Fatal("failed write should not be cached")
+	}
+}
@@ -0,0 +1,80 @@
+package lruish
+
+import (
+	"errors"
+	"sync"
+)
+
+// StringCache is a thread-safe fixed size lruish cache with string keys. It
+// avoids converting keys to interface{} on every call, which allocates.
+type StringCache struct {
+	lru  *typedLRU[string]
+	lock sync.RWMutex
+}
+
+// NewStringCache creates a multi-thread safe cache of the given size, keyed
+// by strings.
+func NewStringCache(size int) (*StringCache, error) {
+	if size <= 0 {
+		return nil, errors.New("must provide a positive size")
+	}
+	return &StringCache{lru: newTypedLRU[string](size)}, nil
+}
+
+// Add adds a value to the cache.  Returns true if an eviction occurred.
+func (c *StringCache) Add(key string, value interface{}) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.add(key, value)
+}
+
+// Get looks up a key's value from the cache.
+func (c *StringCache) Get(key string) (value interface{}, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.get(key)
+}
+
+// Contains checks if a key is in the cache, without updating the
+// recent-ness.
+func (c *StringCache) Contains(key string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.contains(key)
+}
+
+// Peek returns the key value (or undefined if not found) without updating
+// the "recently used"-ness of the key.
+func (c *StringCache) Peek(key string) (value interface{}, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.peek(key)
+}
+
+// Remove removes the provided key from the cache.
+func (c *StringCache) Remove(key string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.remove(key)
+}
+
+// Keys returns the keys, unordered
+func (c *StringCache) Keys() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.keys()
+}
+
+// Len returns the number of items in the cache.
+func (c *StringCache) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.lru.items)
+}
+
+// Purge is used to completely clear the cache
+func (c *StringCache) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lru.purge()
+}
@@ -0,0 +1,40 @@
+package lruish
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestStringCache(t *testing.T) {
+	l, err := NewStringCache(128)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 0; i < 256; i++ {
+		l.Add(strconv.Itoa(i), i)
+	}
+	if l.Len() != 128 {
+		t.Fatalf("bad len: %v", l.Len())
+	}
+	if v, ok := l.Get("255"); !ok || v != 255 {
+		t.Errorf("255 should be set to 255: %v, %v", v, ok)
+	}
+	if l.Contains("0") {
+		t.Errorf("0 should have been evicted")
+	}
+	if !l.Remove("255") || l.Contains("255") {
+		t.Errorf("255 should have been removed")
+	}
+}
+
+func TestStringCacheGetAllocs(t *testing.T) {
+	l, _ := NewStringCache(128)
+	l.Add("key", nil)
+	key := string([]byte("key"))
+	if allocs := testing.AllocsPerRun(100, func() { l.Get(key) }); allocs != 0 {
+		t.Errorf("Get allocated %v times", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { l.Contains(key) }); allocs != 0 {
+		t.Errorf("Contains allocated %v times", allocs)
+	}
+}
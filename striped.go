@@ -0,0 +1,591 @@
+package lruish
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"io"
+	"iter"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// WithStripes splits a synchronized cache into the given number of stripes,
+// each with its own ring and lock. Keys are assigned to stripes by hash, so
+// operations on unrelated keys rarely contend. The capacity is divided evenly
+// among the stripes, which evict independently: recency is only tracked
+// within a stripe, and a full stripe evicts even if others have room. The
+// Hasher set with WithHasher, if any, hashes string and integer keys; other
+// keys are hashed with hash/maphash.
+//
+// Operations spanning the whole cache, such as Keys, Len and Range, take the
+// locks of all stripes. Watermark callbacks and namespace quotas apply to
+// each stripe separately.
+func WithStripes(stripes int) Option {
+	return func(c *config) {
+		c.stripes = stripes
+	}
+}
+
+// stripedLRU is a thread-safe cache made up of independently locked stripes.
+type stripedLRU struct {
+	stripes []*SynchedLRU
+	seed    maphash.Seed
+	hasher  Hasher     // Hasher of string and integer keys, if set
+	events  chan Event // Event stream shared by the stripes, if enabled
+
+	invalidator Invalidator // Replicas to keep coherent with, if any
+	unsubscribe func()
+
+	aliases sync.Map    // Stripes holding the entries of alias keys
+	aliased atomic.Bool // Whether aliases were ever added
+}
+
+func newStriped(size int, cfg *config) (*stripedLRU, error) {
+	if size <= 0 {
+		return nil, errors.New("must provide a positive size")
+	}
+	n := min(cfg.stripes, size)
+	c := &stripedLRU{
+		stripes: make([]*SynchedLRU, n),
+		seed:    maphash.MakeSeed(),
+		hasher:  cfg.hasher,
+	}
+	// The stripes share one event stream, which the striped cache owns
+	stripeCfg := *cfg
+	stripeCfg.eventBuffer = 0
+	stripeCfg.invalidator = nil
+	if cfg.eventBuffer > 0 {
+		c.events = make(chan Event, cfg.eventBuffer)
+	}
+	for i := range c.stripes {
+		stripe, err := newSynched(stripeSize(size, n, i), &stripeCfg)
+		if err != nil {
+			return nil, err
+		}
+		stripe.lru.events = c.events
+		c.stripes[i] = stripe
+		c.routeAliases(stripe)
+	}
+	var err error
+	if c.unsubscribe, err = subscribe(cfg.invalidator, c.invalidated); err != nil {
+		return nil, err
+	}
+	c.invalidator = cfg.invalidator
+	return c, nil
+}
+
+// stripeSize returns the share of size given to stripe i out of n.
+func stripeSize(size, n, i int) int {
+	if i < size%n {
+		return size/n + 1
+	}
+	return size / n
+}
+
+// stripe returns the stripe holding the entry of the key, which is the stripe
+// the key hashes to unless the key is an alias.
+func (c *stripedLRU) stripe(key interface{}) *SynchedLRU {
+	if c.aliased.Load() {
+		if s, ok := c.aliases.Load(key); ok {
+			return s.(*SynchedLRU)
+		}
+	}
+	return c.hashStripe(key)
+}
+
+// hashStripe returns the stripe the key hashes to.
+func (c *stripedLRU) hashStripe(key interface{}) *SynchedLRU {
+	return c.stripes[c.hash(key)%uint64(len(c.stripes))]
+}
+
+// hash hashes the key with the Hasher if there is one and it can, and with
+// maphash otherwise. Integers are hashed as 8 little-endian bytes.
+func (c *stripedLRU) hash(key interface{}) uint64 {
+	if c.hasher == nil {
+		return maphash.Comparable(c.seed, key)
+	}
+	var n uint64
+	switch k := key.(type) {
+	case string:
+		return c.hasher.Hash(unsafe.Slice(unsafe.StringData(k), len(k)))
+	case int:
+		n = uint64(k)
+	case int64:
+		n = uint64(k)
+	case int32:
+		n = uint64(k)
+	case uint:
+		n = uint64(k)
+	case uint64:
+		n = k
+	case uint32:
+		n = uint64(k)
+	default:
+		return maphash.Comparable(c.seed, key)
+	}
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], n)
+	return c.hasher.Hash(buf[:])
+}
+
+// lockAll takes the locks of all stripes, in order.
+func (c *stripedLRU) lockAll() {
+	for _, s := range c.stripes {
+		s.lock.Lock()
+	}
+}
+
+func (c *stripedLRU) unlockAll() {
+	for _, s := range c.stripes {
+		s.unlock()
+	}
+}
+
+// rlockAll takes the read locks of all stripes, in order.
+func (c *stripedLRU) rlockAll() {
+	for _, s := range c.stripes {
+		s.lock.RLock()
+	}
+}
+
+func (c *stripedLRU) runlockAll() {
+	for _, s := range c.stripes {
+		s.lock.RUnlock()
+	}
+}
+
+func (c *stripedLRU) Add(key, value interface{}) bool {
+	return c.stripe(key).Add(key, value)
+}
+
+func (c *stripedLRU) TryAdd(key, value interface{}) error {
+	return c.stripe(key).TryAdd(key, value)
+}
+
+func (c *stripedLRU) Get(key interface{}) (interface{}, bool) {
+	return c.stripe(key).Get(key)
+}
+
+func (c *stripedLRU) GetStale(key interface{}) (interface{}, bool, bool) {
+	return c.stripe(key).GetStale(key)
+}
+
+func (c *stripedLRU) GetWithExpiry(key interface{}) (interface{}, time.Time, bool) {
+	return c.stripe(key).GetWithExpiry(key)
+}
+
+func (c *stripedLRU) Contains(key interface{}) bool {
+	return c.stripe(key).Contains(key)
+}
+
+func (c *stripedLRU) Peek(key interface{}) (interface{}, bool) {
+	return c.stripe(key).Peek(key)
+}
+
+func (c *stripedLRU) ContainsOrAdd(key, value interface{}) (bool, bool) {
+	return c.stripe(key).ContainsOrAdd(key, value)
+}
+
+func (c *stripedLRU) Remove(key interface{}) bool {
+	removed := c.stripe(key).Remove(key)
+	c.publish(invalidation(key))
+	return removed
+}
+
+func (c *stripedLRU) GetAndRemove(key interface{}) (interface{}, bool) {
+	value, ok := c.stripe(key).GetAndRemove(key)
+	c.publish(invalidation(key))
+	return value, ok
+}
+
+func (c *stripedLRU) Swap(key, value interface{}) (interface{}, bool) {
+	return c.stripe(key).Swap(key, value)
+}
+
+func (c *stripedLRU) AddWithPriority(key, value interface{}, priority Priority) bool {
+	return c.stripe(key).AddWithPriority(key, value, priority)
+}
+
+func (c *stripedLRU) AddWithCost(key, value interface{}, cost float64) bool {
+	return c.stripe(key).AddWithCost(key, value, cost)
+}
+
+func (c *stripedLRU) AddTagged(key, value interface{}, tags ...string) bool {
+	return c.stripe(key).AddTagged(key, value, tags...)
+}
+
+func (c *stripedLRU) InvalidateTag(tag string) int {
+	var n int
+	for _, s := range c.stripes {
+		removed := s.invalidateTag(tag)
+		c.publishKeys(removed)
+		n += len(removed)
+	}
+	return n
+}
+
+func (c *stripedLRU) Touch(key interface{}) bool {
+	return c.stripe(key).Touch(key)
+}
+
+func (c *stripedLRU) Demote(key interface{}) bool {
+	return c.stripe(key).Demote(key)
+}
+
+func (c *stripedLRU) Pin(key interface{}) bool {
+	return c.stripe(key).Pin(key)
+}
+
+func (c *stripedLRU) Unpin(key interface{}) bool {
+	return c.stripe(key).Unpin(key)
+}
+
+func (c *stripedLRU) CompareAndSwap(key, old, new interface{}) bool {
+	return c.stripe(key).CompareAndSwap(key, old, new)
+}
+
+func (c *stripedLRU) CompareAndDelete(key, old interface{}) bool {
+	deleted := c.stripe(key).CompareAndDelete(key, old)
+	if deleted {
+		c.publish(invalidation(key))
+	}
+	return deleted
+}
+
+func (c *stripedLRU) AddMany(keys, values []interface{}) []bool {
+	if len(keys) != len(values) {
+		panic("lruish: keys and values differ in length")
+	}
+	evicted := make([]bool, len(keys))
+	for i, key := range keys {
+		evicted[i] = c.Add(key, values[i])
+	}
+	return evicted
+}
+
+func (c *stripedLRU) GetMany(keys []interface{}) ([]interface{}, []bool) {
+	values, ok := make([]interface{}, len(keys)), make([]bool, len(keys))
+	for i, key := range keys {
+		values[i], ok[i] = c.Get(key)
+	}
+	return values, ok
+}
+
+// ContainsMany checks whether each of the keys is in the cache, taking the
+// read lock of each stripe once.
+func (c *stripedLRU) ContainsMany(keys []interface{}) []bool {
+	batches := make(map[*SynchedLRU][]int) // Stripe -> indexes of its keys
+	for i, key := range keys {
+		s := c.stripe(key)
+		batches[s] = append(batches[s], i)
+	}
+	ok := make([]bool, len(keys))
+	for s, indexes := range batches {
+		batch := make([]interface{}, len(indexes))
+		for j, i := range indexes {
+			batch[j] = keys[i]
+		}
+		for j, contained := range s.ContainsMany(batch) {
+			ok[indexes[j]] = contained
+		}
+	}
+	return ok
+}
+
+func (c *stripedLRU) RemoveMany(keys []interface{}) []bool {
+	removed := make([]bool, len(keys))
+	for i, key := range keys {
+		removed[i] = c.Remove(key)
+	}
+	return removed
+}
+
+func (c *stripedLRU) RemoveFunc(pred func(key, value interface{}) bool) int {
+	var n int
+	for _, s := range c.stripes {
+		removed := s.removeFunc(pred)
+		c.publishKeys(removed)
+		n += len(removed)
+	}
+	return n
+}
+
+func (c *stripedLRU) RemovePrefix(prefix string) int {
+	var n int
+	for _, s := range c.stripes {
+		removed := s.removePrefix(prefix)
+		c.publishKeys(removed)
+		n += len(removed)
+	}
+	return n
+}
+
+// Keys returns the keys of all stripes, unordered.
+func (c *stripedLRU) Keys() []interface{} {
+	c.rlockAll()
+	defer c.runlockAll()
+
+	var keys []interface{}
+	for _, s := range c.stripes {
+		keys = append(keys, s.lru.Keys()...)
+	}
+	return keys
+}
+
+// Range calls fn for each entry in the cache, stripe by stripe, without
+// updating their recent-ness. Iteration stops if fn returns false. The read
+// locks of all stripes are held during the iteration, so fn must not modify
+// the cache.
+func (c *stripedLRU) Range(fn func(key, value interface{}) bool) {
+	c.rlockAll()
+	defer c.runlockAll()
+
+	stopped := false
+	for _, s := range c.stripes {
+		s.lru.Range(func(key, value interface{}) bool {
+			stopped = !fn(key, value)
+			return !stopped
+		})
+		if stopped {
+			return
+		}
+	}
+}
+
+func (c *stripedLRU) All() iter.Seq2[interface{}, interface{}] {
+	return c.Range
+}
+
+func (c *stripedLRU) AllKeys() iter.Seq[interface{}] {
+	return keysOf(c.Range)
+}
+
+func (c *stripedLRU) AllValues() iter.Seq[interface{}] {
+	return valuesOf(c.Range)
+}
+
+func (c *stripedLRU) Len() int {
+	c.rlockAll()
+	defer c.runlockAll()
+
+	var n int
+	for _, s := range c.stripes {
+		n += s.lru.Len()
+	}
+	return n
+}
+
+func (c *stripedLRU) Cap() int {
+	var n int
+	for _, s := range c.stripes {
+		n += s.Cap()
+	}
+	return n
+}
+
+// Resize divides the new capacity evenly among the stripes.
+func (c *stripedLRU) Resize(size int) int {
+	if size < len(c.stripes) {
+		panic("lruish: size below the number of stripes")
+	}
+	var evicted int
+	for i, s := range c.stripes {
+		evicted += s.Resize(stripeSize(size, len(c.stripes), i))
+	}
+	return evicted
+}
+
+func (c *stripedLRU) Utilization() Utilization {
+	var u Utilization
+	for _, s := range c.stripes {
+		su := s.Utilization()
+		u.Cap += su.Cap
+		u.Len += su.Len
+		u.Holes += su.Holes
+	}
+	u.Ratio = float64(u.Len) / float64(u.Cap)
+	return u
+}
+
+func (c *stripedLRU) SizeBytes() int64 {
+	var n int64
+	for _, s := range c.stripes {
+		n += s.SizeBytes()
+	}
+	return n
+}
+
+func (c *stripedLRU) AllocStats() AllocStats {
+	var stats AllocStats
+	for _, s := range c.stripes {
+		ss := s.AllocStats()
+		stats.Allocated += ss.Allocated
+		stats.Reused += ss.Reused
+	}
+	return stats
+}
+
+func (c *stripedLRU) Stats() Stats {
+	var stats Stats
+	for _, s := range c.stripes {
+		ss := s.Stats()
+		stats.Hits += ss.Hits
+		stats.Misses += ss.Misses
+		stats.Evictions += ss.Evictions
+		stats.GhostHits += ss.GhostHits
+	}
+	return stats
+}
+
+// HotKeys merges the most frequently accessed keys of the stripes.
+func (c *stripedLRU) HotKeys(n int) []HotKey {
+	var hot []HotKey
+	for _, s := range c.stripes {
+		hot = append(hot, s.HotKeys(n)...)
+	}
+	if hot == nil {
+		return nil
+	}
+	sort.Slice(hot, func(i, j int) bool {
+		return hot[i].Count > hot[j].Count
+	})
+	if n >= 0 && n < len(hot) {
+		hot = hot[:n]
+	}
+	return hot
+}
+
+func (c *stripedLRU) AccessCount(key interface{}) (uint64, bool) {
+	return c.stripe(key).AccessCount(key)
+}
+
+// Entries returns the entries of all stripes, stripe by stripe. Positions are
+// relative to the head of each stripe's ring.
+func (c *stripedLRU) Entries() []EntryInfo {
+	c.rlockAll()
+	defer c.runlockAll()
+
+	var entries []EntryInfo
+	for _, s := range c.stripes {
+		entries = append(entries, s.lru.Entries()...)
+	}
+	return entries
+}
+
+func (c *stripedLRU) Compact() {
+	for _, s := range c.stripes {
+		s.Compact()
+	}
+}
+
+func (c *stripedLRU) Namespace(prefix string) Cache {
+	return &namespace{root: c, ns: prefix}
+}
+
+func (c *stripedLRU) PurgeNamespace(prefix string) int {
+	var n int
+	for _, s := range c.stripes {
+		n += s.PurgeNamespace(prefix)
+	}
+	c.publish(Invalidation{Namespace: prefix, All: true})
+	return n
+}
+
+// SetNamespaceQuota limits the namespace to the given fraction of the
+// capacity of each stripe.
+func (c *stripedLRU) SetNamespaceQuota(prefix string, fraction float64) {
+	for _, s := range c.stripes {
+		s.SetNamespaceQuota(prefix, fraction)
+	}
+}
+
+// NamespaceStats returns the statistics of the namespace, summed over the
+// stripes. The quota is the total of the per-stripe quotas.
+func (c *stripedLRU) NamespaceStats(prefix string) NamespaceStats {
+	var stats NamespaceStats
+	for _, s := range c.stripes {
+		ss := s.NamespaceStats(prefix)
+		stats.Len += ss.Len
+		stats.Quota += ss.Quota
+		stats.Hits += ss.Hits
+		stats.Misses += ss.Misses
+		stats.Evictions += ss.Evictions
+	}
+	return stats
+}
+
+func (c *stripedLRU) Clone() Cache {
+	clone := &stripedLRU{
+		stripes: make([]*SynchedLRU, len(c.stripes)),
+		seed:    c.seed,
+		hasher:  c.hasher,
+	}
+	for i, s := range c.stripes {
+		stripe := s.Clone().(*SynchedLRU)
+		clone.stripes[i] = stripe
+		clone.routeAliases(stripe)
+		if stripe.lru.aliases != nil {
+			clone.aliased.Store(true)
+			for alias := range stripe.lru.aliases.entries {
+				clone.aliases.Store(alias, stripe)
+			}
+		}
+	}
+	return clone
+}
+
+// DebugDump writes the ring layout of every stripe.
+func (c *stripedLRU) DebugDump(w io.Writer) {
+	for i, s := range c.stripes {
+		fmt.Fprintf(w, "stripe %d: ", i)
+		s.DebugDump(w)
+	}
+}
+
+func (c *stripedLRU) Events() <-chan Event {
+	if c.events == nil {
+		return nil
+	}
+	return c.events
+}
+
+func (c *stripedLRU) DroppedEvents() uint64 {
+	var n uint64
+	for _, s := range c.stripes {
+		n += atomic.LoadUint64(&s.lru.dropped)
+	}
+	return n
+}
+
+func (c *stripedLRU) hookEvictions(fn func(key, value interface{}, reason EvictionReason)) {
+	for _, s := range c.stripes {
+		s.hookEvictions(fn)
+	}
+}
+
+// Close closes all stripes, and then the shared event stream.
+func (c *stripedLRU) Close() error {
+	c.unsubscribe()
+	// Detach the shared stream, so closing one stripe doesn't close it
+	// while others may still emit
+	for _, s := range c.stripes {
+		s.lock.Lock()
+		s.lru.events = nil
+		s.lock.Unlock()
+	}
+	var err error
+	for _, s := range c.stripes {
+		if e := s.Close(); e != nil {
+			err = e
+		}
+	}
+	if c.events != nil && err == nil {
+		close(c.events)
+	}
+	return err
+}
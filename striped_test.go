@@ -0,0 +1,141 @@
+package lruish
+
+import (
+	"encoding/binary"
+	"sync"
+	"testing"
+)
+
+func TestStriped(t *testing.T) {
+	l, err := New(128, WithStripes(4), WithEvents(1024))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if l.Cap() != 128 {
+		t.Fatalf("bad capacity %d", l.Cap())
+	}
+	for i := 0; i < 64; i++ {
+		l.Add(i, i)
+	}
+	for i := 0; i < 64; i++ {
+		if v, ok := l.Get(i); !ok || v != i {
+			t.Fatalf("bad lookup of %d: %v %v", i, v, ok)
+		}
+	}
+	if l.Len() != 64 || len(l.Keys()) != 64 {
+		t.Fatalf("bad length %d", l.Len())
+	}
+	var n int
+	l.Range(func(key, value interface{}) bool {
+		n++
+		return n < 10
+	})
+	if n != 10 {
+		t.Fatalf("range should stop early, visited %d", n)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	var events int
+	for range l.Events() {
+		events++
+	}
+	if events != 128 {
+		t.Fatalf("expected 128 events, got %d", events)
+	}
+	if err := l.Close(); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+// littleEndianHasher uses the first eight bytes of the key, read as a
+// little-endian integer, as its hash.
+type littleEndianHasher struct{}
+
+func (littleEndianHasher) Hash(key []byte) uint64 {
+	var buf [8]byte
+	copy(buf[:], key)
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+func TestStripedHasher(t *testing.T) {
+	l, _ := New(16, WithStripes(4), WithHasher(littleEndianHasher{}))
+	stripes := l.(*stripedLRU).stripes
+	for i := 0; i < 8; i++ {
+		l.Add(i, i)
+		l.Add(uint64(i), i)
+		if !stripes[i%4].Contains(i) || !stripes[i%4].Contains(uint64(i)) {
+			t.Errorf("key %d not in stripe %d", i, i%4)
+		}
+	}
+	// 'a' is 97, which picks stripe 1
+	l.Add("a", 1)
+	if !stripes[1].Contains("a") {
+		t.Error("string key not hashed by the hasher")
+	}
+	// Other keys fall back to maphash
+	l.Add(1.5, 1)
+	if !l.Contains(1.5) {
+		t.Error("float key lost")
+	}
+}
+
+func TestStripedPurgeAtomic(t *testing.T) {
+	var c *stripedLRU
+	var unlocked []int
+	l, _ := New(16, WithStripes(4), WithHasher(littleEndianHasher{}), WithOnEvict(func(key, value interface{}) {
+		// While any stripe is being purged, all of them stay locked
+		for i, s := range c.stripes {
+			if s.lock.TryLock() {
+				s.lock.Unlock()
+				unlocked = append(unlocked, i)
+			}
+		}
+	}))
+	c = l.(*stripedLRU)
+	for i := 0; i < 8; i++ {
+		l.Add(i, i)
+	}
+	l.Purge()
+	if len(unlocked) != 0 || l.Len() != 0 {
+		t.Errorf("stripes %v unlocked during purge, %d entries left", unlocked, l.Len())
+	}
+}
+
+func TestStripedConcurrent(t *testing.T) {
+	l, err := New(1024, WithStripes(8))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				l.Add(g*1000+i, i)
+				l.Get(g*1000 + i/2)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if l.Len() > l.Cap() {
+		t.Fatalf("length %d exceeds capacity %d", l.Len(), l.Cap())
+	}
+}
+
+func TestStripedContainsMany(t *testing.T) {
+	l, _ := New(128, WithStripes(4))
+	keys := make([]interface{}, 64)
+	for i := range keys {
+		keys[i] = i
+		if i%2 == 0 {
+			l.Add(i, i)
+		}
+	}
+	for i, ok := range l.ContainsMany(keys) {
+		if ok != (i%2 == 0) {
+			t.Errorf("key %d: have %v", i, ok)
+		}
+	}
+}
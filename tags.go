@@ -0,0 +1,91 @@
+package lruish
+
+// AddTagged adds a value to the cache, tagged with the given tags. All entries
+// carrying a tag can be removed at once with InvalidateTag. If the key is
+// already cached, its tags are replaced. Returns true if an eviction occurred.
+func (c *SynchedLRU) AddTagged(key, value interface{}, tags ...string) bool {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.AddTagged(key, value, tags...)
+}
+
+// InvalidateTag removes all entries carrying the given tag from the cache,
+// returning how many were removed.
+func (c *SynchedLRU) InvalidateTag(tag string) int {
+	removed := c.invalidateTag(tag)
+	c.publishKeys(removed)
+	return len(removed)
+}
+
+// invalidateTag is InvalidateTag, returning the removed keys without
+// publishing their invalidations.
+func (c *SynchedLRU) invalidateTag(tag string) []interface{} {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.invalidateTag(tag)
+}
+
+// AddTagged adds a value to the cache, tagged with the given tags. All entries
+// carrying a tag can be removed at once with InvalidateTag. If the key is
+// already cached, its tags are replaced. Returns true if an eviction occurred.
+func (c *lruish) AddTagged(key, value interface{}, tags ...string) bool {
+	evicted := c.Add(key, value)
+	ent, ok := c.entry(key)
+	if !ok || c.frozen {
+		return evicted // Not added, the cache is closed, frozen or fully pinned
+	}
+	if ent.tags != nil {
+		c.untag(ent)
+	}
+	if len(tags) > 0 {
+		ent.tags = append([]string(nil), tags...)
+		c.tag(ent)
+	}
+	return evicted
+}
+
+// InvalidateTag removes all entries carrying the given tag from the cache,
+// returning how many were removed.
+func (c *lruish) InvalidateTag(tag string) int {
+	return len(c.invalidateTag(tag))
+}
+
+// invalidateTag removes all entries carrying the given tag from the cache,
+// returning their keys.
+func (c *lruish) invalidateTag(tag string) []interface{} {
+	if c.frozen {
+		return nil
+	}
+	tagged := c.tags[tag]
+	removed := make([]interface{}, 0, len(tagged))
+	// Removal untags the entries, which is safe during iteration
+	for ent := range tagged {
+		removed = append(removed, ent.key)
+		c.removeElem(ent, ReasonRemoved)
+	}
+	return removed
+}
+
+// tag adds the entry to the index of each of its tags.
+func (c *lruish) tag(ent *lruElem) {
+	if c.tags == nil {
+		c.tags = make(map[string]map[*lruElem]struct{})
+	}
+	for _, tag := range ent.tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[*lruElem]struct{})
+		}
+		c.tags[tag][ent] = struct{}{}
+	}
+}
+
+// untag removes the entry from the index of each of its tags.
+func (c *lruish) untag(ent *lruElem) {
+	for _, tag := range ent.tags {
+		delete(c.tags[tag], ent)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+	ent.tags = nil
+}
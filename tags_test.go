@@ -0,0 +1,31 @@
+package lruish
+
+import "testing"
+
+func TestInvalidateTag(t *testing.T) {
+	l, err := NewSynched(4)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.AddTagged(1, 1, "odd")
+	l.AddTagged(2, 2, "even")
+	l.AddTagged(3, 3, "odd", "prime")
+	l.Add(4, 4)
+	if n := l.InvalidateTag("odd"); n != 2 {
+		t.Errorf("expected 2 invalidations, got %d", n)
+	}
+	if l.Contains(1) || l.Contains(3) || !l.Contains(2) || !l.Contains(4) {
+		t.Errorf("wrong entries invalidated")
+	}
+	if n := l.InvalidateTag("prime"); n != 0 {
+		t.Errorf("removed entries should have been untagged, invalidated %d", n)
+	}
+	// Retagging replaces the previous tags
+	l.AddTagged(2, 2, "prime")
+	if n := l.InvalidateTag("even"); n != 0 {
+		t.Errorf("retagged entry should have lost its tag, invalidated %d", n)
+	}
+	if n := l.InvalidateTag("prime"); n != 1 || l.Contains(2) {
+		t.Errorf("expected 2 to be invalidated, invalidated %d", n)
+	}
+}
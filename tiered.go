@@ -0,0 +1,134 @@
+package lruish
+
+import (
+	"errors"
+	"sync"
+)
+
+// Tiered combines a small, fast cache with a larger second tier. Lookups
+// missing the first tier fall through to the second, and hits there move the
+// entry up into the first tier. Entries evicted from the first tier for lack
+// of capacity move down into the second, so every entry lives in at most one
+// tier.
+type Tiered struct {
+	l1, l2 Cache
+
+	// lock serializes operations moving entries between the tiers
+	lock sync.Mutex
+}
+
+// evictionHooker is implemented by the caches of this package, which can
+// report their evictions to another component after construction.
+type evictionHooker interface {
+	hookEvictions(fn func(key, value interface{}, reason EvictionReason))
+}
+
+// NewTiered creates a tiered cache out of l1 and l2. The first tier must be a
+// cache created by this package, rather than a view or wrapper, so that its
+// evictions can be observed. The second tier may be any Cache, but must not
+// use l1 itself.
+func NewTiered(l1, l2 Cache) (*Tiered, error) {
+	hooker, ok := l1.(evictionHooker)
+	if !ok {
+		return nil, errors.New("first tier must be created by New or NewUnsynched")
+	}
+	hooker.hookEvictions(func(key, value interface{}, reason EvictionReason) {
+		if reason == ReasonCapacity {
+			l2.Add(key, value)
+		}
+	})
+	return &Tiered{l1: l1, l2: l2}, nil
+}
+
+func (c *SynchedLRU) hookEvictions(fn func(key, value interface{}, reason EvictionReason)) {
+	c.lock.Lock()
+	defer c.unlock()
+	c.lru.hookEvictions(fn)
+}
+
+// hookEvictions makes the cache invoke fn for every entry leaving it, after
+// the configured eviction callback.
+func (c *lruish) hookEvictions(fn func(key, value interface{}, reason EvictionReason)) {
+	onEvict := c.onEvict
+	c.onEvict = func(key, value interface{}, reason EvictionReason) {
+		if onEvict != nil {
+			onEvict(key, value, reason)
+		}
+		fn(key, value, reason)
+	}
+}
+
+// Add adds a value to the first tier, dropping any older value from the
+// second. Returns true if an eviction occurred in the first tier.
+func (c *Tiered) Add(key, value interface{}) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.l2.Remove(key)
+	return c.l1.Add(key, value)
+}
+
+// Get looks up a key's value from the first tier, and then from the second.
+// An entry found in the second tier is moved up into the first.
+func (c *Tiered) Get(key interface{}) (value interface{}, ok bool) {
+	if value, ok := c.l1.Get(key); ok {
+		return value, true
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if value, ok = c.l2.GetAndRemove(key); ok {
+		c.l1.Add(key, value)
+	}
+	return value, ok
+}
+
+// Peek returns the key's value from either tier, without updating the
+// recent-ness of the key or moving it between tiers.
+func (c *Tiered) Peek(key interface{}) (value interface{}, ok bool) {
+	if value, ok := c.l1.Peek(key); ok {
+		return value, true
+	}
+	return c.l2.Peek(key)
+}
+
+// Contains checks if a key is in either tier.
+func (c *Tiered) Contains(key interface{}) bool {
+	return c.l1.Contains(key) || c.l2.Contains(key)
+}
+
+// Remove removes the provided key from both tiers, returning if the key was
+// contained.
+func (c *Tiered) Remove(key interface{}) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	removed := c.l1.Remove(key)
+	return c.l2.Remove(key) || removed
+}
+
+// Len returns the number of items in both tiers.
+func (c *Tiered) Len() int {
+	return c.l1.Len() + c.l2.Len()
+}
+
+// L1 returns the first tier.
+func (c *Tiered) L1() Cache {
+	return c.l1
+}
+
+// L2 returns the second tier.
+func (c *Tiered) L2() Cache {
+	return c.l2
+}
+
+// Close closes both tiers. The first tier is closed first, so that with
+// WithPurgeOnClose its entries don't move down into the closed second tier.
+func (c *Tiered) Close() error {
+	err1 := c.l1.Close()
+	err2 := c.l2.Close()
+	if err1 != nil {
+		return err1
+	}
+	return err2
+}
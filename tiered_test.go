@@ -0,0 +1,37 @@
+package lruish
+
+import "testing"
+
+func TestTiered(t *testing.T) {
+	l1, _ := New(2)
+	l2, _ := New(10)
+	c, err := NewTiered(l1, l2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		c.Add(i, i)
+	}
+	if l1.Len() != 2 || l2.Len() != 3 {
+		t.Fatalf("bad tier sizes %d and %d", l1.Len(), l2.Len())
+	}
+	if c.Len() != 5 {
+		t.Fatalf("expected 5 entries, got %d", c.Len())
+	}
+	// 0 was demoted, a hit moves it back up
+	if v, ok := c.Get(0); !ok || v != 0 {
+		t.Fatalf("bad lookup: %v %v", v, ok)
+	}
+	if !l1.Contains(0) || l2.Contains(0) {
+		t.Fatal("0 should have moved to the first tier")
+	}
+	if c.Len() != 5 {
+		t.Fatalf("promotion should not lose entries, have %d", c.Len())
+	}
+	if !c.Remove(0) || c.Contains(0) {
+		t.Fatal("0 should be removed")
+	}
+	if _, err := NewTiered(l1.Namespace("ns"), l2); err == nil {
+		t.Fatal("expected error for namespace view")
+	}
+}
@@ -0,0 +1,56 @@
+package lruish
+
+// Touch promotes the entry for key as if it was read, without returning its
+// value. Returns false if the key is not in the cache.
+func (c *SynchedLRU) Touch(key interface{}) bool {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.Touch(key)
+}
+
+// Demote moves the entry for key to the tail of the ring, making it the next
+// candidate for eviction. Returns false if the key is not in the cache.
+func (c *SynchedLRU) Demote(key interface{}) bool {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.Demote(key)
+}
+
+// Touch promotes the entry for key as if it was read, without returning its
+// value. Returns false if the key is not in the cache.
+func (c *lruish) Touch(key interface{}) bool {
+	ent, ok := c.get(key)
+	if !ok {
+		return false
+	}
+	c.promote(ent)
+	c.touch(ent, false)
+	return true
+}
+
+// Demote moves the entry for key to the tail of the ring, making it the next
+// candidate for eviction. Returns false if the key is not in the cache.
+func (c *lruish) Demote(key interface{}) bool {
+	ent, ok := c.get(key)
+	if !ok {
+		return false
+	}
+	tail := c.head - 1
+	if tail < 0 {
+		tail += c.size
+	}
+	if other := c.ring[tail]; other != nil {
+		other.index = ent.index
+	}
+	c.ring[ent.index], c.ring[tail] = c.ring[tail], ent
+	ent.index = tail
+	return true
+}
+
+func (n *namespace) Touch(key interface{}) bool {
+	return n.root.Touch(n.wrap(key))
+}
+
+func (n *namespace) Demote(key interface{}) bool {
+	return n.root.Demote(n.wrap(key))
+}
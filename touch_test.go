@@ -0,0 +1,39 @@
+package lruish
+
+import "testing"
+
+func TestTouch(t *testing.T) {
+	l, err := NewSynched(2, WithPromotion(PromoteToFront))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	l.Add(2, 2)
+	if !l.Touch(1) {
+		t.Fatalf("1 should be contained")
+	}
+	l.Add(3, 3)
+	if !l.Contains(1) || l.Contains(2) {
+		t.Errorf("touch should have saved 1 from eviction")
+	}
+	if l.Touch(2) {
+		t.Errorf("2 should not be contained")
+	}
+}
+
+func TestDemote(t *testing.T) {
+	l, err := NewSynched(4)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		l.Add(i, i)
+	}
+	if !l.Demote(3) {
+		t.Fatalf("3 should be contained")
+	}
+	l.Add(4, 4)
+	if l.Contains(3) || !l.Contains(0) {
+		t.Errorf("demoted entry should have been evicted first")
+	}
+}
@@ -0,0 +1,130 @@
+package lruish
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// WithExpireAfterWrite makes entries expire once the given duration has passed
+// since they were added or last updated, regardless of how often they are
+// read in between.
+func WithExpireAfterWrite(ttl time.Duration) Option {
+	return func(c *config) {
+		c.expireAfterWrite = ttl
+	}
+}
+
+// WithExpireAfterAccess makes entries expire once they have not been read or
+// written for the given duration.
+//
+// It can be combined with WithExpireAfterWrite, in which case an entry expires
+// as soon as either of the limits is reached.
+func WithExpireAfterAccess(ttl time.Duration) Option {
+	return func(c *config) {
+		c.expireAfterAccess = ttl
+	}
+}
+
+// WithTTLJitter randomizes the time-to-live of each entry by up to the given
+// fraction in either direction, drawn anew whenever the entry is written.
+// Entries added together then expire spread out over time, instead of all
+// at once.
+func WithTTLJitter(fraction float64) Option {
+	return func(c *config) {
+		c.ttlJitter = fraction
+	}
+}
+
+// hasTTL reports whether entries in the cache can expire at all.
+func (c *lruish) hasTTL() bool {
+	return c.expireAfterWrite > 0 || c.expireAfterAccess > 0
+}
+
+// timed reports whether the cache needs to track entry timestamps.
+func (c *lruish) timed() bool {
+	return c.hasTTL() || c.refreshAfter > 0
+}
+
+// expired reports whether the entry has outlived its time-to-live, or its
+// weakly held value has been reclaimed.
+func (c *lruish) expired(ent *lruElem) bool {
+	if c.weakValues && c.collected(ent) {
+		return true
+	}
+	if !c.hasTTL() {
+		return false
+	}
+	now := c.now()
+	if c.expireAfterWrite > 0 && now-ent.written >= c.ttl(ent, c.expireAfterWrite) {
+		return true
+	}
+	return c.expireAfterAccess > 0 && now-ent.accessed >= c.ttl(ent, c.expireAfterAccess)
+}
+
+// touch updates the timestamps of an entry which was just read, or written
+// if write is set.
+func (c *lruish) touch(ent *lruElem, write bool) {
+	if !c.timed() {
+		return
+	}
+	now := c.now()
+	if write {
+		ent.written = now
+		if c.ttlJitter > 0 {
+			ent.ttlScale = 1 + c.ttlJitter*(2*rand.Float64()-1)
+		}
+	}
+	ent.accessed = now
+}
+
+// GetStale looks up a key's value from the cache like Get, but also returns
+// values which have expired, flagged as stale. Stale entries are neither
+// promoted nor removed, so they can be served until a fresh value is added.
+func (c *lruish) GetStale(key interface{}) (value interface{}, stale, ok bool) {
+	ent, ok := c.entry(key)
+	if !ok {
+		return nil, false, false
+	}
+	if c.expired(ent) {
+		return c.unpack(ent.value), true, true
+	}
+	value, ok = c.Get(key)
+	return value, false, ok
+}
+
+// GetWithExpiry looks up a key's value from the cache like Get, and also
+// returns when the entry will expire, given that it isn't accessed or
+// written again. The expiry time is zero if entries don't expire.
+func (c *lruish) GetWithExpiry(key interface{}) (value interface{}, expiresAt time.Time, ok bool) {
+	if value, ok = c.Get(key); !ok {
+		return nil, time.Time{}, false
+	}
+	ent, _ := c.entry(key)
+	return value, c.expiresAt(ent), true
+}
+
+// expiresAt returns the time at which the entry expires, or the zero time if
+// entries don't expire.
+func (c *lruish) expiresAt(ent *lruElem) time.Time {
+	if !c.hasTTL() {
+		return time.Time{}
+	}
+	deadline := int64(math.MaxInt64)
+	if c.expireAfterWrite > 0 {
+		deadline = ent.written + c.ttl(ent, c.expireAfterWrite)
+	}
+	if c.expireAfterAccess > 0 {
+		deadline = min(deadline, ent.accessed+c.ttl(ent, c.expireAfterAccess))
+	}
+	return time.Unix(0, deadline)
+}
+
+// ttl returns the given time-to-live of the entry in nanoseconds, with the
+// entry's jitter applied.
+func (c *lruish) ttl(ent *lruElem, d time.Duration) int64 {
+	if c.ttlJitter == 0 {
+		return int64(d)
+	}
+	return int64(float64(d) * ent.ttlScale)
+}
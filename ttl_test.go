@@ -0,0 +1,117 @@
+package lruish
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpireAfterWrite(t *testing.T) {
+	l, err := NewSynched(2, WithExpireAfterWrite(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := l.Get(1); !ok {
+		t.Fatalf("1 should not have expired yet")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := l.Get(1); ok {
+		t.Errorf("1 should have expired despite being read")
+	}
+	if l.Len() != 0 {
+		t.Errorf("expired entry should have been removed")
+	}
+}
+
+func TestExpireAfterAccess(t *testing.T) {
+	l, err := NewSynched(2, WithExpireAfterAccess(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	l.Add(2, 2)
+	for i := 0; i < 3; i++ {
+		time.Sleep(30 * time.Millisecond)
+		if _, ok := l.Get(1); !ok {
+			t.Fatalf("1 should be kept alive by reads")
+		}
+	}
+	if l.Contains(2) {
+		t.Errorf("2 should have expired")
+	}
+}
+
+func TestGetStale(t *testing.T) {
+	l, err := NewSynched(2, WithExpireAfterWrite(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	if v, stale, ok := l.GetStale(1); !ok || stale || v != 1 {
+		t.Fatalf("expected fresh value, got %v %v %v", v, stale, ok)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if v, stale, ok := l.GetStale(1); !ok || !stale || v != 1 {
+		t.Fatalf("expected stale value, got %v %v %v", v, stale, ok)
+	}
+	if _, stale, ok := l.GetStale(2); ok || stale {
+		t.Fatalf("missing key should not be found")
+	}
+}
+
+func TestGetWithExpiry(t *testing.T) {
+	l, err := New(2, WithExpireAfterWrite(time.Hour), WithExpireAfterAccess(time.Minute))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	l.Add(1, 1)
+	before := time.Now()
+	v, expiresAt, ok := l.GetWithExpiry(1)
+	if !ok || v != 1 {
+		t.Fatalf("bad lookup: %v %v", v, ok)
+	}
+	// The access limit is the tighter one, counted from the Get
+	if expiresAt.Before(before.Add(time.Minute)) || expiresAt.After(time.Now().Add(time.Minute)) {
+		t.Fatalf("bad expiry %v", expiresAt)
+	}
+	if _, _, ok := l.GetWithExpiry(2); ok {
+		t.Fatal("missing key should not be found")
+	}
+	untimed, _ := New(2)
+	untimed.Add(1, 1)
+	if _, expiresAt, ok := untimed.GetWithExpiry(1); !ok || !expiresAt.IsZero() {
+		t.Fatalf("expected zero expiry without TTL, got %v", expiresAt)
+	}
+}
+
+func TestTTLJitter(t *testing.T) {
+	clock := newFakeClock()
+	l, err := New(100, WithClock(clock), WithExpireAfterWrite(100*time.Second), WithTTLJitter(0.2))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var earliest, latest time.Time
+	for i := 0; i < 100; i++ {
+		l.Add(i, i)
+		_, expiresAt, _ := l.GetWithExpiry(i)
+		if earliest.IsZero() || expiresAt.Before(earliest) {
+			earliest = expiresAt
+		}
+		if expiresAt.After(latest) {
+			latest = expiresAt
+		}
+	}
+	now := clock.Now()
+	if earliest.Before(now.Add(80*time.Second)) || latest.After(now.Add(120*time.Second)) {
+		t.Fatalf("expiry out of jitter range: %v - %v", earliest.Sub(now), latest.Sub(now))
+	}
+	if latest.Sub(earliest) < 10*time.Second {
+		t.Fatalf("expiry not spread out: %v - %v", earliest.Sub(now), latest.Sub(now))
+	}
+	// Half-way through the jitter range, some but not all entries expired
+	clock.Advance(100 * time.Second)
+	if n := len(l.Keys()); n == 0 || n == 100 {
+		t.Fatalf("expected some entries to have expired, %d left", n)
+	}
+}
@@ -0,0 +1,104 @@
+package lruish
+
+import (
+	"math"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Budget returns the number of bytes a cache may retain, or zero if it's
+// unconstrained.
+type Budget func() int64
+
+// FixedBudget returns a Budget of the given number of bytes.
+func FixedBudget(bytes int64) Budget {
+	return func() int64 {
+		return bytes
+	}
+}
+
+// MemoryLimitBudget returns a Budget of the given fraction of the Go runtime's
+// soft memory limit, as set by debug.SetMemoryLimit or GOMEMLIMIT. The limit
+// is read on every use, so the budget follows changes to it. Without a limit,
+// the budget is unconstrained.
+func MemoryLimitBudget(fraction float64) Budget {
+	return func() int64 {
+		limit := debug.SetMemoryLimit(-1)
+		if limit == math.MaxInt64 {
+			return 0
+		}
+		return int64(fraction * float64(limit))
+	}
+}
+
+// Tuner periodically resizes a cache, so that its estimated memory use stays
+// within a budget. The capacity is derived from the average size of the
+// cached entries, as estimated by SizeBytes, and kept between a minimum and
+// a maximum. An unconstrained budget grows the cache to the maximum.
+type Tuner struct {
+	cache            Cache
+	budget           Budget
+	minSize, maxSize int
+
+	quit chan struct{}
+	done sync.WaitGroup
+}
+
+// NewTuner starts tuning the capacity of the cache every interval, until
+// Stop is called. If interval is zero, the tuner only acts when Tune is
+// called. Intervals are measured by the clock of the cache.
+func NewTuner(cache Cache, budget Budget, minSize, maxSize int, interval time.Duration) *Tuner {
+	t := &Tuner{
+		cache:   cache,
+		budget:  budget,
+		minSize: minSize,
+		maxSize: maxSize,
+		quit:    make(chan struct{}),
+	}
+	if interval > 0 {
+		t.done.Add(1)
+		go t.loop(interval)
+	}
+	return t
+}
+
+func (t *Tuner) loop(interval time.Duration) {
+	defer t.done.Done()
+
+	clock := ClockOf(t.cache)
+	for {
+		select {
+		case <-clock.After(interval):
+			t.Tune()
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+// Tune resizes the cache once, returning the new capacity. To avoid churn,
+// the cache is only resized if the capacity changes by more than a tenth.
+func (t *Tuner) Tune() int {
+	size := t.cache.Cap()
+	target := t.maxSize
+	if budget := t.budget(); budget > 0 {
+		if n := t.cache.Len(); n > 0 {
+			perEntry := max(1, t.cache.SizeBytes()/int64(n))
+			target = int(min(budget/perEntry, int64(t.maxSize)))
+		}
+	}
+	target = max(target, t.minSize, 1)
+	if diff := target - size; diff > size/10 || -diff > size/10 {
+		t.cache.Resize(target)
+		return target
+	}
+	return size
+}
+
+// Stop stops the periodic tuning, and waits for a tuning in progress to
+// finish.
+func (t *Tuner) Stop() {
+	close(t.quit)
+	t.done.Wait()
+}
@@ -0,0 +1,187 @@
+package lruish
+
+import (
+	"errors"
+	"sync"
+)
+
+// typedLRU is a non-thread safe lruish cache with statically typed keys. It
+// implements the same ring as lruish, but avoids boxing keys in interfaces,
+// and serves as the backend for the key-specialized caches.
+type typedLRU[K comparable] struct {
+	size  int
+	items map[K]*typedElem[K]
+	head  int
+	ring  []*typedElem[K]
+}
+
+type typedElem[K comparable] struct {
+	value interface{}
+	key   K
+	index int
+}
+
+func newTypedLRU[K comparable](size int) *typedLRU[K] {
+	return &typedLRU[K]{
+		size:  size,
+		items: make(map[K]*typedElem[K]),
+		ring:  make([]*typedElem[K], size),
+	}
+}
+
+func (c *typedLRU[K]) promote(ent *typedElem[K]) {
+	curIndex := ent.index
+	// Calculate the new position for this item
+	position := curIndex - c.head
+	if position < 0 {
+		position += c.size
+	}
+	// Calculate new index to place this item at
+	newIndex := (c.head + position/2) % c.size
+	// Update the downgraded item, if non-nil (could be a hole in the ring)
+	if c.ring[newIndex] != nil {
+		c.ring[newIndex].index = curIndex
+	}
+	ent.index = newIndex
+	c.ring[curIndex], c.ring[newIndex] = c.ring[newIndex], c.ring[curIndex]
+}
+
+func (c *typedLRU[K]) add(key K, value interface{}) bool {
+	if ent, ok := c.items[key]; ok {
+		c.promote(ent)
+		ent.value = value
+		return false
+	}
+	// new head position is h-1, which is where the current tail lives
+	c.head--
+	if c.head < 0 {
+		c.head += c.size
+	}
+	evicted := false
+	if toDelete := c.ring[c.head]; toDelete != nil {
+		delete(c.items, toDelete.key)
+		evicted = true
+	}
+	ent := &typedElem[K]{value: value, key: key, index: c.head}
+	c.items[key] = ent
+	c.ring[c.head] = ent
+	return evicted
+}
+
+func (c *typedLRU[K]) get(key K) (interface{}, bool) {
+	if ent, ok := c.items[key]; ok {
+		c.promote(ent)
+		return ent.value, true
+	}
+	return nil, false
+}
+
+func (c *typedLRU[K]) peek(key K) (interface{}, bool) {
+	if ent, ok := c.items[key]; ok {
+		return ent.value, true
+	}
+	return nil, false
+}
+
+func (c *typedLRU[K]) contains(key K) bool {
+	_, ok := c.items[key]
+	return ok
+}
+
+func (c *typedLRU[K]) remove(key K) bool {
+	if ent, ok := c.items[key]; ok {
+		delete(c.items, key)
+		c.ring[ent.index] = nil
+		return true
+	}
+	return false
+}
+
+func (c *typedLRU[K]) keys() []K {
+	keys := make([]K, 0, len(c.items))
+	for k := range c.items {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (c *typedLRU[K]) purge() {
+	c.items = make(map[K]*typedElem[K])
+	c.ring = make([]*typedElem[K], c.size)
+	c.head = 0
+}
+
+// TypedCache is a thread-safe fixed size lruish cache with keys of a static
+// comparable type. Like StringCache and Uint64Cache, it never converts keys
+// to interface{}, so lookups don't allocate.
+type TypedCache[K comparable] struct {
+	lru  *typedLRU[K]
+	lock sync.RWMutex
+}
+
+// NewTypedCache creates a multi-thread safe cache of the given size, keyed by
+// values of type K.
+func NewTypedCache[K comparable](size int) (*TypedCache[K], error) {
+	if size <= 0 {
+		return nil, errors.New("must provide a positive size")
+	}
+	return &TypedCache[K]{lru: newTypedLRU[K](size)}, nil
+}
+
+// Add adds a value to the cache.  Returns true if an eviction occurred.
+func (c *TypedCache[K]) Add(key K, value interface{}) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.add(key, value)
+}
+
+// Get looks up a key's value from the cache.
+func (c *TypedCache[K]) Get(key K) (value interface{}, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.get(key)
+}
+
+// Contains checks if a key is in the cache, without updating the
+// recent-ness.
+func (c *TypedCache[K]) Contains(key K) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.contains(key)
+}
+
+// Peek returns the key value (or undefined if not found) without updating
+// the "recently used"-ness of the key.
+func (c *TypedCache[K]) Peek(key K) (value interface{}, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.peek(key)
+}
+
+// Remove removes the provided key from the cache.
+func (c *TypedCache[K]) Remove(key K) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.remove(key)
+}
+
+// Keys returns the keys, unordered
+func (c *TypedCache[K]) Keys() []K {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.keys()
+}
+
+// Len returns the number of items in the cache.
+func (c *TypedCache[K]) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.lru.items)
+}
+
+// Purge is used to completely clear the cache
+func (c *TypedCache[K]) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lru.purge()
+}
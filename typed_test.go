@@ -0,0 +1,41 @@
+package lruish
+
+import "testing"
+
+type point struct {
+	x, y int64
+}
+
+func TestTypedCache(t *testing.T) {
+	l, err := NewTypedCache[point](128)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := int64(0); i < 256; i++ {
+		l.Add(point{i, -i}, i)
+	}
+	if l.Len() != 128 {
+		t.Fatalf("bad len: %v", l.Len())
+	}
+	if v, ok := l.Get(point{255, -255}); !ok || v != int64(255) {
+		t.Errorf("255 should be set to 255: %v, %v", v, ok)
+	}
+	if l.Contains(point{0, 0}) {
+		t.Errorf("0 should have been evicted")
+	}
+	if !l.Remove(point{255, -255}) || l.Contains(point{255, -255}) {
+		t.Errorf("255 should have been removed")
+	}
+}
+
+func TestTypedCacheGetAllocs(t *testing.T) {
+	l, _ := NewTypedCache[point](128)
+	key := point{1, 2}
+	l.Add(key, &key)
+	if allocs := testing.AllocsPerRun(100, func() { l.Get(key) }); allocs != 0 {
+		t.Errorf("Get allocated %v times", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { l.Contains(key) }); allocs != 0 {
+		t.Errorf("Contains allocated %v times", allocs)
+	}
+}
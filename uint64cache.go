@@ -0,0 +1,82 @@
+package lruish
+
+import (
+	"errors"
+	"sync"
+)
+
+// Uint64Cache is a thread-safe fixed size lruish cache with uint64 keys, for
+// callers which key their entries by (truncated) hashes. Keys are never
+// converted to interface{}, and the map uses the runtime's fast path for
+// 64-bit keys.
+type Uint64Cache struct {
+	lru  *typedLRU[uint64]
+	lock sync.RWMutex
+}
+
+// NewUint64Cache creates a multi-thread safe cache of the given size, keyed
+// by uint64 values.
+func NewUint64Cache(size int) (*Uint64Cache, error) {
+	if size <= 0 {
+		return nil, errors.New("must provide a positive size")
+	}
+	return &Uint64Cache{lru: newTypedLRU[uint64](size)}, nil
+}
+
+// Add adds a value to the cache.  Returns true if an eviction occurred.
+func (c *Uint64Cache) Add(key uint64, value interface{}) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.add(key, value)
+}
+
+// Get looks up a key's value from the cache.
+func (c *Uint64Cache) Get(key uint64) (value interface{}, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.get(key)
+}
+
+// Contains checks if a key is in the cache, without updating the
+// recent-ness.
+func (c *Uint64Cache) Contains(key uint64) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.contains(key)
+}
+
+// Peek returns the key value (or undefined if not found) without updating
+// the "recently used"-ness of the key.
+func (c *Uint64Cache) Peek(key uint64) (value interface{}, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.peek(key)
+}
+
+// Remove removes the provided key from the cache.
+func (c *Uint64Cache) Remove(key uint64) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.remove(key)
+}
+
+// Keys returns the keys, unordered
+func (c *Uint64Cache) Keys() []uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.keys()
+}
+
+// Len returns the number of items in the cache.
+func (c *Uint64Cache) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.lru.items)
+}
+
+// Purge is used to completely clear the cache
+func (c *Uint64Cache) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lru.purge()
+}
@@ -0,0 +1,60 @@
+package lruish
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestUint64Cache(t *testing.T) {
+	l, err := NewUint64Cache(128)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := uint64(0); i < 256; i++ {
+		l.Add(i, i)
+	}
+	if l.Len() != 128 {
+		t.Fatalf("bad len: %v", l.Len())
+	}
+	if v, ok := l.Get(255); !ok || v != uint64(255) {
+		t.Errorf("255 should be set to 255: %v, %v", v, ok)
+	}
+	if l.Contains(0) {
+		t.Errorf("0 should have been evicted")
+	}
+	if allocs := testing.AllocsPerRun(100, func() { l.Get(255) }); allocs != 0 {
+		t.Errorf("Get allocated %v times", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { l.Contains(255) }); allocs != 0 {
+		t.Errorf("Contains allocated %v times", allocs)
+	}
+}
+
+func BenchmarkUint64Cache_Rand(b *testing.B) {
+	l, err := NewUint64Cache(8192)
+	if err != nil {
+		b.Fatalf("err: %v", err)
+	}
+
+	trace := make([]uint64, b.N*2)
+	for i := 0; i < b.N*2; i++ {
+		trace[i] = uint64(rand.Int63() % 32768)
+	}
+
+	b.ResetTimer()
+
+	var hit, miss int
+	for i := 0; i < 2*b.N; i++ {
+		if i%2 == 0 {
+			l.Add(trace[i], nil)
+		} else {
+			_, ok := l.Get(trace[i])
+			if ok {
+				hit++
+			} else {
+				miss++
+			}
+		}
+	}
+	b.Logf("hit: %d miss: %d ratio: %f", hit, miss, float64(hit)/float64(miss))
+}
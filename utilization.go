@@ -0,0 +1,50 @@
+package lruish
+
+// Utilization describes how much of the ring is occupied.
+type Utilization struct {
+	Cap   int     // Number of slots in the ring
+	Len   int     // Slots holding an entry, including expired ones
+	Holes int     // Empty slots, left by Remove or not filled yet
+	Ratio float64 // Fraction of slots holding an entry
+}
+
+// Cap returns the capacity of the cache.
+func (c *SynchedLRU) Cap() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.Cap()
+}
+
+// Utilization reports how many ring slots are occupied. Holes left behind by
+// Remove reduce the effective capacity until they drift out of the ring.
+func (c *SynchedLRU) Utilization() Utilization {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.Utilization()
+}
+
+// Cap returns the capacity of the cache.
+func (c *lruish) Cap() int {
+	return c.size
+}
+
+// Utilization reports how many ring slots are occupied. Holes left behind by
+// Remove reduce the effective capacity until they drift out of the ring.
+func (c *lruish) Utilization() Utilization {
+	// Every entry occupies exactly one slot, the rest are holes
+	used := len(c.items)
+	return Utilization{
+		Cap:   c.size,
+		Len:   used,
+		Holes: c.size - used,
+		Ratio: float64(used) / float64(c.size),
+	}
+}
+
+func (n *namespace) Cap() int {
+	return n.root.Cap()
+}
+
+func (n *namespace) Utilization() Utilization {
+	return n.root.Utilization()
+}
@@ -0,0 +1,21 @@
+package lruish
+
+import "testing"
+
+func TestUtilization(t *testing.T) {
+	l, err := NewSynched(4)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if l.Cap() != 4 {
+		t.Errorf("bad capacity: %d", l.Cap())
+	}
+	for i := 0; i < 4; i++ {
+		l.Add(i, i)
+	}
+	l.Remove(1)
+	want := Utilization{Cap: 4, Len: 3, Holes: 1, Ratio: 0.75}
+	if u := l.Utilization(); u != want {
+		t.Errorf("have %+v, want %+v", u, want)
+	}
+}
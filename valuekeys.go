@@ -0,0 +1,141 @@
+package lruish
+
+import "reflect"
+
+// WithValueKeys makes the cache keep a map from each cached value to the keys
+// it is cached under, for KeysOf. This costs a map entry per cached value and
+// a map update on every write.
+func WithValueKeys(enabled bool) Option {
+	return func(c *config) {
+		c.trackValueKeys = enabled
+	}
+}
+
+// KeysOf returns the keys under which the given value is cached, in no
+// particular order. Values are compared by identity: pointers, maps and
+// slices match only if they refer to the same memory, while other values
+// are compared with ==. This allows invalidating all the keys aliasing a
+// large value at once. Returns nil unless the cache was created with
+// WithValueKeys.
+func (c *SynchedLRU) KeysOf(value interface{}) []interface{} {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lru.KeysOf(value)
+}
+
+// KeysOf returns the keys under which the given value is cached, in no
+// particular order. Values are compared by identity. Returns nil unless the
+// cache was created with WithValueKeys.
+func (c *lruish) KeysOf(value interface{}) []interface{} {
+	id := valueIdentity(value)
+	if c.valueKeys == nil || id == nil {
+		return nil
+	}
+	var keys []interface{}
+	for ent := range c.valueKeys.entries[id] {
+		if !c.expired(ent) {
+			keys = append(keys, ent.key)
+		}
+	}
+	return keys
+}
+
+// sliceRef identifies a slice by its type, backing array and length. Maps
+// are identified likewise, with a zero length.
+type sliceRef struct {
+	typ reflect.Type
+	ptr uintptr
+	len int
+}
+
+// valueIdentity returns the key identifying a value in the value key map, or
+// nil if the value cannot be identified.
+func valueIdentity(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice:
+		return sliceRef{v.Type(), v.Pointer(), v.Len()}
+	case reflect.Map:
+		return sliceRef{v.Type(), v.Pointer(), 0}
+	}
+	if !v.Comparable() {
+		return nil // Funcs, or structs holding uncomparable values
+	}
+	return value
+}
+
+// valueKeyMap maps value identities to the entries holding them, and back.
+// Identities are kept out of the entries, as most caches don't track them.
+type valueKeyMap struct {
+	entries map[interface{}]map[*lruElem]struct{}
+	ids     map[*lruElem]interface{}
+}
+
+func newValueKeyMap() *valueKeyMap {
+	return &valueKeyMap{
+		entries: make(map[interface{}]map[*lruElem]struct{}),
+		ids:     make(map[*lruElem]interface{}),
+	}
+}
+
+// add maps the entry to the value identity.
+func (m *valueKeyMap) add(ent *lruElem, id interface{}) {
+	if m.entries[id] == nil {
+		m.entries[id] = make(map[*lruElem]struct{})
+	}
+	m.entries[id][ent] = struct{}{}
+	m.ids[ent] = id
+}
+
+// remove drops the entry from the map, if present.
+func (m *valueKeyMap) remove(ent *lruElem) {
+	id, ok := m.ids[ent]
+	if !ok {
+		return
+	}
+	delete(m.ids, ent)
+	delete(m.entries[id], ent)
+	if len(m.entries[id]) == 0 {
+		delete(m.entries, id)
+	}
+}
+
+// trackValue adds the entry to the value key map, under its new value.
+func (c *lruish) trackValue(ent *lruElem, value interface{}) {
+	if c.valueKeys == nil {
+		return
+	}
+	if id := valueIdentity(value); id != nil {
+		c.valueKeys.add(ent, id)
+	}
+}
+
+// untrackValue removes the entry from the value key map.
+func (c *lruish) untrackValue(ent *lruElem) {
+	c.valueKeys.remove(ent)
+}
+
+// KeysOf returns the keys of the namespace under which the given value is
+// cached.
+func (n *namespace) KeysOf(value interface{}) []interface{} {
+	var keys []interface{}
+	for _, key := range n.root.KeysOf(value) {
+		if k, ok := n.unwrap(key); ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// KeysOf returns the keys under which the given value is cached, across all
+// stripes.
+func (c *stripedLRU) KeysOf(value interface{}) []interface{} {
+	var keys []interface{}
+	for _, s := range c.stripes {
+		keys = append(keys, s.KeysOf(value)...)
+	}
+	return keys
+}
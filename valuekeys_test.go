@@ -0,0 +1,92 @@
+package lruish
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// sortedInts returns the integer keys, sorted.
+func sortedInts(keys []interface{}) []int {
+	ints := make([]int, 0, len(keys))
+	for _, k := range keys {
+		ints = append(ints, k.(int))
+	}
+	sort.Ints(ints)
+	return ints
+}
+
+func TestKeysOf(t *testing.T) {
+	l, _ := New(4, WithValueKeys(true))
+	big := make([]byte, 1024)
+	other := make([]byte, 1024)
+	l.Add(1, big)
+	l.Add(2, big)
+	l.Add(3, other)
+	l.Add(4, big[:512])
+	if have, want := sortedInts(l.KeysOf(big)), []int{1, 2}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %v, want %v", have, want)
+	}
+	if have, want := sortedInts(l.KeysOf(other)), []int{3}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	// Equal contents are not the same value
+	if have := l.KeysOf(make([]byte, 1024)); len(have) != 0 {
+		t.Errorf("have %v, want none", have)
+	}
+	// Invalidating all aliases
+	l.RemoveMany(l.KeysOf(big))
+	if l.Contains(1) || l.Contains(2) || !l.Contains(3) {
+		t.Errorf("wrong entries removed")
+	}
+	if have := l.KeysOf(big); len(have) != 0 {
+		t.Errorf("have %v after removal, want none", have)
+	}
+	// Replaced and evicted values are untracked
+	l.Add(3, "small")
+	if have := l.KeysOf(other); len(have) != 0 {
+		t.Errorf("have %v after replacement, want none", have)
+	}
+	for i := 5; i < 9; i++ {
+		l.Add(i, "small")
+	}
+	if have, want := sortedInts(l.KeysOf("small")), []int{5, 6, 7, 8}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestKeysOfUntracked(t *testing.T) {
+	l, _ := New(4)
+	l.Add(1, "a")
+	if have := l.KeysOf("a"); have != nil {
+		t.Errorf("have %v, want nil", have)
+	}
+	tracked, _ := New(4, WithValueKeys(true))
+	tracked.Add(1, func() {})
+	if have := tracked.KeysOf(nil); have != nil {
+		t.Errorf("have %v, want nil", have)
+	}
+}
+
+func TestKeysOfNamespaceAndStriped(t *testing.T) {
+	l, _ := New(4, WithValueKeys(true))
+	v := &struct{}{}
+	ns := l.Namespace("ns")
+	ns.Add(1, v)
+	l.Add(2, v)
+	if have, want := ns.KeysOf(v), []interface{}{1}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	s, _ := New(64, WithStripes(4), WithValueKeys(true))
+	for i := 0; i < 16; i++ {
+		s.Add(i, v)
+	}
+	if have := s.KeysOf(v); len(have) != 16 {
+		t.Errorf("have %d keys, want 16", len(have))
+	}
+	c := s.Clone()
+	if have := c.KeysOf(v); len(have) != 16 {
+		t.Errorf("clone has %d keys, want 16", len(have))
+	}
+}
@@ -0,0 +1,90 @@
+package lruish
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+)
+
+// WithVersionComparator sets the function AddVersioned compares versions
+// with, which returns a negative number if a is older than b, zero if they
+// are the same, and a positive number if a is newer. By default, versions
+// must be integers, floats or strings of the same type, compared by value.
+func WithVersionComparator(compare func(a, b interface{}) int) Option {
+	return func(c *config) {
+		c.versionCompare = compare
+	}
+}
+
+// AddVersioned adds a value to the cache like Add, unless the key is cached
+// with a version at least as new as the given one. This keeps stale values
+// from overwriting fresh ones when updates are processed out of order.
+// Returns whether the value was stored.
+//
+// Entries remember the version of the last AddVersioned only: other writes
+// replace the value but keep the version, and values added with Add have no
+// version, which is older than any.
+func (c *SynchedLRU) AddVersioned(key, value, version interface{}) bool {
+	c.lock.Lock()
+	defer c.unlock()
+	return c.lru.AddVersioned(key, value, version)
+}
+
+// AddVersioned adds a value to the cache like Add, unless the key is cached
+// with a version at least as new as the given one. This keeps stale values
+// from overwriting fresh ones when updates are processed out of order.
+// Returns whether the value was stored.
+//
+// Entries remember the version of the last AddVersioned only: other writes
+// replace the value but keep the version, and values added with Add have no
+// version, which is older than any.
+func (c *lruish) AddVersioned(key, value, version interface{}) bool {
+	if version == nil {
+		panic("lruish: nil version")
+	}
+	if c.frozen {
+		return false
+	}
+	if ent, ok := c.lookup(key); ok && ent.version != nil && c.compareVersions(version, ent.version) <= 0 {
+		return false
+	}
+	c.Add(key, value)
+	ent, ok := c.entry(key)
+	if !ok {
+		// The cache is closed, or full of pinned entries
+		return false
+	}
+	ent.version = version
+	return true
+}
+
+// compareVersions compares two versions, with the configured comparator or
+// by value.
+func (c *lruish) compareVersions(a, b interface{}) int {
+	if c.versionCompare != nil {
+		return c.versionCompare(a, b)
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		panic(fmt.Sprintf("lruish: comparing versions of types %T and %T", a, b))
+	}
+	switch {
+	case va.CanInt():
+		return cmp.Compare(va.Int(), vb.Int())
+	case va.CanUint():
+		return cmp.Compare(va.Uint(), vb.Uint())
+	case va.CanFloat():
+		return cmp.Compare(va.Float(), vb.Float())
+	case va.Kind() == reflect.String:
+		return cmp.Compare(va.String(), vb.String())
+	}
+	panic(fmt.Sprintf("lruish: versions of type %T need a comparator", a))
+}
+
+func (n *namespace) AddVersioned(key, value, version interface{}) bool {
+	return n.root.AddVersioned(n.wrap(key), value, version)
+}
+
+func (c *stripedLRU) AddVersioned(key, value, version interface{}) bool {
+	return c.stripe(key).AddVersioned(key, value, version)
+}
@@ -0,0 +1,68 @@
+package lruish
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddVersioned(t *testing.T) {
+	l, _ := New(4)
+	if !l.AddVersioned("a", "v2", 2) {
+		t.Fatal("first version not stored")
+	}
+	if l.AddVersioned("a", "v1", 1) {
+		t.Error("older version stored")
+	}
+	if l.AddVersioned("a", "v2'", 2) {
+		t.Error("same version stored")
+	}
+	if v, _ := l.Get("a"); v != "v2" {
+		t.Errorf("have %v, want v2", v)
+	}
+	if !l.AddVersioned("a", "v3", 3) {
+		t.Error("newer version not stored")
+	}
+	if v, _ := l.Get("a"); v != "v3" {
+		t.Errorf("have %v, want v3", v)
+	}
+	// Unversioned values are replaced by any version
+	l.Add("b", "plain")
+	if !l.AddVersioned("b", "v0", 0) {
+		t.Error("version not stored over unversioned value")
+	}
+	// Versions are forgotten with the entry
+	l.Remove("a")
+	if !l.AddVersioned("a", "v1", 1) {
+		t.Error("version not stored after removal")
+	}
+}
+
+func TestAddVersionedComparator(t *testing.T) {
+	l, _ := New(4, WithVersionComparator(func(a, b interface{}) int {
+		// Semantic-ish versions, compared by length first
+		sa, sb := a.(string), b.(string)
+		if len(sa) != len(sb) {
+			return len(sa) - len(sb)
+		}
+		return strings.Compare(sa, sb)
+	}))
+	l.AddVersioned("a", 1, "v9")
+	if !l.AddVersioned("a", 2, "v10") {
+		t.Error("newer version not stored")
+	}
+	if l.AddVersioned("a", 3, "v9") {
+		t.Error("older version stored")
+	}
+}
+
+func TestAddVersionedNamespace(t *testing.T) {
+	l, _ := New(4, WithStripes(2))
+	ns := l.Namespace("ns")
+	ns.AddVersioned("a", "new", uint64(5))
+	if ns.AddVersioned("a", "old", uint64(4)) {
+		t.Error("older version stored")
+	}
+	if !l.AddVersioned("a", "other", uint64(1)) {
+		t.Error("versions leaked across namespaces")
+	}
+}
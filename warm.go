@@ -0,0 +1,84 @@
+package lruish
+
+import "iter"
+
+// Entry is a key and value pair, as loaded by Warm.
+type Entry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// Warm bulk-loads entries given most recently used first, as returned by All,
+// so that the recency order of the cache matches the order of the entries.
+// They are added oldest first, under a single acquisition of the lock. Only
+// as many entries as the cache holds are loaded, as the others would be
+// evicted right away.
+func (c *SynchedLRU) Warm(entries []Entry) {
+	c.lock.Lock()
+	defer c.unlock()
+	c.lru.Warm(entries)
+}
+
+// WarmFrom bulk-loads the entries of a sequence, most recently used first,
+// like Warm. The sequence is consumed before taking the lock.
+func (c *SynchedLRU) WarmFrom(seq iter.Seq2[interface{}, interface{}]) {
+	c.Warm(collectEntries(seq, c.Cap()))
+}
+
+// Warm bulk-loads entries given most recently used first, as returned by All,
+// so that the recency order of the cache matches the order of the entries.
+// Only as many entries as the cache holds are loaded, as the others would be
+// evicted right away.
+func (c *lruish) Warm(entries []Entry) {
+	n := min(len(entries), c.size)
+	for i := n - 1; i >= 0; i-- {
+		c.Add(entries[i].Key, entries[i].Value)
+	}
+}
+
+// WarmFrom bulk-loads the entries of a sequence, most recently used first,
+// like Warm.
+func (c *lruish) WarmFrom(seq iter.Seq2[interface{}, interface{}]) {
+	c.Warm(collectEntries(seq, c.size))
+}
+
+// collectEntries collects up to n entries of a sequence.
+func collectEntries(seq iter.Seq2[interface{}, interface{}], n int) []Entry {
+	var entries []Entry
+	for key, value := range seq {
+		if len(entries) == n {
+			break
+		}
+		entries = append(entries, Entry{Key: key, Value: value})
+	}
+	return entries
+}
+
+func (n *namespace) Warm(entries []Entry) {
+	wrapped := make([]Entry, len(entries))
+	for i, e := range entries {
+		wrapped[i] = Entry{Key: n.wrap(e.Key), Value: e.Value}
+	}
+	n.root.Warm(wrapped)
+}
+
+func (n *namespace) WarmFrom(seq iter.Seq2[interface{}, interface{}]) {
+	n.Warm(collectEntries(seq, n.Cap()))
+}
+
+// Warm bulk-loads entries given most recently used first, taking the lock of
+// each stripe once. The order of the entries is kept within each stripe.
+func (c *stripedLRU) Warm(entries []Entry) {
+	batches := make(map[*SynchedLRU][]Entry)
+	for _, e := range entries {
+		s := c.stripe(e.Key)
+		batches[s] = append(batches[s], e)
+	}
+	for s, batch := range batches {
+		s.Warm(batch)
+	}
+}
+
+func (c *stripedLRU) WarmFrom(seq iter.Seq2[interface{}, interface{}]) {
+	c.Warm(collectEntries(seq, c.Cap()))
+}
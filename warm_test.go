@@ -0,0 +1,57 @@
+package lruish
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWarm(t *testing.T) {
+	l, _ := New(3)
+	l.Warm([]Entry{{"a", 1}, {"b", 2}, {"c", 3}, {"d", 4}})
+	// The first entries are the most recent, the surplus is left out
+	if have, want := keysInOrder(l), []interface{}{"a", "b", "c"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if l.Stats().Evictions != 0 {
+		t.Errorf("have %d evictions, want none", l.Stats().Evictions)
+	}
+}
+
+func TestWarmFrom(t *testing.T) {
+	src, _ := New(4)
+	for i := 0; i < 4; i++ {
+		src.Add(i, i*i)
+	}
+	dst, _ := New(4)
+	dst.WarmFrom(src.All())
+	if have, want := keysInOrder(dst), keysInOrder(src); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	ns := dst.Namespace("ns")
+	ns.WarmFrom(src.All())
+	if have, want := keysInOrder(ns), keysInOrder(src); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestWarmStriped(t *testing.T) {
+	l, _ := New(64, WithStripes(4))
+	entries := make([]Entry, 16)
+	for i := range entries {
+		entries[i] = Entry{i, i}
+	}
+	l.Warm(entries)
+	for i := range entries {
+		if v, ok := l.Peek(i); !ok || v != i {
+			t.Errorf("key %d: have %v", i, v)
+		}
+	}
+}
+
+func keysInOrder(c Cache) []interface{} {
+	var keys []interface{}
+	for _, e := range c.Entries() {
+		keys = append(keys, e.Key)
+	}
+	return keys
+}
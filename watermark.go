@@ -0,0 +1,16 @@
+package lruish
+
+// WithHighWatermark registers a callback which is invoked when the number of
+// entries reaches the given fraction of the capacity. It fires once per
+// crossing: the watermark is re-armed when occupancy drops below it again.
+// This lets applications shed load or grow the cache before evictions start.
+//
+// In synchronized caches the callback is invoked while the cache lock is held,
+// so it must not access the cache. To handle the event elsewhere, do a
+// non-blocking send on a channel from the callback.
+func WithHighWatermark(fraction float64, onPressure func(len, cap int)) Option {
+	return func(c *config) {
+		c.watermark = fraction
+		c.onPressure = onPressure
+	}
+}
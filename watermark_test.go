@@ -0,0 +1,24 @@
+package lruish
+
+import "testing"
+
+func TestHighWatermark(t *testing.T) {
+	var crossings []int
+	l, err := NewSynched(10, WithHighWatermark(0.8, func(len, cap int) {
+		crossings = append(crossings, len)
+	}))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		l.Add(i, i)
+	}
+	if len(crossings) != 1 || crossings[0] != 8 {
+		t.Fatalf("expected a single crossing at 8 entries, got %v", crossings)
+	}
+	l.RemoveMany([]interface{}{0, 1, 2})
+	l.Add(10, 10)
+	if len(crossings) != 2 {
+		t.Errorf("watermark should have been re-armed, got %v", crossings)
+	}
+}
@@ -0,0 +1,64 @@
+package lruish
+
+import (
+	"reflect"
+	"unsafe"
+	"weak"
+)
+
+// WithWeakValues makes the cache hold pointer values weakly, so the garbage
+// collector can reclaim them once nothing else refers to them. Lookups of a
+// reclaimed value report a miss, and drop the entry with ReasonCollected,
+// passing a nil value to eviction callbacks. This trades hit rate for memory
+// when caching large objects, which are lost instead of exhausting memory.
+//
+// Values of other kinds are held as usual. Reclaimed entries keep their slot
+// until they are looked up or evicted, and lock-free reads enabled by
+// WithSnapshotReads or Freeze notice them on Get and Peek only.
+func WithWeakValues(enabled bool) Option {
+	return func(c *config) {
+		c.weakValues = enabled
+	}
+}
+
+// weakValue is a pointer value held weakly. The weak pointer is untyped, and
+// the value is rebuilt with its original type.
+type weakValue struct {
+	typ reflect.Type
+	ptr weak.Pointer[byte]
+}
+
+// makeWeak returns a weak reference to the value, if it is a non-nil pointer.
+func makeWeak(value interface{}) (*weakValue, bool) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return nil, false
+	}
+	return &weakValue{typ: v.Type(), ptr: weak.Make((*byte)(v.UnsafePointer()))}, true
+}
+
+// value returns the referenced value, or nil if it has been reclaimed.
+func (w *weakValue) value() interface{} {
+	p := w.ptr.Value()
+	if p == nil {
+		return nil
+	}
+	return reflect.NewAt(w.typ.Elem(), unsafe.Pointer(p)).Convert(w.typ).Interface()
+}
+
+// collected reports whether the entry's value was held weakly and has been
+// reclaimed.
+func (c *lruish) collected(ent *lruElem) bool {
+	w, ok := ent.value.(*weakValue)
+	return ok && w.ptr.Value() == nil
+}
+
+// unpackLive converts a stored value back into the form it was added in, and
+// reports whether it is still there, having not been reclaimed.
+func (c *lruish) unpackLive(value interface{}) (interface{}, bool) {
+	if w, ok := value.(*weakValue); ok {
+		v := w.value()
+		return v, v != nil
+	}
+	return c.unpack(value), true
+}
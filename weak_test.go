@@ -0,0 +1,74 @@
+package lruish
+
+import (
+	"runtime"
+	"testing"
+)
+
+type decoded struct {
+	data [1 << 16]byte
+}
+
+func TestWeakValues(t *testing.T) {
+	var reasons []EvictionReason
+	l, _ := New(4, WithWeakValues(true), WithOnEvictReason(func(key, value interface{}, reason EvictionReason) {
+		reasons = append(reasons, reason)
+	}))
+	kept := &decoded{}
+	kept.data[0] = 1
+	l.Add("kept", kept)
+	l.Add("dropped", &decoded{})
+	l.Add("plain", "value")
+
+	runtime.GC()
+	runtime.GC()
+
+	if v, ok := l.Get("kept"); !ok || v.(*decoded) != kept {
+		t.Errorf("referenced value lost: %v, %v", v, ok)
+	}
+	if v, ok := l.Get("dropped"); ok {
+		t.Errorf("reclaimed value returned: %v", v)
+	}
+	if l.Contains("dropped") {
+		t.Error("reclaimed entry not dropped")
+	}
+	if v, ok := l.Get("plain"); !ok || v != "value" {
+		t.Errorf("non-pointer value lost: %v, %v", v, ok)
+	}
+	if len(reasons) != 1 || reasons[0] != ReasonCollected {
+		t.Errorf("have reasons %v, want [collected]", reasons)
+	}
+	runtime.KeepAlive(kept)
+}
+
+type decodedPtr *decoded
+
+func TestWeakValuesNamedPointer(t *testing.T) {
+	l, _ := New(4, WithWeakValues(true))
+	p := decodedPtr(&decoded{})
+	l.Add("p", p)
+	if v, ok := l.Peek("p"); !ok || v.(decodedPtr) != p {
+		t.Errorf("have %v (%v), want %v", v, ok, p)
+	}
+	runtime.KeepAlive(p)
+}
+
+func TestWeakValuesCompareAndSwap(t *testing.T) {
+	l, _ := New(4, WithWeakValues(true))
+	old, new := &decoded{}, &decoded{}
+	l.Add("a", old)
+	if l.CompareAndSwap("a", &decoded{}, new) {
+		t.Error("swap should fail on another pointer")
+	}
+	if !l.CompareAndSwap("a", old, new) {
+		t.Fatal("swap should succeed on the cached pointer")
+	}
+	if v, _ := l.Peek("a"); v.(*decoded) != new {
+		t.Errorf("have %p, want %p", v, new)
+	}
+	if !l.CompareAndDelete("a", new) || l.Contains("a") {
+		t.Error("delete should succeed on the cached pointer")
+	}
+	runtime.KeepAlive(old)
+	runtime.KeepAlive(new)
+}
@@ -0,0 +1,300 @@
+package lruish
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// WriteBack is a thread-safe cache in front of a Store, which defers writes to
+// the store. Added values are marked dirty, and only written to the store once
+// they leave the cache, or when the cache is flushed. Removals are passed on
+// to the store immediately.
+//
+// Store calls are made with the cache lock held.
+type WriteBack struct {
+	lru      *lruish
+	lock     sync.Mutex
+	store    Store
+	progress func(written, remaining int)
+
+	dirty   map[interface{}]uint64     // cached keys not yet written to the store
+	pending map[interface{}]dirtyValue // dirty values which left the cache, not yet written
+	seq     uint64                     // Number of values marked dirty so far
+}
+
+// dirtyValue is a value which left the cache before being written.
+type dirtyValue struct {
+	value interface{}
+	seq   uint64 // When the key was marked dirty
+}
+
+// WithFlushProgress makes WriteBack.FlushDirty call progress after every
+// batch, with the number of values written so far and the number left to
+// write.
+func WithFlushProgress(progress func(written, remaining int)) Option {
+	return func(c *config) {
+		c.flushProgress = progress
+	}
+}
+
+// NewWriteBack creates a write-back cache of the given size in front of
+// store, with optional features configured through opts. Background refreshes
+// and asynchronous eviction callbacks are not supported, and neither are weak
+// values, which could be reclaimed before being written.
+func NewWriteBack(size int, store Store, opts ...Option) (*WriteBack, error) {
+	cfg := newConfig(opts)
+	if cfg.refreshLoader != nil || cfg.evictQueue > 0 || cfg.invalidator != nil {
+		return nil, errors.New("write-back caches don't support refreshing, invalidators or asynchronous callbacks")
+	}
+	if cfg.weakValues {
+		return nil, errors.New("write-back caches don't support weak values")
+	}
+	c := &WriteBack{
+		store:    store,
+		progress: cfg.flushProgress,
+		dirty:    make(map[interface{}]uint64),
+		pending:  make(map[interface{}]dirtyValue),
+	}
+	onEvict := cfg.onEvictReason
+	cfg.onEvictReason = func(key, value interface{}, reason EvictionReason) {
+		c.onEvict(key, value, reason)
+		if onEvict != nil {
+			onEvict(key, value, reason)
+		}
+	}
+	lru, err := newLruish(size, cfg)
+	if err != nil {
+		return nil, err
+	}
+	c.lru = lru
+	return c, nil
+}
+
+// onEvict holds on to dirty values leaving the cache until they are written.
+func (c *WriteBack) onEvict(key, value interface{}, reason EvictionReason) {
+	if reason == ReasonReplaced {
+		return
+	}
+	if tracked, ok := value.(*TrackedCloser); ok {
+		value = tracked.Unwrap()
+	}
+	if seq, ok := c.dirty[key]; ok {
+		delete(c.dirty, key)
+		c.pending[key] = dirtyValue{value: value, seq: seq}
+	}
+}
+
+// writePending writes the dirty values which left the cache to the store.
+// Values which fail to be written are kept, to be retried later.
+func (c *WriteBack) writePending() error {
+	for key, pv := range c.pending {
+		if err := c.store.Put(key, pv.value); err != nil {
+			return err
+		}
+		delete(c.pending, key)
+	}
+	return nil
+}
+
+// Add adds a value to the cache, marking it dirty. The returned error is that
+// of writing a dirty value evicted to make room, which is retried on the next
+// Add or Flush. If the cache turns the value away, as with WithNoEviction or
+// WithDoorkeeper, it is written to the store right away instead.
+func (c *WriteBack) Add(key, value interface{}) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// A key which is already dirty keeps its age, as its oldest unwritten
+	// change is what FlushDirty orders by
+	seq, dirty := c.dirty[key]
+	if pv, ok := c.pending[key]; ok {
+		seq, dirty = pv.seq, true
+		delete(c.pending, key)
+	}
+	switch err := c.lru.TryAdd(key, value); err {
+	case nil:
+		if !dirty {
+			c.seq++
+			seq = c.seq
+		}
+		c.dirty[key] = seq
+	case ErrCacheFull:
+		if err := c.store.Put(key, value); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+	return c.writePending()
+}
+
+// Get looks up a key's value from the cache, or from the store if the cache
+// doesn't hold it. Values loaded from the store are added to the cache.
+func (c *WriteBack) Get(key interface{}) (value interface{}, ok bool, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if value, ok := c.lru.Get(key); ok {
+		return value, true, nil
+	}
+	if pv, ok := c.pending[key]; ok {
+		return pv.value, true, nil
+	}
+	if c.lru.closed {
+		return nil, false, nil
+	}
+	if value, ok, err = c.store.Get(key); err != nil || !ok {
+		return nil, false, err
+	}
+	c.lru.Add(key, value)
+	// Failed writes stay pending, and are reported by the next Add or Flush
+	c.writePending()
+	return value, true, nil
+}
+
+// Remove deletes the key from the store and the cache, discarding any
+// unwritten value.
+func (c *WriteBack) Remove(key interface{}) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.store.Delete(key); err != nil {
+		return err
+	}
+	delete(c.dirty, key)
+	delete(c.pending, key)
+	c.lru.Remove(key)
+	return nil
+}
+
+// Dirty returns the number of values not yet written to the store.
+func (c *WriteBack) Dirty() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.dirty) + len(c.pending)
+}
+
+// Flush writes all dirty values to the store. It stops at the first failed
+// write, leaving the remaining values dirty.
+func (c *WriteBack) Flush() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.flush()
+}
+
+func (c *WriteBack) flush() error {
+	if err := c.writePending(); err != nil {
+		return err
+	}
+	for key := range c.dirty {
+		if _, err := c.write(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FlushDirty writes the values dirty at the time of the call to the store,
+// oldest first, in batches of batchSize. The lock is released between
+// batches, so the cache stays usable during a long flush, and the flush stops
+// early with ctx.Err() once ctx is done. Progress is reported after every
+// batch to the callback set with WithFlushProgress.
+//
+// A batch is written in full even if some of its values fail, which stay
+// dirty to be retried later. The flush stops after the first batch with
+// failures, returning their errors joined. In any case, FlushDirty returns
+// the number of values written.
+func (c *WriteBack) FlushDirty(ctx context.Context, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		return 0, errors.New("must provide a positive batch size")
+	}
+	type dirtyKey struct {
+		key interface{}
+		seq uint64
+	}
+	c.lock.Lock()
+	keys := make([]dirtyKey, 0, len(c.dirty)+len(c.pending))
+	for key, seq := range c.dirty {
+		keys = append(keys, dirtyKey{key, seq})
+	}
+	for key, pv := range c.pending {
+		keys = append(keys, dirtyKey{key, pv.seq})
+	}
+	c.lock.Unlock()
+	slices.SortFunc(keys, func(a, b dirtyKey) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+
+	written := 0
+	for len(keys) > 0 {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		batch := keys[:min(batchSize, len(keys))]
+		keys = keys[len(batch):]
+
+		var errs []error
+		c.lock.Lock()
+		for _, dk := range batch {
+			ok, err := c.write(dk.key)
+			if err != nil {
+				errs = append(errs, err)
+			} else if ok {
+				written++
+			}
+		}
+		c.lock.Unlock()
+
+		if c.progress != nil {
+			c.progress(written, len(keys))
+		}
+		if len(errs) > 0 {
+			return written, errors.Join(errs...)
+		}
+	}
+	return written, nil
+}
+
+// write writes the key's value to the store if it's dirty, reporting whether
+// it was.
+func (c *WriteBack) write(key interface{}) (bool, error) {
+	if pv, ok := c.pending[key]; ok {
+		if err := c.store.Put(key, pv.value); err != nil {
+			return false, err
+		}
+		delete(c.pending, key)
+		return true, nil
+	}
+	if _, ok := c.dirty[key]; !ok {
+		return false, nil
+	}
+	ent, ok := c.lru.items[key]
+	if !ok {
+		delete(c.dirty, key)
+		return false, nil
+	}
+	if err := c.store.Put(key, c.lru.unpack(ent.value)); err != nil {
+		return false, err
+	}
+	delete(c.dirty, key)
+	return true, nil
+}
+
+// Close flushes the dirty values to the store and closes the cache. If the
+// flush fails, the cache is left open so that it can be retried. The store is
+// left open.
+func (c *WriteBack) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.lru.closed {
+		return ErrClosed
+	}
+	if err := c.flush(); err != nil {
+		return err
+	}
+	return c.lru.Close()
+}
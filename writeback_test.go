@@ -0,0 +1,152 @@
+package lruish
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestWriteBack(t *testing.T) {
+	store := newMapStore()
+	c, err := NewWriteBack(2, store)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	c.Add(1, "a")
+	c.Add(1, "b")
+	c.Add(2, "c")
+	if store.puts != 0 {
+		t.Fatalf("expected writes to be deferred, got %d", store.puts)
+	}
+	if n := c.Dirty(); n != 2 {
+		t.Fatalf("expected 2 dirty values, got %d", n)
+	}
+	// Evicting 1 writes it back
+	c.Add(3, "d")
+	if store.puts != 1 || store.data[1] != "b" {
+		t.Fatalf("evicted value not written: %v", store.data)
+	}
+	if v, ok, _ := c.Get(1); !ok || v != "b" {
+		t.Fatalf("bad lookup: %v %v", v, ok)
+	}
+	if err := c.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if store.data[2] != "c" || store.data[3] != "d" || c.Dirty() != 0 {
+		t.Fatalf("flush incomplete: %v, %d dirty", store.data, c.Dirty())
+	}
+	c.Remove(3)
+	if _, ok := store.data[3]; ok {
+		t.Fatal("3 should be deleted from the store")
+	}
+}
+
+func TestWriteBackFailure(t *testing.T) {
+	store := newMapStore()
+	c, err := NewWriteBack(1, store)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	c.Add(1, "a")
+	store.err = errors.New("boom")
+	if err := c.Add(2, "b"); err != store.err {
+		t.Fatalf("expected store error, got %v", err)
+	}
+	// The evicted value is still served while pending
+	if v, ok, _ := c.Get(1); !ok || v != "a" {
+		t.Fatalf("pending value lost: %v %v", v, ok)
+	}
+	if err := c.Close(); err != store.err {
+		t.Fatalf("expected store error, got %v", err)
+	}
+	store.err = nil
+	if err := c.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	if store.data[1] != "a" || store.data[2] != "b" {
+		t.Fatalf("values lost: %v", store.data)
+	}
+}
+
+func TestWriteBackRejected(t *testing.T) {
+	store := newMapStore()
+	c, err := NewWriteBack(1, store, WithNoEviction(true))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	c.Add("a", 1)
+	// The cache is full, so b goes straight to the store
+	if err := c.Add("b", 2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if store.data["b"] != 2 || c.Dirty() != 1 {
+		t.Fatalf("rejected value not written through: %v, %d dirty", store.data, c.Dirty())
+	}
+	if err := c.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if store.data["a"] != 1 {
+		t.Fatalf("dirty value not flushed: %v", store.data)
+	}
+}
+
+// orderStore records the order of writes, and fails those of one key.
+type orderStore struct {
+	*mapStore
+	order []interface{}
+	fail  interface{}
+}
+
+func (s *orderStore) Put(key, value interface{}) error {
+	if key == s.fail {
+		return errors.New("boom")
+	}
+	s.order = append(s.order, key)
+	return s.mapStore.Put(key, value)
+}
+
+func TestWriteBackFlushDirty(t *testing.T) {
+	store := &orderStore{mapStore: newMapStore()}
+	var progress [][2]int
+	c, err := NewWriteBack(10, store, WithFlushProgress(func(written, remaining int) {
+		progress = append(progress, [2]int{written, remaining})
+	}))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for i := 1; i <= 5; i++ {
+		c.Add(i, i)
+	}
+	// Updating a dirty value doesn't make it any younger
+	c.Add(1, 10)
+	if n, err := c.FlushDirty(context.Background(), 2); n != 5 || err != nil {
+		t.Fatalf("have %d, %v, want 5 written", n, err)
+	}
+	if !slices.Equal(store.order, []interface{}{1, 2, 3, 4, 5}) || store.data[1] != 10 {
+		t.Errorf("bad writes: %v, %v", store.order, store.data)
+	}
+	if !slices.Equal(progress, [][2]int{{2, 3}, {4, 1}, {5, 0}}) {
+		t.Errorf("bad progress: %v", progress)
+	}
+	// A batch with a failure is completed, and then the flush stops
+	store.fail = 7
+	for i := 6; i <= 9; i++ {
+		c.Add(i, i)
+	}
+	if n, err := c.FlushDirty(context.Background(), 3); n != 2 || err == nil {
+		t.Fatalf("have %d, %v, want 2 written and an error", n, err)
+	}
+	if c.Dirty() != 2 || store.data[8] != 8 {
+		t.Errorf("have %d dirty, %v, want 7 and 9 left", c.Dirty(), store.data)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if n, err := c.FlushDirty(ctx, 3); n != 0 || err != context.Canceled {
+		t.Errorf("have %d, %v, want context.Canceled", n, err)
+	}
+	store.fail = nil
+	if n, err := c.FlushDirty(context.Background(), 3); n != 2 || err != nil || c.Dirty() != 0 {
+		t.Errorf("have %d, %v, %d dirty, want all written", n, err, c.Dirty())
+	}
+}